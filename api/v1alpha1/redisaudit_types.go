@@ -0,0 +1,200 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RedisAuditSpec defines the keyspace scan to perform.
+type RedisAuditSpec struct {
+	// Pattern is the SCAN MATCH glob selecting the keys to audit
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default="*"
+	// +kubebuilder:validation:MinLength=1
+	Pattern string `json:"pattern,omitempty"`
+
+	// ConnectionRef names a RedisConnection in the audit's namespace whose
+	// server is scanned. Defaults to the controller's own connection.
+	// +kubebuilder:validation:Optional
+	ConnectionRef *corev1.LocalObjectReference `json:"connectionRef,omitempty"`
+
+	// MaxKeys stops the scan after this many keys; the report is then
+	// marked as truncated
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=10000
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=1000000
+	MaxKeys int64 `json:"maxKeys,omitempty"`
+
+	// OversizedBytes is the memory usage above which a key is reported as
+	// oversized
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=1048576
+	// +kubebuilder:validation:Minimum=1
+	OversizedBytes int64 `json:"oversizedBytes,omitempty"`
+
+	// CompareEntries additionally compares every managed RedisEntry's declared
+	// value and TTL with Redis and reports the entries that differ
+	// +optional
+	CompareEntries bool `json:"compareEntries,omitempty"`
+
+	// DescribeUnmanaged additionally records the type, remaining TTL and
+	// memory usage of the sampled unmanaged keys, to review shadow state
+	// before declaring RedisEntries for it
+	// +optional
+	DescribeUnmanaged bool `json:"describeUnmanaged,omitempty"`
+}
+
+// AuditedKey is a key listed in an audit report.
+type AuditedKey struct {
+	// Key is the Redis key
+	Key string `json:"key"`
+
+	// Bytes is the memory used by the key as reported by MEMORY USAGE
+	// +optional
+	Bytes int64 `json:"bytes,omitempty"`
+}
+
+// UnmanagedKey describes a key no RedisEntry declares.
+type UnmanagedKey struct {
+	// Key is the Redis key
+	Key string `json:"key"`
+
+	// Type is the Redis type of the key, as reported by TYPE
+	Type string `json:"type"`
+
+	// TTLSeconds is the remaining TTL; it is omitted for keys that never expire
+	// +optional
+	TTLSeconds *int64 `json:"ttlSeconds,omitempty"`
+
+	// Bytes is the memory used by the key as reported by MEMORY USAGE
+	// +optional
+	Bytes int64 `json:"bytes,omitempty"`
+}
+
+// DriftedEntry is a RedisEntry whose keys differ from Redis.
+type DriftedEntry struct {
+	// Namespace of the RedisEntry
+	Namespace string `json:"namespace"`
+
+	// Name of the RedisEntry
+	Name string `json:"name"`
+
+	// Key is the first key that differs
+	Key string `json:"key"`
+
+	// State describes the difference: Drifted, Missing, TTLMismatch or Error
+	State string `json:"state"`
+}
+
+// RedisAuditStatus holds the report of the most recent audit. Key lists are
+// capped; the counts always cover every scanned key.
+type RedisAuditStatus struct {
+	// Conditions represent the latest available observations of the audit
+	// +listType=map
+	// +listMapKey=type
+	// +kubebuilder:validation:MaxItems=8
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// CompletionTime is when the report was produced
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// ScannedKeys is the number of keys matching the pattern that were examined
+	// +optional
+	ScannedKeys int64 `json:"scannedKeys,omitempty"`
+
+	// Truncated is set when the scan stopped at spec.maxKeys
+	// +optional
+	Truncated bool `json:"truncated,omitempty"`
+
+	// ManagedKeys is the number of scanned keys declared by a RedisEntry
+	// +optional
+	ManagedKeys int64 `json:"managedKeys,omitempty"`
+
+	// UnmanagedKeys is the number of scanned keys no RedisEntry declares
+	// +optional
+	UnmanagedKeys int64 `json:"unmanagedKeys,omitempty"`
+
+	// UnmanagedKeySamples lists some of the unmanaged keys
+	// +optional
+	UnmanagedKeySamples []string `json:"unmanagedKeySamples,omitempty"`
+
+	// UnmanagedKeyDetails describes the sampled unmanaged keys, when
+	// spec.describeUnmanaged is set
+	// +optional
+	UnmanagedKeyDetails []UnmanagedKey `json:"unmanagedKeyDetails,omitempty"`
+
+	// KeysWithoutTTL is the number of scanned keys that never expire
+	// +optional
+	KeysWithoutTTL int64 `json:"keysWithoutTTL,omitempty"`
+
+	// KeyWithoutTTLSamples lists some of the keys that never expire
+	// +optional
+	KeyWithoutTTLSamples []string `json:"keyWithoutTTLSamples,omitempty"`
+
+	// OversizedKeys lists keys using more than spec.oversizedBytes, largest
+	// first
+	// +optional
+	OversizedKeys []AuditedKey `json:"oversizedKeys,omitempty"`
+
+	// EntriesInSync is the number of compared entries matching Redis, when
+	// spec.compareEntries is set
+	// +optional
+	EntriesInSync int64 `json:"entriesInSync,omitempty"`
+
+	// EntriesDrifted is the number of compared entries differing from Redis
+	// +optional
+	EntriesDrifted int64 `json:"entriesDrifted,omitempty"`
+
+	// DriftedEntries lists some of the entries differing from Redis
+	// +optional
+	DriftedEntries []DriftedEntry `json:"driftedEntries,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=raudit,categories=redis
+// +kubebuilder:printcolumn:name="Pattern",type="string",JSONPath=".spec.pattern"
+// +kubebuilder:printcolumn:name="Scanned",type="integer",JSONPath=".status.scannedKeys"
+// +kubebuilder:printcolumn:name="Unmanaged",type="integer",JSONPath=".status.unmanagedKeys"
+// +kubebuilder:printcolumn:name="Completed",type="date",JSONPath=".status.completionTime"
+
+// RedisAudit is the Schema for the redisaudits API. Each generation of an
+// audit is run once; edit the spec to run it again.
+type RedisAudit struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RedisAuditSpec   `json:"spec,omitempty"`
+	Status RedisAuditStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RedisAuditList contains a list of RedisAudit.
+type RedisAuditList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RedisAudit `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RedisAudit{}, &RedisAuditList{})
+}
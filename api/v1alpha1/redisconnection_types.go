@@ -0,0 +1,116 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RedisConnectionSpec describes a Redis server RedisEntries can be written
+// to instead of the controller's default connection.
+// +kubebuilder:validation:XValidation:rule="!has(self.cluster) || !self.cluster || !has(self.db) || self.db == 0",message="a Redis Cluster only has db 0"
+type RedisConnectionSpec struct {
+	// Address is the host:port of the Redis server
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^\S+:[0-9]+$`
+	Address string `json:"address"`
+
+	// DB is the database selected after connecting
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	DB int32 `json:"db,omitempty"`
+
+	// Cluster connects to a Redis Cluster, with Address as the first node
+	// asked for the slot layout. Keys are sent to the node serving their slot.
+	// +kubebuilder:validation:Optional
+	Cluster bool `json:"cluster,omitempty"`
+
+	// CredentialsSecretRef names a Secret in the connection's namespace with
+	// a password key and an optional username key for Redis ACL users
+	// +kubebuilder:validation:Optional
+	CredentialsSecretRef *corev1.LocalObjectReference `json:"credentialsSecretRef,omitempty"`
+
+	// TLS, when set, connects over TLS
+	// +kubebuilder:validation:Optional
+	TLS *ConnectionTLS `json:"tls,omitempty"`
+}
+
+// ConnectionTLS configures TLS for a RedisConnection.
+type ConnectionTLS struct {
+	// CASecretRef selects a Secret key in the connection's namespace holding
+	// the PEM-encoded CA certificates that sign the server certificate.
+	// Defaults to the system roots.
+	// +kubebuilder:validation:Optional
+	CASecretRef *corev1.SecretKeySelector `json:"caSecretRef,omitempty"`
+
+	// ServerName is the name verified in the server certificate. Defaults to
+	// the host of Address.
+	// +kubebuilder:validation:Optional
+	ServerName string `json:"serverName,omitempty"`
+
+	// InsecureSkipVerify accepts any server certificate. Only meant for
+	// testing.
+	// +kubebuilder:validation:Optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// RedisConnectionStatus reports whether the controller can reach the server.
+type RedisConnectionStatus struct {
+	// Conditions represent the latest available observations of the
+	// connection
+	// +listType=map
+	// +listMapKey=type
+	// +kubebuilder:validation:MaxItems=8
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// LastChecked is when the controller last pinged the server
+	// +optional
+	LastChecked *metav1.Time `json:"lastChecked,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=rconn,categories=redis
+// +kubebuilder:printcolumn:name="Address",type="string",JSONPath=".spec.address"
+// +kubebuilder:printcolumn:name="DB",type="integer",JSONPath=".spec.db",priority=1
+// +kubebuilder:printcolumn:name="Cluster",type="boolean",JSONPath=".spec.cluster",priority=1
+// +kubebuilder:printcolumn:name="Available",type="string",JSONPath=".status.conditions[?(@.type==\"Available\")].status"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// RedisConnection is the Schema for the redisconnections API. RedisEntries
+// in the same namespace reference it by name to be written to its server.
+type RedisConnection struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RedisConnectionSpec   `json:"spec,omitempty"`
+	Status RedisConnectionStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RedisConnectionList contains a list of RedisConnection.
+type RedisConnectionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RedisConnection `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RedisConnection{}, &RedisConnectionList{})
+}
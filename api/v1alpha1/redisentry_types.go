@@ -17,49 +17,308 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// CanaryLabel set to "true" marks a RedisEntry as managed by the canary
+// controller during a progressive rollout of a new controller version.
+const CanaryLabel = "redis.aaspcodes.github.io/canary"
+
+// KeyCleanupFinalizer holds a RedisEntry until the controller has deleted its
+// keys from Redis.
+const KeyCleanupFinalizer = "redis.aaspcodes.github.io/key-cleanup"
+
+// RefreshStatusAnnotation set to "true" makes the controller read the key
+// back from Redis into status.currentValue without writing it. The
+// controller removes the annotation once the status is refreshed.
+const RefreshStatusAnnotation = "redis.aaspcodes.github.io/refresh-status"
+
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
 // RedisEntrySpec defines the desired state of RedisEntry.
+// +kubebuilder:validation:XValidation:rule="!has(self.entries) || !(self.key in self.entries)",message="entries must not repeat spec.key"
+// +kubebuilder:validation:XValidation:rule="!(has(self.value) && has(self.valueFrom))",message="value and valueFrom are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="!(has(self.structuredValue) && (has(self.value) || has(self.valueFrom)))",message="structuredValue is mutually exclusive with value and valueFrom"
 type RedisEntrySpec struct {
-	// Key is the Redis key to be set
+	// Key is the Redis key to be set. Carriage returns, newlines and NUL
+	// characters are not allowed.
 	// +kubebuilder:validation:Required
 	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Pattern=`^[^\r\n\x00]+$`
 	Key string `json:"key"`
 
 	// Value is the value to be stored in Redis
-	// +kubebuilder:validation:Required
-	Value string `json:"value"`
+	// +kubebuilder:validation:Optional
+	Value string `json:"value,omitempty"`
+
+	// ValueFrom fetches the value from an external source instead of Value
+	// +kubebuilder:validation:Optional
+	ValueFrom *ValueSource `json:"valueFrom,omitempty"`
+
+	// StructuredValue is a JSON object written instead of Value, so it can
+	// be declared in YAML rather than as an escaped JSON string. It is
+	// serialized with sorted keys, so equal objects are written identically.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	StructuredValue *runtime.RawExtension `json:"structuredValue,omitempty"`
+
+	// ConnectionRef names a RedisConnection in the entry's namespace whose
+	// server the entry is written to. Defaults to the controller's own
+	// connection.
+	// +kubebuilder:validation:Optional
+	ConnectionRef *corev1.LocalObjectReference `json:"connectionRef,omitempty"`
 
 	// TTL is the time-to-live in seconds for the key-value pair
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:validation:Minimum=0
 	TTL *int64 `json:"ttl,omitempty"`
+
+	// CommandTimeoutSeconds overrides the read and write timeout of the
+	// commands sent for this entry, for unusually large values or slow
+	// targets
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=300
+	CommandTimeoutSeconds *int32 `json:"commandTimeoutSeconds,omitempty"`
+
+	// Entries are additional key-value pairs written together with Key in a
+	// single transaction. The TTL, when set, applies to every pair. Keys
+	// follow the same rules as Key, enforced by the controller.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxProperties=32
+	Entries map[string]string `json:"entries,omitempty"`
+
+	// ActiveDeadlineSeconds limits how long the entry is kept in Redis,
+	// counted from its creation. Once exceeded, the controller deletes the
+	// keys and marks the entry Completed, regardless of any TTL.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty"`
+
+	// TTLSecondsAfterFinished, when set, deletes the RedisEntry itself this
+	// many seconds after it completed, like a Job's ttlSecondsAfterFinished.
+	// 0 deletes it right away.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	TTLSecondsAfterFinished *int64 `json:"ttlSecondsAfterFinished,omitempty"`
+
+	// Checksum, when set to SHA256, writes a companion key named
+	// <key>:sha256 holding the hex SHA-256 of the value, with the same TTL.
+	// Drift detection then compares the checksum and the value's length
+	// instead of reading the whole value.
+	// +kubebuilder:validation:Optional
+	Checksum ChecksumAlgorithm `json:"checksum,omitempty"`
+
+	// ChunkSizeBytes, when set, splits values larger than this into chunk
+	// keys named <key>:chunk:<n>, counting from 0. The key itself then holds
+	// a JSON manifest with the number of chunks, the total size in bytes and
+	// the SHA-256 of the value.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1024
+	ChunkSizeBytes *int64 `json:"chunkSizeBytes,omitempty"`
+
+	// RetryPolicy overrides how quickly the entry is retried after a failed
+	// write to Redis
+	// +kubebuilder:validation:Optional
+	RetryPolicy *RetryPolicy `json:"retryPolicy,omitempty"`
+
+	// DeletionPolicy decides what happens to the keys in Redis when the entry
+	// is deleted. Defaults to Delete.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=Delete
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+// ValueSource describes where to fetch an entry's value from. Exactly one
+// source must be set.
+// +kubebuilder:validation:MinProperties=1
+// +kubebuilder:validation:MaxProperties=1
+type ValueSource struct {
+	// HTTP fetches the value from an HTTP(S) URL
+	// +kubebuilder:validation:Optional
+	HTTP *HTTPValueSource `json:"http,omitempty"`
+
+	// SecretKeyRef reads the value from a key of a Secret in the entry's
+	// namespace. The entry is synced again when the Secret changes.
+	// +kubebuilder:validation:Optional
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+
+	// ConfigMapKeyRef reads the value from a key of a ConfigMap in the
+	// entry's namespace, for large values or values shared by several
+	// entries. The entry is synced again when the ConfigMap changes.
+	// +kubebuilder:validation:Optional
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+}
+
+// HTTPValueSource fetches a value over HTTP(S). The content must match a
+// pinned SHA-256 checksum, so a changed or tampered document is never written.
+type HTTPValueSource struct {
+	// URL is the http:// or https:// location of the content
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^https?://`
+	URL string `json:"url"`
+
+	// SHA256 is the expected hex-encoded SHA-256 checksum of the content
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^[a-f0-9]{64}$`
+	SHA256 string `json:"sha256"`
+
+	// AuthHeaderSecretRef selects a Secret key in the entry's namespace whose
+	// value is sent as the Authorization header
+	// +kubebuilder:validation:Optional
+	AuthHeaderSecretRef *corev1.SecretKeySelector `json:"authHeaderSecretRef,omitempty"`
 }
 
+// RetryPolicy describes an exponential backoff for failed writes.
+// +kubebuilder:validation:XValidation:rule="!has(self.initialDelay) || duration(self.initialDelay) > duration('0s')",message="initialDelay must be positive"
+// +kubebuilder:validation:XValidation:rule="!has(self.maxDelay) || duration(self.maxDelay) > duration('0s')",message="maxDelay must be positive"
+// +kubebuilder:validation:XValidation:rule="!has(self.multiplier) || quantity(string(self.multiplier)).compareTo(quantity('1')) >= 0",message="multiplier must be at least 1"
+type RetryPolicy struct {
+	// InitialDelay is the delay before the first retry. Defaults to 5s.
+	// +kubebuilder:validation:Optional
+	InitialDelay *metav1.Duration `json:"initialDelay,omitempty"`
+
+	// MaxDelay caps the delay between retries. Defaults to 5m.
+	// +kubebuilder:validation:Optional
+	MaxDelay *metav1.Duration `json:"maxDelay,omitempty"`
+
+	// Multiplier is applied to the delay after each consecutive failure, and
+	// may be fractional, such as "1.5". Defaults to 2.
+	// +kubebuilder:validation:Optional
+	Multiplier *resource.Quantity `json:"multiplier,omitempty"`
+}
+
+// ChecksumAlgorithm selects how the checksum of a value is computed.
+// +kubebuilder:validation:Enum=SHA256
+type ChecksumAlgorithm string
+
+const (
+	// ChecksumSHA256 is the hex-encoded SHA-256 of the value
+	ChecksumSHA256 ChecksumAlgorithm = "SHA256"
+)
+
+// DeletionPolicy describes what happens to an entry's keys when the entry is
+// deleted.
+// +kubebuilder:validation:Enum=Delete;Retain;Orphan
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyDelete deletes the keys before the entry is removed
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+	// DeletionPolicyRetain leaves the keys in Redis; the controller still
+	// records the decision before the entry is removed
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+	// DeletionPolicyOrphan leaves the keys in Redis and the entry without a
+	// finalizer, so it is removed right away even when the controller is
+	// not running
+	DeletionPolicyOrphan DeletionPolicy = "Orphan"
+)
+
+// WriteMode describes how the keys of an entry are written together.
+// +kubebuilder:validation:Enum=Transaction;Pipeline
+type WriteMode string
+
+const (
+	// WriteModeTransaction writes all keys atomically in MULTI/EXEC
+	WriteModeTransaction WriteMode = "Transaction"
+	// WriteModePipeline writes the keys in a pipeline without atomicity
+	WriteModePipeline WriteMode = "Pipeline"
+)
+
 // RedisEntryStatus defines the observed state of RedisEntry.
 type RedisEntryStatus struct {
 	// Conditions represent the latest available observations of the RedisEntry's state
+	// +listType=map
+	// +listMapKey=type
+	// +kubebuilder:validation:MaxItems=8
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 
 	// LastUpdated is the timestamp of the last successful update to Redis
 	// +optional
 	LastUpdated *metav1.Time `json:"lastUpdated,omitempty"`
 
-	// CurrentValue represents the current value in Redis for the key
+	// CurrentValue represents the current value in Redis for the key, as
+	// last read on request through the refresh-status annotation. Values
+	// read from a Secret are shown as a hash, and long values are truncated.
 	// +optional
 	CurrentValue string `json:"currentValue,omitempty"`
+
+	// LastRefreshed is when CurrentValue was last read from Redis
+	// +optional
+	LastRefreshed *metav1.Time `json:"lastRefreshed,omitempty"`
+
+	// SyncAttempts counts the writes to Redis attempted for this entry
+	// +optional
+	SyncAttempts int64 `json:"syncAttempts,omitempty"`
+
+	// LastSyncTime is the timestamp of the most recent write attempt,
+	// successful or not
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+
+	// LastSyncDurationMillis is how long the most recent write attempt took
+	// +optional
+	LastSyncDurationMillis int64 `json:"lastSyncDurationMillis,omitempty"`
+
+	// WriteMode is how an entry with additional keys was last written:
+	// Transaction (MULTI/EXEC) or Pipeline, used by proxies and for keys in
+	// different Redis Cluster hash slots
+	// +optional
+	WriteMode WriteMode `json:"writeMode,omitempty"`
+
+	// Chunks is the number of chunk keys the value was last split into
+	// +optional
+	Chunks int32 `json:"chunks,omitempty"`
+
+	// ChunkedKey is the main key the chunk keys were last written under, so
+	// they are deleted after spec.key is renamed
+	// +optional
+	ChunkedKey string `json:"chunkedKey,omitempty"`
+
+	// LastError is the error of the most recent write attempt; it is cleared
+	// once a write succeeds
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+
+	// FailedKeys lists the keys the most recent write failed on when others
+	// were written; it is cleared once a write succeeds
+	// +optional
+	// +kubebuilder:validation:MaxItems=20
+	FailedKeys []KeyFailure `json:"failedKeys,omitempty"`
+
+	// CompletionTime is when the keys were deleted after the active
+	// deadline passed
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// LastDriftDetected is when Redis was last found holding a value that
+	// differs from the spec of an already synced entry
+	// +optional
+	LastDriftDetected *metav1.Time `json:"lastDriftDetected,omitempty"`
+}
+
+// KeyFailure is a key that could not be written to Redis.
+type KeyFailure struct {
+	// Key is the Redis key
+	Key string `json:"key"`
+
+	// Error is the error Redis returned for the key
+	Error string `json:"error"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=re;rentry,categories=redis;all
 // +kubebuilder:printcolumn:name="Key",type="string",JSONPath=".spec.key"
 // +kubebuilder:printcolumn:name="Value",type="string",JSONPath=".spec.value"
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 // +kubebuilder:printcolumn:name="Last Updated",type="date",JSONPath=".status.lastUpdated"
+// +kubebuilder:printcolumn:name="Sync Millis",type="integer",JSONPath=".status.lastSyncDurationMillis",priority=1
 
 // RedisEntry is the Schema for the redisentries API.
 type RedisEntry struct {
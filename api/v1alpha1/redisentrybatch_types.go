@@ -0,0 +1,129 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// BatchEntryLabel is set on the RedisEntries created for a batch to the UID
+// of the batch.
+const BatchEntryLabel = "redis.aaspcodes.github.io/batch-uid"
+
+// BatchItem is a single key-value pair of a batch.
+type BatchItem struct {
+	// Key is the Redis key to be set, following the same rules as a
+	// RedisEntry's key
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Pattern=`^[^\r\n\x00]+$`
+	Key string `json:"key"`
+
+	// Value is the value to be stored in Redis
+	// +kubebuilder:validation:Optional
+	Value string `json:"value,omitempty"`
+}
+
+// RedisEntryBatchSpec defines the key-value pairs managed by a batch.
+type RedisEntryBatchSpec struct {
+	// Items are the key-value pairs to write. Each item is expanded into a
+	// RedisEntry owned by the batch.
+	// +listType=map
+	// +listMapKey=key
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=5000
+	Items []BatchItem `json:"items"`
+
+	// TTL is the time-to-live in seconds applied to every item
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	TTL *int64 `json:"ttl,omitempty"`
+}
+
+// RedisEntryBatchStatus aggregates the status of the batch's RedisEntries.
+type RedisEntryBatchStatus struct {
+	// Conditions represent the latest available observations of the batch
+	// +listType=map
+	// +listMapKey=type
+	// +kubebuilder:validation:MaxItems=8
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// ObservedGeneration is the batch generation the entries were last
+	// updated for
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	OwnedEntriesStatus `json:",inline"`
+}
+
+// OwnedEntriesStatus aggregates the status of the RedisEntries created for a
+// batch or a template.
+type OwnedEntriesStatus struct {
+	// Entries is the number of RedisEntries owned by the resource
+	// +optional
+	Entries int32 `json:"entries,omitempty"`
+
+	// Synced is the number of entries written to Redis at their current
+	// generation
+	// +optional
+	Synced int32 `json:"synced,omitempty"`
+
+	// Failed is the number of entries whose last sync failed
+	// +optional
+	Failed int32 `json:"failed,omitempty"`
+
+	// Pending is the number of entries not yet synced
+	// +optional
+	Pending int32 `json:"pending,omitempty"`
+
+	// FailedItems lists some of the keys whose entry failed to sync
+	// +optional
+	// +kubebuilder:validation:MaxItems=20
+	FailedItems []KeyFailure `json:"failedItems,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=rebatch,categories=redis
+// +kubebuilder:printcolumn:name="Entries",type="integer",JSONPath=".status.entries"
+// +kubebuilder:printcolumn:name="Synced",type="integer",JSONPath=".status.synced"
+// +kubebuilder:printcolumn:name="Failed",type="integer",JSONPath=".status.failed"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// RedisEntryBatch is the Schema for the redisentrybatches API. It declares
+// many key-value pairs in one object; the controller creates a RedisEntry for
+// each of them and reports aggregate counts.
+type RedisEntryBatch struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RedisEntryBatchSpec   `json:"spec,omitempty"`
+	Status RedisEntryBatchStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RedisEntryBatchList contains a list of RedisEntryBatch.
+type RedisEntryBatchList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RedisEntryBatch `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RedisEntryBatch{}, &RedisEntryBatchList{})
+}
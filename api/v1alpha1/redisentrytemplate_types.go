@@ -0,0 +1,114 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TemplateEntryLabel is set on the RedisEntries created for a template to
+// the UID of the template.
+const TemplateEntryLabel = "redis.aaspcodes.github.io/template-uid"
+
+// RedisEntryTemplateSpec defines the keys a template expands into.
+// +kubebuilder:validation:XValidation:rule="has(self.parameters) || has(self.parametersFrom)",message="parameters or parametersFrom is required"
+type RedisEntryTemplateSpec struct {
+	// Key is a Go template rendering the key of each entry from a parameter
+	// set, e.g. "ratelimit:{{ .region }}". Referencing a parameter missing
+	// from a set is an error.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Key string `json:"key"`
+
+	// Value is a Go template rendering the value of each entry
+	// +kubebuilder:validation:Optional
+	Value string `json:"value,omitempty"`
+
+	// TTL is the time-to-live in seconds applied to every entry
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	TTL *int64 `json:"ttl,omitempty"`
+
+	// Parameters are parameter sets; each one is expanded into an entry
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxItems=5000
+	Parameters []map[string]string `json:"parameters,omitempty"`
+
+	// ParametersFrom reads further parameter sets from a ConfigMap
+	// +kubebuilder:validation:Optional
+	ParametersFrom *ParametersSource `json:"parametersFrom,omitempty"`
+}
+
+// ParametersSource locates parameter sets outside the template.
+type ParametersSource struct {
+	// ConfigMapKeyRef selects a ConfigMap key holding a YAML or JSON list of
+	// parameter sets, each a map of names to values
+	// +kubebuilder:validation:Required
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef"`
+}
+
+// RedisEntryTemplateStatus aggregates the status of the template's
+// RedisEntries.
+type RedisEntryTemplateStatus struct {
+	// Conditions represent the latest available observations of the template
+	// +listType=map
+	// +listMapKey=type
+	// +kubebuilder:validation:MaxItems=8
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// ObservedGeneration is the template generation the entries were last
+	// updated for
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	OwnedEntriesStatus `json:",inline"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=retemplate,categories=redis
+// +kubebuilder:printcolumn:name="Key",type="string",JSONPath=".spec.key"
+// +kubebuilder:printcolumn:name="Entries",type="integer",JSONPath=".status.entries"
+// +kubebuilder:printcolumn:name="Synced",type="integer",JSONPath=".status.synced"
+// +kubebuilder:printcolumn:name="Failed",type="integer",JSONPath=".status.failed"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// RedisEntryTemplate is the Schema for the redisentrytemplates API. It
+// expands a key and value template across parameter sets; the controller
+// creates a RedisEntry for each rendered key and prunes the entries of
+// removed parameter sets.
+type RedisEntryTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RedisEntryTemplateSpec   `json:"spec,omitempty"`
+	Status RedisEntryTemplateStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RedisEntryTemplateList contains a list of RedisEntryTemplate.
+type RedisEntryTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RedisEntryTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RedisEntryTemplate{}, &RedisEntryTemplateList{})
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RedisTransactionSpec names the RedisEntries applied together.
+type RedisTransactionSpec struct {
+	// Entries are the names of RedisEntries in the transaction's namespace,
+	// written in this order. While a transaction lists an entry, the entry is
+	// only written through the transaction.
+	// +listType=set
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=32
+	Entries []string `json:"entries"`
+}
+
+// TransactionStepResult is the outcome of one step of a transaction.
+// +kubebuilder:validation:Enum=Applied;Failed;RolledBack;RollbackFailed;Skipped
+type TransactionStepResult string
+
+const (
+	// StepApplied means the entry's keys were written
+	StepApplied TransactionStepResult = "Applied"
+	// StepFailed means writing the entry's keys failed; any keys it did
+	// write were rolled back
+	StepFailed TransactionStepResult = "Failed"
+	// StepRolledBack means the entry's keys were written and then restored
+	// to their prior values after a later step failed
+	StepRolledBack TransactionStepResult = "RolledBack"
+	// StepRollbackFailed means restoring the entry's keys failed, so Redis
+	// may hold a mix of old and new values
+	StepRollbackFailed TransactionStepResult = "RollbackFailed"
+	// StepSkipped means the step was not attempted because an earlier one
+	// failed
+	StepSkipped TransactionStepResult = "Skipped"
+)
+
+// TransactionStep records how one entry of the transaction was applied.
+type TransactionStep struct {
+	// Entry is the name of the RedisEntry
+	Entry string `json:"entry"`
+
+	// Generation is the entry generation that was applied
+	// +optional
+	Generation int64 `json:"generation,omitempty"`
+
+	// ValueHash is the SHA-256 of the value that was applied, so the entry
+	// is applied again when its Secret or ConfigMap changes
+	// +optional
+	ValueHash string `json:"valueHash,omitempty"`
+
+	// Result is the outcome of the step
+	Result TransactionStepResult `json:"result"`
+
+	// Error describes why the step or its rollback failed
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// RedisTransactionStatus records the outcome of the last attempt to apply
+// the transaction.
+type RedisTransactionStatus struct {
+	// Conditions represent the latest available observations of the
+	// transaction
+	// +listType=map
+	// +listMapKey=type
+	// +kubebuilder:validation:MaxItems=8
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Steps are the results of the last attempt, one per entry in order
+	// +optional
+	// +kubebuilder:validation:MaxItems=32
+	Steps []TransactionStep `json:"steps,omitempty"`
+
+	// LastAttemptTime is when the transaction was last applied or attempted
+	// +optional
+	LastAttemptTime *metav1.Time `json:"lastAttemptTime,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=retx,categories=redis
+// +kubebuilder:printcolumn:name="Available",type="string",JSONPath=".status.conditions[?(@.type==\"Available\")].status"
+// +kubebuilder:printcolumn:name="Last Attempt",type="date",JSONPath=".status.lastAttemptTime"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// RedisTransaction is the Schema for the redistransactions API. It applies
+// several RedisEntries together: when one of them fails to write, the keys
+// already written are restored to the values they held before.
+type RedisTransaction struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RedisTransactionSpec   `json:"spec,omitempty"`
+	Status RedisTransactionStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RedisTransactionList contains a list of RedisTransaction.
+type RedisTransactionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RedisTransaction `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RedisTransaction{}, &RedisTransactionList{})
+}
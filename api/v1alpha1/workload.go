@@ -0,0 +1,32 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+const (
+	// WorkloadEntriesAnnotation declares the Redis keys of a Deployment or
+	// StatefulSet as a JSON object mapping each key to its value. The
+	// controller creates a RedisEntry per key, owned by the workload.
+	WorkloadEntriesAnnotation = "redis.aaspcodes.github.io/entries"
+
+	// WorkloadTTLAnnotation optionally sets the TTL in seconds of the keys
+	// declared by WorkloadEntriesAnnotation.
+	WorkloadTTLAnnotation = "redis.aaspcodes.github.io/ttl"
+
+	// WorkloadEntryLabel is set on the RedisEntries created for a workload
+	// to the UID of the workload.
+	WorkloadEntryLabel = "redis.aaspcodes.github.io/workload-uid"
+)
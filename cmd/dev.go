@@ -0,0 +1,161 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+// devRedisAddress is where --dev expects Redis when no address or Service is given.
+const devRedisAddress = "localhost:6379"
+
+// portForwardService forwards a local port to a ready pod behind a Service
+// given as [namespace/]name[:port] and returns the local address. The forward
+// stays open until ctx ends; it does not follow the Service to a new pod.
+func portForwardService(ctx context.Context, cfg *rest.Config, service string) (string, error) {
+	namespace, name := metav1.NamespaceDefault, service
+	if ns, n, ok := strings.Cut(service, "/"); ok {
+		namespace, name = ns, n
+	}
+	name, portName, _ := strings.Cut(name, ":")
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return "", err
+	}
+	svc, err := clientset.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get Service %s/%s: %w", namespace, name, err)
+	}
+	servicePort, err := findServicePort(svc, portName)
+	if err != nil {
+		return "", err
+	}
+	pod, err := readyPodFor(ctx, clientset, svc)
+	if err != nil {
+		return "", err
+	}
+	podPort, err := resolveTargetPort(pod, servicePort)
+	if err != nil {
+		return "", err
+	}
+
+	transport, upgrader, err := spdy.RoundTripperFor(cfg)
+	if err != nil {
+		return "", err
+	}
+	url := clientset.CoreV1().RESTClient().Post().
+		Resource("pods").Namespace(pod.Namespace).Name(pod.Name).SubResource("portforward").URL()
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, url)
+
+	ready := make(chan struct{})
+	forwarder, err := portforward.NewOnAddresses(dialer, []string{"127.0.0.1"},
+		[]string{fmt.Sprintf("0:%d", podPort)}, ctx.Done(), ready, io.Discard, os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	failed := make(chan error, 1)
+	go func() {
+		failed <- forwarder.ForwardPorts()
+	}()
+	select {
+	case <-ready:
+	case err := <-failed:
+		return "", fmt.Errorf("failed to port-forward to pod %s/%s: %w", pod.Namespace, pod.Name, err)
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+
+	ports, err := forwarder.GetPorts()
+	if err != nil {
+		return "", err
+	}
+	return "127.0.0.1:" + strconv.Itoa(int(ports[0].Local)), nil
+}
+
+// findServicePort returns the Service port with the given name or number, or
+// the first port when none is given.
+func findServicePort(svc *corev1.Service, port string) (corev1.ServicePort, error) {
+	if len(svc.Spec.Ports) == 0 {
+		return corev1.ServicePort{}, fmt.Errorf("service %s/%s has no ports", svc.Namespace, svc.Name)
+	}
+	if port == "" {
+		return svc.Spec.Ports[0], nil
+	}
+	for _, p := range svc.Spec.Ports {
+		if p.Name == port || strconv.Itoa(int(p.Port)) == port {
+			return p, nil
+		}
+	}
+	return corev1.ServicePort{}, fmt.Errorf("service %s/%s has no port %s", svc.Namespace, svc.Name, port)
+}
+
+// readyPodFor returns a ready pod selected by the Service.
+func readyPodFor(ctx context.Context, clientset kubernetes.Interface, svc *corev1.Service) (*corev1.Pod, error) {
+	if len(svc.Spec.Selector) == 0 {
+		return nil, fmt.Errorf("service %s/%s has no selector", svc.Namespace, svc.Name)
+	}
+	pods, err := clientset.CoreV1().Pods(svc.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(svc.Spec.Selector).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range pods.Items {
+		for _, condition := range pods.Items[i].Status.Conditions {
+			if condition.Type == corev1.PodReady && condition.Status == corev1.ConditionTrue {
+				return &pods.Items[i], nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("service %s/%s has no ready pods", svc.Namespace, svc.Name)
+}
+
+// resolveTargetPort returns the container port a Service port targets on a pod.
+func resolveTargetPort(pod *corev1.Pod, servicePort corev1.ServicePort) (int, error) {
+	switch {
+	case servicePort.TargetPort.Type == intstr.Int && servicePort.TargetPort.IntVal != 0:
+		return int(servicePort.TargetPort.IntVal), nil
+	case servicePort.TargetPort.Type == intstr.String && servicePort.TargetPort.StrVal != "":
+		for _, container := range pod.Spec.Containers {
+			for _, port := range container.Ports {
+				if port.Name == servicePort.TargetPort.StrVal {
+					return int(port.ContainerPort), nil
+				}
+			}
+		}
+		return 0, fmt.Errorf("pod %s/%s has no port named %s", pod.Namespace, pod.Name, servicePort.TargetPort.StrVal)
+	default:
+		// An unset targetPort defaults to the port itself
+		return int(servicePort.Port), nil
+	}
+}
@@ -19,26 +19,44 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"flag"
+	"maps"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
 
 	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	"github.com/AAspCodes/redis-ctrl/internal/config"
 	"github.com/AAspCodes/redis-ctrl/internal/controller"
+	"github.com/AAspCodes/redis-ctrl/internal/features"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
+	clientmetrics "k8s.io/client-go/tools/metrics"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
 
+// serviceAccountNamespaceFile holds the namespace the controller runs in
+const serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
 var (
 	scheme   = runtime.NewScheme()
 	setupLog = ctrl.Log.WithName("setup")
@@ -60,6 +78,32 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var reservedKeyPrefixes string
+	var metricsPerEntryLabels, metricsPerKeyLabels bool
+	var metricsMaxTTLSeries int
+	var healthCheckInterval time.Duration
+	var latencyLatest bool
+	var dnsRefreshInterval time.Duration
+	var maxRedisWritesPerSecond float64
+	var redisAddress string
+	var redisProxyMode bool
+	var connectionName string
+	var statusCoalesceWindow time.Duration
+	var maxStatusUpdatesPerSecond float64
+	var entrySelector string
+	var namespaceCredentials bool
+	var configFile string
+	var logValues string
+	var maxKeyLength int
+	var keyPattern string
+	var maxConcurrentReconciles int
+	var apiBackpressure bool
+	var devMode bool
+	var markerKey string
+	var drainTimeout time.Duration
+	var devRedisService string
+	var createServiceMonitor bool
+	var rolloutTrack string
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -78,6 +122,79 @@ func main() {
 	flag.StringVar(&metricsCertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.StringVar(&reservedKeyPrefixes, "reserved-key-prefixes", "__keyspace@,__keyevent@",
+		"Comma-separated list of key prefixes the controller refuses to write.")
+	flag.BoolVar(&metricsPerEntryLabels, "metrics-per-entry-labels", false,
+		"If set, custom metrics carry namespace and name labels for each RedisEntry.")
+	flag.BoolVar(&metricsPerKeyLabels, "metrics-per-key-labels", false,
+		"If set, custom metrics carry a label with the Redis key.")
+	flag.IntVar(&metricsMaxTTLSeries, "metrics-max-ttl-series", 1000,
+		"Maximum number of keys whose remaining TTL is exported. 0 disables the TTL gauge.")
+	flag.DurationVar(&healthCheckInterval, "redis-health-check-interval", 10*time.Second,
+		"How often Redis is pinged; all entries are resynced when it recovers from an outage.")
+	flag.BoolVar(&latencyLatest, "redis-latency-latest", false,
+		"If set, export the latency spikes recorded by the Redis latency monitor (LATENCY LATEST) on "+
+			"every health check. Requires latency-monitor-threshold to be set on the server.")
+	flag.DurationVar(&dnsRefreshInterval, "redis-dns-refresh-interval", 30*time.Second,
+		"How often the Redis host name is resolved again; connections are re-established when its "+
+			"addresses change. 0 disables the check.")
+	flag.Float64Var(&maxRedisWritesPerSecond, "max-redis-writes-per-second", 0,
+		"Upper bound on Redis write operations per second across all entries. 0 disables the limit.")
+	flag.StringVar(&redisAddress, "redis-address", defaultRedisAddress(),
+		"Redis server as host:port or a redis:// or rediss:// URL. Defaults to $REDIS_URL, "+
+			"then $REDIS_HOST:$REDIS_PORT, then "+controller.DefaultRedisAddress+".")
+	flag.StringVar(&connectionName, "redis-connection-name", "default",
+		"Name of the Redis connection, set as the connection label of the custom metrics so that "+
+			"controllers managing different Redis servers can be told apart.")
+	flag.BoolVar(&redisProxyMode, "redis-proxy-mode", false,
+		"If set, only use commands that Redis proxies such as Twemproxy or Envoy support. "+
+			"Entries with additional keys are then written without a transaction.")
+	flag.DurationVar(&statusCoalesceWindow, "status-coalesce-window", 30*time.Second,
+		"Skip status writes that only refresh sync counters and timestamps if the entry's status "+
+			"was written within this window. 0 writes every change.")
+	flag.Float64Var(&maxStatusUpdatesPerSecond, "max-status-updates-per-second", 0,
+		"Upper bound on RedisEntry status writes per second across all entries. 0 disables the limit.")
+	flag.StringVar(&entrySelector, "entry-selector", "",
+		"Label selector restricting the controller to matching RedisEntries, "+
+			"e.g. redis.aaspcodes.github.io/managed=true. Empty manages all entries.")
+	flag.BoolVar(&namespaceCredentials, "namespace-credentials", false,
+		"If set, authenticate to Redis with the username and password from a Secret named "+
+			controller.NamespaceCredentialsSecret+" in the entry's namespace, when it exists.")
+	flag.StringVar(&configFile, "config", "",
+		"Path to a configuration file with settings that are reloaded when the file changes: "+
+			"maxRedisWritesPerSecond, maxStatusUpdatesPerSecond and featureGates.")
+	flag.StringVar(&logValues, "log-values", string(controller.ValueLogNever),
+		"How Redis values appear in logs: never, hashed (a short SHA-256 prefix) or always. "+
+			"Credentials are never logged.")
+	flag.IntVar(&maxKeyLength, "max-key-length", controller.DefaultMaxKeyLength,
+		"Maximum length in bytes of keys the controller writes.")
+	flag.StringVar(&keyPattern, "key-pattern", "",
+		"Regular expression every key must match, e.g. ^[a-z0-9:_-]+$. Empty allows any key.")
+	flag.IntVar(&maxConcurrentReconciles, "max-concurrent-reconciles", 1,
+		"Maximum number of RedisEntries reconciled in parallel.")
+	flag.BoolVar(&apiBackpressure, "api-backpressure", true,
+		"If set, reconcile concurrency and the status write rate are lowered while the API server "+
+			"throttles the controller, and restored once it stops.")
+	flag.StringVar(&markerKey, "dataset-marker-key", controller.DefaultMarkerKey,
+		"Key the controller keeps in Redis to notice when it loses its data, e.g. after a FLUSHALL "+
+			"or a failover to an empty replica, and resync all entries. Empty disables the check.")
+	flag.DurationVar(&drainTimeout, "drain-timeout", 5*time.Second,
+		"On shutdown or a POST to "+controller.DrainPath+", how long to wait for in-flight syncs to finish "+
+			"and deferred status writes to be flushed before the manager stops.")
+	flag.BoolVar(&devMode, "dev", false,
+		"Local development mode: run against the current kubeconfig with plain HTTP metrics and "+
+			"connect to Redis at "+devRedisAddress+" unless --redis-address or --dev-redis-service is set.")
+	flag.StringVar(&devRedisService, "dev-redis-service", "",
+		"With --dev, port-forward to a Redis Service given as [namespace/]name[:port] and connect through it.")
+	flag.StringVar(&rolloutTrack, "rollout-track", "",
+		"Rollout track of this deployment when a new controller version is rolled out progressively: "+
+			"canary manages only RedisEntries labeled "+redisv1alpha1.CanaryLabel+"=true, stable all others. "+
+			"Custom metrics get a track label so the two can be compared. Empty manages every entry.")
+	flag.BoolVar(&createServiceMonitor, "create-service-monitor", false,
+		"If set and the Prometheus Operator CRDs are installed, create a ServiceMonitor for the "+
+			"controller's metrics service in the controller's namespace.")
+	flag.Func("feature-gates", "A set of key=value pairs that describe feature gates for alpha/beta features. "+
+		"Options are:\n"+strings.Join(features.Gate.KnownFeatures(), "\n"), features.Gate.Set)
 	opts := zap.Options{
 		Development: true,
 	}
@@ -86,6 +203,22 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	if devMode {
+		// Out of the cluster there are no serving certificates to load and
+		// Redis is reached through localhost
+		setFlags := map[string]bool{}
+		flag.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+		if !setFlags["metrics-secure"] {
+			secureMetrics = false
+		}
+		webhookCertPath, metricsCertPath = "", ""
+		if !setFlags["redis-address"] && os.Getenv("REDIS_URL") == "" && os.Getenv("REDIS_HOST") == "" {
+			redisAddress = devRedisAddress
+		}
+		setupLog.Info("Running in development mode", "redis-address", redisAddress,
+			"dev-redis-service", devRedisService)
+	}
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancellation and
@@ -175,13 +308,48 @@ func main() {
 		})
 	}
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+	ctx := ctrl.SetupSignalHandler()
+	restConfig := ctrl.GetConfigOrDie()
+	// Custom metrics carry the rollout track so canary and stable can be compared
+	var metricsRegistry prometheus.Registerer = ctrlmetrics.Registry
+	if rolloutTrack != "" {
+		metricsRegistry = prometheus.WrapRegistererWith(prometheus.Labels{"track": rolloutTrack}, metricsRegistry)
+	}
+	var backpressure *controller.Backpressure
+	if apiBackpressure {
+		backpressure = controller.NewBackpressure(controller.BackpressureOptions{
+			MaxConcurrency:        maxConcurrentReconciles,
+			StatusWritesPerSecond: float64(restConfig.QPS),
+		})
+		if err := backpressure.Register(metricsRegistry); err != nil {
+			setupLog.Error(err, "unable to register backpressure metrics")
+			os.Exit(1)
+		}
+		// 429 responses are seen by the transport; waits on the client-side
+		// rate limiter are only reported through client-go's metrics hook
+		restConfig.Wrap(backpressure.WrapTransport)
+		clientmetrics.RateLimiterLatency = backpressure
+	}
+
+	// The canary elects its own leader so it runs alongside the stable controller.
+	leaderElectionID := "511e12af.aaspcodes.github.io"
+	if rolloutTrack == controller.TrackCanary {
+		leaderElectionID = controller.TrackCanary + "." + leaderElectionID
+	}
+
+	mgr, err := ctrl.NewManager(restConfig, ctrl.Options{
 		Scheme:                 scheme,
 		Metrics:                metricsServerOptions,
 		WebhookServer:          webhookServer,
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
-		LeaderElectionID:       "511e12af.aaspcodes.github.io",
+		LeaderElectionID:       leaderElectionID,
+		// Only credentials Secrets are cached; others are read on demand
+		Cache: cache.Options{
+			ByObject: map[client.Object]cache.ByObject{
+				&corev1.Secret{}: controller.SecretCacheOptions(),
+			},
+		},
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
 		// when the Manager ends. This requires the binary to immediately end when the
 		// Manager is stopped, otherwise, this setting is unsafe. Setting this significantly
@@ -199,15 +367,241 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err = (&controller.RedisEntryReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr); err != nil {
+	syncMetrics := controller.NewMetrics(controller.MetricsOptions{
+		PerEntryLabels: metricsPerEntryLabels,
+		PerKeyLabels:   metricsPerKeyLabels,
+		MaxTTLSeries:   metricsMaxTTLSeries,
+		ConnectionName: connectionName,
+	})
+	if err := syncMetrics.Register(metricsRegistry); err != nil {
+		setupLog.Error(err, "unable to register custom metrics")
+		os.Exit(1)
+	}
+
+	valueLogMode, err := controller.ParseValueLogMode(logValues)
+	if err != nil {
+		setupLog.Error(err, "invalid --log-values")
+		os.Exit(1)
+	}
+
+	keyPolicy := controller.KeyPolicy{MaxLength: maxKeyLength}
+	if keyPattern != "" {
+		if keyPolicy.Pattern, err = regexp.Compile(keyPattern); err != nil {
+			setupLog.Error(err, "invalid --key-pattern")
+			os.Exit(1)
+		}
+	}
+
+	redisOptions, err := controller.RedisOptionsFromAddress(redisAddress)
+	if err != nil {
+		setupLog.Error(err, "invalid Redis address")
+		os.Exit(1)
+	}
+	if redisOptions.Password == "" {
+		redisOptions.Password = os.Getenv("REDIS_PASSWORD")
+	}
+	if devRedisService != "" {
+		if !devMode {
+			setupLog.Error(nil, "--dev-redis-service requires --dev")
+			os.Exit(1)
+		}
+		if redisOptions.Addr, err = portForwardService(ctx, restConfig, devRedisService); err != nil {
+			setupLog.Error(err, "unable to port-forward to Redis", "service", devRedisService)
+			os.Exit(1)
+		}
+		setupLog.Info("Port-forwarding to Redis", "service", devRedisService, "address", redisOptions.Addr)
+	}
+
+	// Global ceiling on Redis writes, independent of how many entries exist.
+	// The limiters always exist so the configuration file can adjust them.
+	writeLimiter := rate.NewLimiter(rate.Inf, 1)
+	config.SetRate(writeLimiter, maxRedisWritesPerSecond)
+
+	var selector labels.Selector
+	if entrySelector != "" {
+		if selector, err = labels.Parse(entrySelector); err != nil {
+			setupLog.Error(err, "invalid entry selector")
+			os.Exit(1)
+		}
+	}
+	if selector, err = controller.TrackSelector(rolloutTrack, selector); err != nil {
+		setupLog.Error(err, "invalid --rollout-track")
+		os.Exit(1)
+	}
+
+	// Keep bulk syncs from turning into a storm of status writes
+	statusLimiter := rate.NewLimiter(rate.Inf, 1)
+	config.SetRate(statusLimiter, maxStatusUpdatesPerSecond)
+
+	if configFile != "" {
+		// Settings in the file override their flags and are reapplied
+		// whenever the file changes. Gates start from their flag values on
+		// every apply, so removing one from the file restores it.
+		flagGates := features.Snapshot()
+		loaded := false
+		watcher := config.NewWatcher(configFile, 0, func(cfg *config.Config) error {
+			gates := maps.Clone(flagGates)
+			maps.Copy(gates, cfg.FeatureGates)
+			if loaded {
+				if err := features.CheckReload(gates); err != nil {
+					return err
+				}
+			}
+			if err := features.Gate.SetFromMap(gates); err != nil {
+				return err
+			}
+			writes, statuses := maxRedisWritesPerSecond, maxStatusUpdatesPerSecond
+			if cfg.MaxRedisWritesPerSecond != nil {
+				writes = *cfg.MaxRedisWritesPerSecond
+			}
+			if cfg.MaxStatusUpdatesPerSecond != nil {
+				statuses = *cfg.MaxStatusUpdatesPerSecond
+			}
+			config.SetRate(writeLimiter, writes)
+			config.SetRate(statusLimiter, statuses)
+			return nil
+		})
+		if _, err := watcher.Load(); err != nil {
+			setupLog.Error(err, "unable to load configuration file", "path", configFile)
+			os.Exit(1)
+		}
+		loaded = true
+		if err := watcher.Register(metricsRegistry); err != nil {
+			setupLog.Error(err, "unable to register configuration metric")
+			os.Exit(1)
+		}
+		if err := mgr.Add(watcher); err != nil {
+			setupLog.Error(err, "unable to add configuration watcher")
+			os.Exit(1)
+		}
+	}
+
+	entryReconciler := &controller.RedisEntryReconciler{
+		Client:               mgr.GetClient(),
+		Scheme:               mgr.GetScheme(),
+		RedisOptions:         redisOptions,
+		ReservedKeyPrefixes:  splitList(reservedKeyPrefixes),
+		KeyPolicy:            keyPolicy,
+		Metrics:              syncMetrics,
+		ConnectionName:       connectionName,
+		HealthCheckInterval:  healthCheckInterval,
+		LatencyLatest:        latencyLatest,
+		DNSRefreshInterval:   dnsRefreshInterval,
+		WriteLimiter:         writeLimiter,
+		ProxyMode:            redisProxyMode,
+		StatusCoalesceWindow: statusCoalesceWindow,
+		StatusLimiter:        statusLimiter,
+		EntrySelector:        selector,
+		NamespaceCredentials: namespaceCredentials,
+		LogValues:            valueLogMode,
+		APIReader:            mgr.GetAPIReader(),
+
+		MaxConcurrentReconciles: maxConcurrentReconciles,
+		Backpressure:            backpressure,
+		MarkerKey:               markerKey,
+	}
+	if err = entryReconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "RedisEntry")
 		os.Exit(1)
 	}
+	// Served next to /metrics, behind the same authentication and authorization.
+	// Without --metrics-secure nothing checks the caller, so they are left out.
+	if secureMetrics {
+		if err = mgr.AddMetricsServerExtraHandler(controller.ResyncPath, entryReconciler.ResyncHandler()); err != nil {
+			setupLog.Error(err, "unable to add resync endpoint")
+			os.Exit(1)
+		}
+		if err = mgr.AddMetricsServerExtraHandler(controller.DrainPath, entryReconciler.DrainHandler()); err != nil {
+			setupLog.Error(err, "unable to add drain endpoint")
+			os.Exit(1)
+		}
+		if err = mgr.AddMetricsServerExtraHandler(controller.DiffPath, entryReconciler.DiffHandler()); err != nil {
+			setupLog.Error(err, "unable to add diff endpoint")
+			os.Exit(1)
+		}
+	} else {
+		setupLog.Info("Metrics are served without authorization; not serving the resync, drain and diff endpoints",
+			"paths", []string{controller.ResyncPath, controller.DrainPath, controller.DiffPath})
+	}
+	// Both tracks keep the clients of their RedisConnections up to date, but
+	// only the stable controller reports their status
+	if err = (&controller.RedisConnectionReconciler{
+		Client:     mgr.GetClient(),
+		Scheme:     mgr.GetScheme(),
+		Entries:    entryReconciler,
+		SkipStatus: rolloutTrack == controller.TrackCanary,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RedisConnection")
+		os.Exit(1)
+	}
+	// The canary track only reconciles RedisEntries; every other resource stays
+	// with the stable controller so the two never act on the same objects.
+	if rolloutTrack != controller.TrackCanary {
+		if err = (&controller.RedisAuditReconciler{
+			Client:      mgr.GetClient(),
+			Scheme:      mgr.GetScheme(),
+			RedisClient: entryReconciler.RedisClient,
+			ProxyMode:   redisProxyMode,
+			APIReader:   mgr.GetAPIReader(),
+			Entries:     entryReconciler,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "RedisAudit")
+			os.Exit(1)
+		}
+		if err = (&controller.RedisEntryBatchReconciler{
+			Client: mgr.GetClient(),
+			Scheme: mgr.GetScheme(),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "RedisEntryBatch")
+			os.Exit(1)
+		}
+		if err = (&controller.RedisEntryTemplateReconciler{
+			Client:    mgr.GetClient(),
+			Scheme:    mgr.GetScheme(),
+			APIReader: mgr.GetAPIReader(),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "RedisEntryTemplate")
+			os.Exit(1)
+		}
+		if err = (&controller.RedisTransactionReconciler{
+			Client:  mgr.GetClient(),
+			Scheme:  mgr.GetScheme(),
+			Entries: entryReconciler,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "RedisTransaction")
+			os.Exit(1)
+		}
+		if features.Enabled(features.WorkloadEntries) {
+			for _, kind := range controller.WorkloadKinds {
+				if err = (&controller.WorkloadEntryReconciler{
+					Client: mgr.GetClient(),
+					Scheme: mgr.GetScheme(),
+					Kind:   kind,
+				}).SetupWithManager(mgr); err != nil {
+					setupLog.Error(err, "unable to create controller", "controller", kind.Kind+"Entries")
+					os.Exit(1)
+				}
+			}
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
+	if createServiceMonitor {
+		namespace, err := os.ReadFile(serviceAccountNamespaceFile)
+		if err != nil {
+			setupLog.Error(err, "unable to determine the controller namespace for the ServiceMonitor")
+			os.Exit(1)
+		}
+		if err := mgr.Add(&controller.ServiceMonitorInstaller{
+			Client:    mgr.GetClient(),
+			Namespace: strings.TrimSpace(string(namespace)),
+			Secure:    secureMetrics,
+		}); err != nil {
+			setupLog.Error(err, "unable to add ServiceMonitor installer")
+			os.Exit(1)
+		}
+	}
+
 	if metricsCertWatcher != nil {
 		setupLog.Info("Adding metrics certificate watcher to manager")
 		if err := mgr.Add(metricsCertWatcher); err != nil {
@@ -233,9 +627,51 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Stopping the manager cancels in-flight reconciles, so drain first
+	mgrCtx, stopManager := context.WithCancel(context.Background())
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-entryReconciler.DrainRequested():
+		}
+		drainCtx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+		defer cancel()
+		if err := entryReconciler.Drain(ctrl.LoggerInto(drainCtx, setupLog)); err != nil {
+			setupLog.Error(err, "drain did not complete")
+		}
+		stopManager()
+	}()
+
 	setupLog.Info("starting manager")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	if err := mgr.Start(mgrCtx); err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
 }
+
+// defaultRedisAddress derives the Redis address from the environment set up by
+// the Helm chart.
+func defaultRedisAddress() string {
+	if url := os.Getenv("REDIS_URL"); url != "" {
+		return url
+	}
+	if host := os.Getenv("REDIS_HOST"); host != "" {
+		port := os.Getenv("REDIS_PORT")
+		if port == "" {
+			port = "6379"
+		}
+		return host + ":" + port
+	}
+	return controller.DefaultRedisAddress
+}
+
+// splitList parses a comma-separated flag value, dropping empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package config loads the controller's reloadable configuration file and
+// applies changes to it while the controller is running.
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+)
+
+// defaultInterval is how often the file is checked for changes when no
+// interval is configured. Mounted ConfigMaps are updated by the kubelet
+// within about a minute, so a short poll adds little delay.
+const defaultInterval = 10 * time.Second
+
+// Config holds the settings that can change without restarting the
+// controller. Unset fields keep the value given on the command line.
+type Config struct {
+	// MaxRedisWritesPerSecond overrides --max-redis-writes-per-second
+	MaxRedisWritesPerSecond *float64 `json:"maxRedisWritesPerSecond,omitempty"`
+
+	// MaxStatusUpdatesPerSecond overrides --max-status-updates-per-second
+	MaxStatusUpdatesPerSecond *float64 `json:"maxStatusUpdatesPerSecond,omitempty"`
+
+	// FeatureGates sets feature gates like --feature-gates
+	FeatureGates map[string]bool `json:"featureGates,omitempty"`
+}
+
+// Parse decodes a configuration file, rejecting unknown fields so typos
+// don't go unnoticed.
+func Parse(data []byte) (*Config, error) {
+	cfg := &Config{}
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+// SetRate updates a limiter to allow perSecond events per second, with a
+// burst of one second's worth. Zero or less removes the limit.
+func SetRate(limiter *rate.Limiter, perSecond float64) {
+	if perSecond <= 0 {
+		limiter.SetLimit(rate.Inf)
+		return
+	}
+	limiter.SetLimit(rate.Limit(perSecond))
+	limiter.SetBurst(max(1, int(perSecond)))
+}
+
+// Watcher polls a configuration file and applies it whenever its content
+// changes. The hash of the active configuration is exported as a metric.
+type Watcher struct {
+	path     string
+	interval time.Duration
+	apply    func(*Config) error
+
+	// data is the content of the active configuration; only accessed from
+	// Load, which is not called concurrently
+	data []byte
+	hash *prometheus.GaugeVec
+}
+
+// NewWatcher creates a watcher for the file at path that passes each new
+// configuration to apply.
+func NewWatcher(path string, interval time.Duration, apply func(*Config) error) *Watcher {
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+	return &Watcher{
+		path:     path,
+		interval: interval,
+		apply:    apply,
+		hash: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "redisctrl",
+			Name:      "config_info",
+			Help:      "Hash of the active configuration file; the value is always 1.",
+		}, []string{"hash"}),
+	}
+}
+
+// Register adds the configuration metric to the given registry.
+func (w *Watcher) Register(registry prometheus.Registerer) error {
+	return registry.Register(w.hash)
+}
+
+// Load reads the file and applies it if it changed since the last load. It
+// reports whether a new configuration was applied. A file that fails to
+// parse or apply leaves the previous configuration active.
+func (w *Watcher) Load() (bool, error) {
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read configuration: %w", err)
+	}
+	if w.data != nil && bytes.Equal(data, w.data) {
+		return false, nil
+	}
+
+	cfg, err := Parse(data)
+	if err != nil {
+		return false, err
+	}
+	if err := w.apply(cfg); err != nil {
+		return false, fmt.Errorf("failed to apply configuration: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	w.data = data
+	w.hash.Reset()
+	w.hash.WithLabelValues(hex.EncodeToString(sum[:8])).Set(1)
+	return true, nil
+}
+
+// Start polls the file until the context is cancelled.
+func (w *Watcher) Start(ctx context.Context) error {
+	log := log.FromContext(ctx).WithName("config")
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			changed, err := w.Load()
+			if err != nil {
+				log.Error(err, "Failed to reload configuration, keeping the previous one", "path", w.path)
+			} else if changed {
+				log.Info("Applied new configuration", "path", w.path)
+			}
+		}
+	}
+}
+
+// NeedLeaderElection makes every replica apply configuration changes, so a
+// standby is configured correctly when it takes over.
+func (w *Watcher) NeedLeaderElection() bool {
+	return false
+}
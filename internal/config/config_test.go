@@ -0,0 +1,86 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+)
+
+var _ = ginkgo.Describe("Configuration Reload", func() {
+	var (
+		path    string
+		applied []*Config
+		watcher *Watcher
+	)
+
+	write := func(content string) {
+		gomega.Expect(os.WriteFile(path, []byte(content), 0o600)).To(gomega.Succeed())
+	}
+
+	// activeHash returns the hash label of the configuration metric
+	activeHash := func(registry *prometheus.Registry) string {
+		families, err := registry.Gather()
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(families).To(gomega.HaveLen(1))
+		gomega.Expect(families[0].GetMetric()).To(gomega.HaveLen(1))
+		return families[0].GetMetric()[0].GetLabel()[0].GetValue()
+	}
+
+	ginkgo.BeforeEach(func() {
+		path = filepath.Join(ginkgo.GinkgoT().TempDir(), "config.yaml")
+		applied = nil
+		watcher = NewWatcher(path, 0, func(cfg *Config) error {
+			applied = append(applied, cfg)
+			return nil
+		})
+	})
+
+	ginkgo.It("should apply the file only when it changes", func() {
+		registry := prometheus.NewRegistry()
+		gomega.Expect(watcher.Register(registry)).To(gomega.Succeed())
+
+		write("maxRedisWritesPerSecond: 50\nfeatureGates:\n  DriftDetection: false\n")
+		changed, err := watcher.Load()
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(changed).To(gomega.BeTrue())
+		gomega.Expect(*applied[0].MaxRedisWritesPerSecond).To(gomega.Equal(50.0))
+		gomega.Expect(applied[0].FeatureGates).To(gomega.HaveKeyWithValue("DriftDetection", false))
+		first := activeHash(registry)
+
+		changed, err = watcher.Load()
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(changed).To(gomega.BeFalse())
+
+		write("maxRedisWritesPerSecond: 100\n")
+		changed, err = watcher.Load()
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(changed).To(gomega.BeTrue())
+		gomega.Expect(applied).To(gomega.HaveLen(2))
+		gomega.Expect(activeHash(registry)).NotTo(gomega.Equal(first))
+	})
+
+	ginkgo.It("should keep the previous configuration when the file is invalid", func() {
+		write("maxRedisWritesPerSecond: 50\n")
+		_, err := watcher.Load()
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		write("maxRedisWritesPerSecnd: 100\n")
+		_, err = watcher.Load()
+		gomega.Expect(err).To(gomega.HaveOccurred())
+		gomega.Expect(applied).To(gomega.HaveLen(1))
+	})
+
+	ginkgo.It("should update and remove rate limits", func() {
+		limiter := rate.NewLimiter(rate.Inf, 1)
+		SetRate(limiter, 20)
+		gomega.Expect(limiter.Limit()).To(gomega.Equal(rate.Limit(20)))
+		gomega.Expect(limiter.Burst()).To(gomega.Equal(20))
+
+		SetRate(limiter, 0)
+		gomega.Expect(limiter.Limit()).To(gomega.Equal(rate.Inf))
+	})
+})
@@ -0,0 +1,123 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// Reasons an entry is waiting to be reconciled
+const (
+	// backlogSpec is a created, changed or deleted entry
+	backlogSpec = "spec"
+	// backlogDrift is a resync after Redis recovered, lost its data or a
+	// resync was requested, when keys may differ from their entries
+	backlogDrift = "drift"
+	// backlogRetry is a failed sync waiting to be retried
+	backlogRetry = "retry"
+	// backlogOther covers status writes and changes to credentials or
+	// transactions
+	backlogOther = "other"
+)
+
+// backlogItem is an entry waiting to be reconciled.
+type backlogItem struct {
+	reason     string
+	connection string
+}
+
+// enqueued records that an entry was queued for the given reason. An entry
+// is queued at most once, so it keeps the reason it was first queued for
+// until it is reconciled. It is counted under the connection last seen for
+// the entry.
+func (m *Metrics) enqueued(name types.NamespacedName, reason string) {
+	if m == nil {
+		return
+	}
+	m.backlogMu.Lock()
+	defer m.backlogMu.Unlock()
+	if _, queued := m.queued[name]; queued {
+		return
+	}
+	connection, ok := m.connections[name]
+	if !ok {
+		connection = m.connection()
+	}
+	m.queued[name] = backlogItem{reason: reason, connection: connection}
+	m.backlog.WithLabelValues(connection, reason).Inc()
+}
+
+// dequeued records that a reconcile of an entry started.
+func (m *Metrics) dequeued(name types.NamespacedName) {
+	if m == nil {
+		return
+	}
+	m.backlogMu.Lock()
+	defer m.backlogMu.Unlock()
+	if item, queued := m.queued[name]; queued {
+		delete(m.queued, name)
+		m.backlog.WithLabelValues(item.connection, item.reason).Dec()
+	}
+}
+
+// seen remembers the connection label of an entry for the backlog.
+func (m *Metrics) seen(redisEntry *redisv1alpha1.RedisEntry) {
+	if m == nil {
+		return
+	}
+	m.backlogMu.Lock()
+	defer m.backlogMu.Unlock()
+	m.connections[client.ObjectKeyFromObject(redisEntry)] = m.entryConnection(redisEntry)
+}
+
+// forgetEntry drops what was remembered about a deleted entry.
+func (m *Metrics) forgetEntry(name types.NamespacedName) {
+	if m == nil {
+		return
+	}
+	m.backlogMu.Lock()
+	delete(m.connections, name)
+	m.backlogMu.Unlock()
+	m.forgetTTL(name)
+}
+
+// backlogPredicate records why watch events on RedisEntries queue them. It
+// filters nothing, so it must come after any filtering predicate.
+func (m *Metrics) backlogPredicate() predicate.Predicate {
+	record := func(obj client.Object, reason string) bool {
+		if redisEntry, ok := obj.(*redisv1alpha1.RedisEntry); ok {
+			m.seen(redisEntry)
+		}
+		m.enqueued(client.ObjectKeyFromObject(obj), reason)
+		return true
+	}
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool { return record(e.Object, backlogSpec) },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration() {
+				return record(e.ObjectNew, backlogSpec)
+			}
+			return record(e.ObjectNew, backlogOther)
+		},
+		DeleteFunc:  func(e event.DeleteEvent) bool { return record(e.Object, backlogSpec) },
+		GenericFunc: func(e event.GenericEvent) bool { return record(e.Object, backlogOther) },
+	}
+}
@@ -0,0 +1,251 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// defaultBackpressureRecoveryInterval is used when no interval is configured
+	defaultBackpressureRecoveryInterval = 30 * time.Second
+
+	// minBackpressureLevel bounds how far the controller slows down
+	minBackpressureLevel = 1.0 / 32
+
+	// backpressureRecoveryStep is the share of full speed regained per quiet interval
+	backpressureRecoveryStep = 1.0 / 8
+
+	// throttleCooldown folds a burst of throttled requests into one slowdown
+	throttleCooldown = 5 * time.Second
+
+	// clientThrottleThreshold is how long a request may wait on the client-side
+	// rate limiter before it counts as throttled; client-go logs waits above
+	// the same threshold
+	clientThrottleThreshold = time.Second
+
+	// Throttling sources
+	throttleServer = "server"
+	throttleClient = "client"
+)
+
+// BackpressureOptions configures a Backpressure.
+type BackpressureOptions struct {
+	// MaxConcurrency is the number of reconciles allowed at full speed.
+	MaxConcurrency int
+
+	// StatusWritesPerSecond is the status write rate scaled down while
+	// throttled, typically the client's QPS. At full speed status writes are
+	// not limited further.
+	StatusWritesPerSecond float64
+
+	// RecoveryInterval is how long the API server must go without throttling
+	// the controller before each step back up.
+	RecoveryInterval time.Duration
+}
+
+// Backpressure slows the controller down while the API server is overloaded.
+// Each 429 Too Many Requests response, or request held up by the client-side
+// rate limiter, halves the share of reconcile concurrency and status writes
+// the controller allows itself; every quiet RecoveryInterval raises it again
+// by an eighth. A nil *Backpressure never slows anything down.
+type Backpressure struct {
+	opts BackpressureOptions
+
+	mu sync.Mutex
+	// level is the share of full speed, between minBackpressureLevel and 1
+	level float64
+	// lastChange is when level last decreased or recovered a step
+	lastChange time.Time
+	// lastThrottle is when level last decreased
+	lastThrottle time.Time
+	inFlight     int
+	// released is closed and replaced whenever a reconcile finishes
+	released chan struct{}
+
+	statusLimiter *rate.Limiter
+
+	levelGauge prometheus.Gauge
+	throttled  *prometheus.CounterVec
+
+	now func() time.Time
+}
+
+// NewBackpressure creates a Backpressure running at full speed.
+func NewBackpressure(opts BackpressureOptions) *Backpressure {
+	if opts.MaxConcurrency <= 0 {
+		opts.MaxConcurrency = 1
+	}
+	if opts.RecoveryInterval <= 0 {
+		opts.RecoveryInterval = defaultBackpressureRecoveryInterval
+	}
+	b := &Backpressure{
+		opts:          opts,
+		level:         1,
+		released:      make(chan struct{}),
+		statusLimiter: rate.NewLimiter(rate.Inf, 1),
+		now:           time.Now,
+	}
+	b.levelGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "api_backpressure_level",
+		Help:      "Share of reconcile concurrency and status write rate in use, lowered while the API server throttles.",
+	})
+	b.levelGauge.Set(1)
+	b.throttled = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "api_throttled_requests_total",
+		Help:      "Total number of API server requests throttled by the server (429) or the client-side rate limiter.",
+	}, []string{"source"})
+	return b
+}
+
+// Register adds the collectors to the given registry.
+func (b *Backpressure) Register(registry prometheus.Registerer) error {
+	for _, c := range []prometheus.Collector{b.levelGauge, b.throttled} {
+		if err := registry.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WrapTransport returns a round tripper that reports 429 responses. It is
+// meant for rest.Config.Wrap.
+func (b *Backpressure) WrapTransport(rt http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		resp, err := rt.RoundTrip(req)
+		if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+			b.throttle(throttleServer)
+		}
+		return resp, err
+	})
+}
+
+// Observe receives how long requests waited on the client-side rate limiter,
+// as client-go's RateLimiterLatency metric.
+func (b *Backpressure) Observe(_ context.Context, _ string, _ url.URL, latency time.Duration) {
+	if latency >= clientThrottleThreshold {
+		b.throttle(throttleClient)
+	}
+}
+
+// throttle records a throttled request and slows down, at most once per
+// throttleCooldown.
+func (b *Backpressure) throttle(source string) {
+	b.throttled.WithLabelValues(source).Inc()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.now()
+	if now.Sub(b.lastThrottle) < throttleCooldown {
+		return
+	}
+	b.lastThrottle, b.lastChange = now, now
+	b.setLevel(b.level / 2)
+}
+
+// regain raises the level by one step for every quiet RecoveryInterval since
+// the last change. It must be called with mu held.
+func (b *Backpressure) regain() {
+	if b.level >= 1 {
+		return
+	}
+	steps := b.now().Sub(b.lastChange) / b.opts.RecoveryInterval
+	if steps <= 0 {
+		return
+	}
+	b.lastChange = b.lastChange.Add(steps * b.opts.RecoveryInterval)
+	b.setLevel(b.level + float64(steps)*backpressureRecoveryStep)
+}
+
+// setLevel clamps and applies a new level. It must be called with mu held.
+func (b *Backpressure) setLevel(level float64) {
+	b.level = math.Min(1, math.Max(minBackpressureLevel, level))
+	b.levelGauge.Set(b.level)
+	if b.level >= 1 || b.opts.StatusWritesPerSecond <= 0 {
+		b.statusLimiter.SetLimit(rate.Inf)
+		return
+	}
+	b.statusLimiter.SetLimit(rate.Limit(b.opts.StatusWritesPerSecond * b.level))
+}
+
+// concurrency returns the number of reconciles currently allowed. It must be
+// called with mu held.
+func (b *Backpressure) concurrency() int {
+	return max(1, int(float64(b.opts.MaxConcurrency)*b.level))
+}
+
+// acquire blocks until another reconcile may run and returns the function
+// that ends it.
+func (b *Backpressure) acquire(ctx context.Context) (func(), error) {
+	if b == nil {
+		return func() {}, nil
+	}
+	for {
+		b.mu.Lock()
+		b.regain()
+		if b.inFlight < b.concurrency() {
+			b.inFlight++
+			b.mu.Unlock()
+			return b.release, nil
+		}
+		released := b.released
+		b.mu.Unlock()
+
+		select {
+		case <-released:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// release ends a reconcile started by acquire.
+func (b *Backpressure) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.inFlight--
+	close(b.released)
+	b.released = make(chan struct{})
+}
+
+// waitStatus blocks until a status write fits the current level.
+func (b *Backpressure) waitStatus(ctx context.Context) error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	b.regain()
+	b.mu.Unlock()
+	return b.statusLimiter.Wait(ctx)
+}
+
+// roundTripperFunc adapts a function to http.RoundTripper.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
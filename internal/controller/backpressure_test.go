@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"golang.org/x/time/rate"
+)
+
+var _ = ginkgo.Describe("API Backpressure", func() {
+	var (
+		b   *Backpressure
+		now time.Time
+	)
+
+	ginkgo.BeforeEach(func() {
+		now = time.Now()
+		b = NewBackpressure(BackpressureOptions{
+			MaxConcurrency:        8,
+			StatusWritesPerSecond: 20,
+			RecoveryInterval:      time.Minute,
+		})
+		b.now = func() time.Time { return now }
+	})
+
+	ginkgo.It("should halve once per burst of throttled requests", func() {
+		b.throttle(throttleServer)
+		b.throttle(throttleServer)
+		gomega.Expect(b.level).To(gomega.Equal(0.5))
+		gomega.Expect(b.concurrency()).To(gomega.Equal(4))
+		gomega.Expect(b.statusLimiter.Limit()).To(gomega.Equal(rate.Limit(10)))
+		gomega.Expect(testutil.ToFloat64(b.throttled.WithLabelValues(throttleServer))).To(gomega.Equal(2.0))
+
+		now = now.Add(throttleCooldown)
+		b.throttle(throttleClient)
+		gomega.Expect(b.level).To(gomega.Equal(0.25))
+		gomega.Expect(testutil.ToFloat64(b.levelGauge)).To(gomega.Equal(0.25))
+	})
+
+	ginkgo.It("should recover a step per quiet interval", func() {
+		b.throttle(throttleServer)
+
+		now = now.Add(2*time.Minute + time.Second)
+		b.mu.Lock()
+		b.regain()
+		b.mu.Unlock()
+		gomega.Expect(b.level).To(gomega.Equal(0.75))
+
+		now = now.Add(10 * time.Minute)
+		gomega.Expect(b.waitStatus(context.Background())).To(gomega.Succeed())
+		gomega.Expect(b.level).To(gomega.Equal(1.0))
+		gomega.Expect(b.statusLimiter.Limit()).To(gomega.Equal(rate.Inf))
+	})
+
+	ginkgo.It("should hold reconciles beyond the current concurrency", func() {
+		b.opts.MaxConcurrency = 2
+		b.throttle(throttleServer)
+
+		release, err := b.acquire(context.Background())
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		acquired := make(chan struct{})
+		go func() {
+			defer ginkgo.GinkgoRecover()
+			second, err := b.acquire(context.Background())
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			second()
+			close(acquired)
+		}()
+		gomega.Consistently(acquired, 100*time.Millisecond).ShouldNot(gomega.BeClosed())
+
+		release()
+		gomega.Eventually(acquired).Should(gomega.BeClosed())
+	})
+
+	ginkgo.It("should give up waiting when the context ends", func() {
+		release, err := b.acquire(context.Background())
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		defer release()
+		b.opts.MaxConcurrency = 1
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = b.acquire(ctx)
+		gomega.Expect(err).To(gomega.MatchError(context.Canceled))
+	})
+
+	ginkgo.It("should detect 429 responses and long client-side waits", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+
+		httpClient := &http.Client{Transport: b.WrapTransport(http.DefaultTransport)}
+		resp, err := httpClient.Get(server.URL)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(resp.Body.Close()).To(gomega.Succeed())
+		gomega.Expect(testutil.ToFloat64(b.throttled.WithLabelValues(throttleServer))).To(gomega.Equal(1.0))
+
+		b.Observe(context.Background(), http.MethodGet, *resp.Request.URL, time.Millisecond)
+		gomega.Expect(testutil.ToFloat64(b.throttled.WithLabelValues(throttleClient))).To(gomega.Equal(0.0))
+		b.Observe(context.Background(), http.MethodGet, *resp.Request.URL, 2*time.Second)
+		gomega.Expect(testutil.ToFloat64(b.throttled.WithLabelValues(throttleClient))).To(gomega.Equal(1.0))
+	})
+
+	ginkgo.It("should tolerate a nil backpressure", func() {
+		var nilBackpressure *Backpressure
+		release, err := nilBackpressure.acquire(context.Background())
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		release()
+		gomega.Expect(nilBackpressure.waitStatus(context.Background())).To(gomega.Succeed())
+	})
+})
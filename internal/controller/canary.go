@@ -0,0 +1,58 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+// Rollout tracks, splitting entries between two controller deployments while
+// a new version is rolled out
+const (
+	// TrackCanary manages only entries labeled with CanaryLabel
+	TrackCanary = "canary"
+	// TrackStable manages every entry except the canaries
+	TrackStable = "stable"
+)
+
+// TrackSelector narrows selector, which may be nil, to the entries of a
+// rollout track. An empty track leaves it unchanged.
+func TrackSelector(track string, selector labels.Selector) (labels.Selector, error) {
+	var op selection.Operator
+	switch track {
+	case "":
+		return selector, nil
+	case TrackCanary:
+		op = selection.Equals
+	case TrackStable:
+		op = selection.NotEquals
+	default:
+		return nil, fmt.Errorf("unknown rollout track %q, expected %s or %s", track, TrackCanary, TrackStable)
+	}
+	req, err := labels.NewRequirement(redisv1alpha1.CanaryLabel, op, []string{"true"})
+	if err != nil {
+		return nil, err
+	}
+	if selector == nil {
+		selector = labels.NewSelector()
+	}
+	return selector.Add(*req), nil
+}
@@ -0,0 +1,45 @@
+package controller
+
+import (
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+var _ = ginkgo.Describe("Rollout Tracks", func() {
+	canary := labels.Set{redisv1alpha1.CanaryLabel: "true", "team": "a"}
+	plain := labels.Set{"team": "a"}
+
+	ginkgo.It("should split entries between the canary and stable tracks", func() {
+		selector, err := TrackSelector(TrackCanary, nil)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(selector.Matches(canary)).To(gomega.BeTrue())
+		gomega.Expect(selector.Matches(plain)).To(gomega.BeFalse())
+
+		selector, err = TrackSelector(TrackStable, nil)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(selector.Matches(canary)).To(gomega.BeFalse())
+		gomega.Expect(selector.Matches(plain)).To(gomega.BeTrue())
+	})
+
+	ginkgo.It("should combine the track with the entry selector", func() {
+		base, err := labels.Parse("team=b")
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		selector, err := TrackSelector(TrackCanary, base)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(selector.Matches(canary)).To(gomega.BeFalse())
+		gomega.Expect(selector.Matches(labels.Set{redisv1alpha1.CanaryLabel: "true", "team": "b"})).To(gomega.BeTrue())
+	})
+
+	ginkgo.It("should leave the selector alone without a track", func() {
+		selector, err := TrackSelector("", nil)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(selector).To(gomega.BeNil())
+	})
+
+	ginkgo.It("should reject unknown tracks", func() {
+		_, err := TrackSelector("beta", nil)
+		gomega.Expect(err).To(gomega.HaveOccurred())
+	})
+})
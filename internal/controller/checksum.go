@@ -0,0 +1,41 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+)
+
+// checksumSuffix is appended to the main key to name its checksum key.
+const checksumSuffix = ":sha256"
+
+// checksumKey returns the name of the entry's checksum key, if it keeps one.
+func checksumKey(redisEntry *redisv1alpha1.RedisEntry) (string, bool) {
+	if redisEntry.Spec.Checksum != redisv1alpha1.ChecksumSHA256 {
+		return "", false
+	}
+	return redisEntry.Spec.Key + checksumSuffix, true
+}
+
+// valueChecksum returns the hex-encoded SHA-256 of a value.
+func valueChecksum(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
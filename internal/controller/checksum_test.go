@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	redismock "github.com/go-redis/redismock/v9"
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	redisv9 "github.com/redis/go-redis/v9"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = ginkgo.Describe("Value Checksums", func() {
+	const value = "large-value"
+
+	var (
+		ctx        context.Context
+		mock       redismock.ClientMock
+		mockRedis  *redisv9.Client
+		reconciler *RedisEntryReconciler
+		entry      *redisv1alpha1.RedisEntry
+	)
+
+	ginkgo.BeforeEach(func() {
+		ctx = context.Background()
+		mockRedis, mock = redismock.NewClientMock()
+		reconciler = &RedisEntryReconciler{RedisClient: mockRedis}
+		entry = &redisv1alpha1.RedisEntry{
+			ObjectMeta: metav1.ObjectMeta{Name: "checksummed", Namespace: "default", Generation: 1},
+			Spec: redisv1alpha1.RedisEntrySpec{
+				Key:      "blob",
+				Value:    value,
+				Checksum: redisv1alpha1.ChecksumSHA256,
+			},
+		}
+	})
+
+	ginkgo.AfterEach(func() {
+		gomega.Expect(mock.ExpectationsWereMet()).To(gomega.Succeed())
+	})
+
+	ginkgo.It("should write the checksum key in the same transaction", func() {
+		gomega.Expect(valueChecksum(value)).To(gomega.HaveLen(64))
+		mock.ExpectTxPipeline()
+		mock.ExpectSet("blob", value, time.Minute).SetVal("OK")
+		mock.ExpectMSet("blob:sha256", valueChecksum(value)).SetVal("OK")
+		mock.ExpectExpire("blob:sha256", time.Minute).SetVal(true)
+		mock.ExpectTxPipelineExec()
+
+		gomega.Expect(reconciler.writeEntry(ctx, reconciler.store(mockRedis, nil), entry, value, time.Minute)).To(gomega.Succeed())
+		gomega.Expect(entry.Status.WriteMode).To(gomega.Equal(redisv1alpha1.WriteModeTransaction))
+	})
+
+	ginkgo.It("should detect drift through the checksum and length", func() {
+		entry.Status.Conditions = []metav1.Condition{{
+			Type: typeAvailable, Status: metav1.ConditionTrue, ObservedGeneration: 1,
+		}}
+
+		mock.ExpectStrLen("blob").SetVal(int64(len(value)))
+		mock.ExpectMGet("blob:sha256").SetVal([]interface{}{valueChecksum(value)})
+		drifted, err := reconciler.detectDrift(ctx, reconciler.store(mockRedis, nil), entry, value)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(drifted).To(gomega.BeNil())
+
+		mock.ExpectStrLen("blob").SetVal(int64(len(value)))
+		// Same length, different content
+		mock.ExpectMGet("blob:sha256").SetVal([]interface{}{valueChecksum("other-value")})
+		drifted, err = reconciler.detectDrift(ctx, reconciler.store(mockRedis, nil), entry, value)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(drifted.key).To(gomega.Equal("blob:sha256"))
+
+		mock.ExpectStrLen("blob").SetVal(0)
+		drifted, err = reconciler.detectDrift(ctx, reconciler.store(mockRedis, nil), entry, value)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(drifted.key).To(gomega.Equal("blob"))
+	})
+
+	ginkgo.It("should refuse entries declaring the checksum key", func() {
+		entry.Spec.Entries = map[string]string{"blob:sha256": "x"}
+		gomega.Expect(reconciler.invalidKey(entry)).To(gomega.MatchError(gomega.ContainSubstring("checksum")))
+	})
+})
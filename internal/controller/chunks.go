@@ -0,0 +1,97 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+)
+
+// chunkKeyInfix separates the main key from the chunk number in chunk keys.
+const chunkKeyInfix = ":chunk:"
+
+// chunkManifest is stored in the main key of a chunked value.
+type chunkManifest struct {
+	Chunks int    `json:"chunks"`
+	Bytes  int    `json:"bytes"`
+	SHA256 string `json:"sha256"`
+}
+
+// chunkKey returns the name of the i-th chunk key of a main key.
+func chunkKey(key string, i int) string {
+	return key + chunkKeyInfix + strconv.Itoa(i)
+}
+
+// chunkValue splits a value larger than spec.chunkSizeBytes and returns the
+// manifest to store in the main key along with the chunks. It reports false
+// when the value is stored whole.
+func chunkValue(redisEntry *redisv1alpha1.RedisEntry, value string) (string, []string, bool) {
+	size := redisEntry.Spec.ChunkSizeBytes
+	if size == nil || int64(len(value)) <= *size {
+		return "", nil, false
+	}
+
+	var chunks []string
+	for rest := value; len(rest) > 0; {
+		n := min(int64(len(rest)), *size)
+		chunks = append(chunks, rest[:n])
+		rest = rest[n:]
+	}
+	manifest, _ := json.Marshal(chunkManifest{
+		Chunks: len(chunks),
+		Bytes:  len(value),
+		SHA256: valueChecksum(value),
+	})
+	return string(manifest), chunks, true
+}
+
+// staleChunks returns the chunk keys of the last chunked write that a write
+// of the given number of chunks leaves behind. The count is taken from the
+// manifest in Redis as well as the status, so chunks are found even when the
+// status of an earlier write was lost, and the keys are derived from the key
+// they were written under rather than the current spec.key.
+func staleChunks(ctx context.Context, store KVStore, redisEntry *redisv1alpha1.RedisEntry, chunks int) ([]string, error) {
+	key, count := redisEntry.Spec.Key, int(redisEntry.Status.Chunks)
+	if redisEntry.Status.ChunkedKey != "" {
+		key = redisEntry.Status.ChunkedKey
+	}
+	if redisEntry.Spec.ChunkSizeBytes == nil && count == 0 {
+		return nil, nil
+	}
+	values, err := store.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	var manifest chunkManifest
+	if values[0] != nil && json.Unmarshal([]byte(*values[0]), &manifest) == nil && manifest.SHA256 != "" {
+		count = max(count, manifest.Chunks)
+	}
+
+	// Chunks under the current key are overwritten up to the new count
+	first := 0
+	if key == redisEntry.Spec.Key {
+		first = chunks
+	}
+	var stale []string
+	for i := first; i < count; i++ {
+		stale = append(stale, chunkKey(key, i))
+	}
+	return stale, nil
+}
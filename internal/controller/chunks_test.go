@@ -0,0 +1,121 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	redismock "github.com/go-redis/redismock/v9"
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	redisv9 "github.com/redis/go-redis/v9"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+var _ = ginkgo.Describe("Chunked Values", func() {
+	var (
+		ctx        context.Context
+		mock       redismock.ClientMock
+		mockRedis  *redisv9.Client
+		reconciler *RedisEntryReconciler
+		entry      *redisv1alpha1.RedisEntry
+		value      string
+	)
+
+	ginkgo.BeforeEach(func() {
+		ctx = context.Background()
+		mockRedis, mock = redismock.NewClientMock()
+		reconciler = &RedisEntryReconciler{RedisClient: mockRedis}
+		value = strings.Repeat("a", 1024) + strings.Repeat("b", 1024) + "c"
+		entry = &redisv1alpha1.RedisEntry{
+			ObjectMeta: metav1.ObjectMeta{Name: "chunked", Namespace: "default", Generation: 1},
+			Spec: redisv1alpha1.RedisEntrySpec{
+				Key:            "blob",
+				Value:          value,
+				ChunkSizeBytes: ptr.To[int64](1024),
+			},
+		}
+	})
+
+	ginkgo.AfterEach(func() {
+		gomega.Expect(mock.ExpectationsWereMet()).To(gomega.Succeed())
+	})
+
+	ginkgo.It("should store small values whole", func() {
+		_, _, ok := chunkValue(entry, "small")
+		gomega.Expect(ok).To(gomega.BeFalse())
+	})
+
+	ginkgo.It("should split large values behind a manifest", func() {
+		manifest, chunks, ok := chunkValue(entry, value)
+		gomega.Expect(ok).To(gomega.BeTrue())
+		gomega.Expect(chunks).To(gomega.HaveLen(3))
+		gomega.Expect(strings.Join(chunks, "")).To(gomega.Equal(value))
+
+		var m chunkManifest
+		gomega.Expect(json.Unmarshal([]byte(manifest), &m)).To(gomega.Succeed())
+		gomega.Expect(m).To(gomega.Equal(chunkManifest{Chunks: 3, Bytes: len(value), SHA256: valueChecksum(value)}))
+	})
+
+	ginkgo.It("should write the chunks and drop stale ones", func() {
+		manifest, chunks, _ := chunkValue(entry, value)
+		entry.Status.Chunks = 4
+
+		mock.ExpectMGet("blob").SetVal([]interface{}{nil})
+		mock.ExpectTxPipeline()
+		mock.ExpectSet("blob", manifest, time.Minute).SetVal("OK")
+		for i, chunk := range chunks {
+			mock.ExpectSet(chunkKey("blob", i), chunk, time.Minute).SetVal("OK")
+		}
+		mock.ExpectDel("blob:chunk:3").SetVal(1)
+		mock.ExpectTxPipelineExec()
+
+		gomega.Expect(reconciler.writeEntry(ctx, reconciler.store(mockRedis, nil), entry, value, time.Minute)).To(gomega.Succeed())
+		gomega.Expect(entry.Status.Chunks).To(gomega.BeEquivalentTo(3))
+		gomega.Expect(entryKeys(entry)).To(gomega.Equal([]string{"blob", "blob:chunk:0", "blob:chunk:1", "blob:chunk:2"}))
+	})
+
+	ginkgo.It("should find stale chunks through the manifest in Redis", func() {
+		server, redisClient := newMiniRedis()
+		store := reconciler.store(redisClient, nil)
+		gomega.Expect(reconciler.writeEntry(ctx, store, entry, value, 0)).To(gomega.Succeed())
+		gomega.Expect(entry.Status.ChunkedKey).To(gomega.Equal("blob"))
+
+		// The status recording the chunks was lost
+		entry.Status.Chunks, entry.Status.ChunkedKey = 0, ""
+		gomega.Expect(reconciler.writeEntry(ctx, store, entry, strings.Repeat("a", 1500), 0)).To(gomega.Succeed())
+		gomega.Expect(server.Exists("blob:chunk:1")).To(gomega.BeTrue())
+		gomega.Expect(server.Exists("blob:chunk:2")).To(gomega.BeFalse())
+
+		// Renaming the key drops the chunks under the old one
+		entry.Spec.Key = "blob2"
+		gomega.Expect(reconciler.writeEntry(ctx, store, entry, "small", 0)).To(gomega.Succeed())
+		gomega.Expect(server.Exists("blob:chunk:0")).To(gomega.BeFalse())
+		gomega.Expect(server.Exists("blob:chunk:1")).To(gomega.BeFalse())
+		gomega.Expect(entry.Status.Chunks).To(gomega.BeZero())
+		gomega.Expect(entry.Status.ChunkedKey).To(gomega.BeEmpty())
+	})
+
+	ginkgo.It("should report drift in a chunk", func() {
+		manifest, chunks, _ := chunkValue(entry, value)
+		entry.Status.Chunks = 3
+		entry.Status.Conditions = []metav1.Condition{{
+			Type: typeAvailable, Status: metav1.ConditionTrue, ObservedGeneration: 1,
+		}}
+		mock.ExpectMGet("blob", "blob:chunk:0", "blob:chunk:1", "blob:chunk:2").
+			SetVal([]interface{}{manifest, chunks[0], "tampered", chunks[2]})
+
+		d, err := reconciler.detectDrift(ctx, reconciler.store(mockRedis, nil), entry, value)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(d).NotTo(gomega.BeNil())
+		gomega.Expect(d.key).To(gomega.Equal("blob:chunk:1"))
+	})
+
+	ginkgo.It("should reject entries that shadow chunk keys", func() {
+		entry.Spec.Entries = map[string]string{"blob:chunk:0": "x"}
+		gomega.Expect(reconciler.invalidKey(entry)).To(gomega.MatchError(gomega.ContainSubstring("reserved for chunks")))
+	})
+})
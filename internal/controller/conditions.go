@@ -0,0 +1,63 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"slices"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	// entryConditionTypes are the condition types the controller sets on a
+	// RedisEntry
+	entryConditionTypes = []string{typeAvailable, typeError, typeCompleted, typeDeleting}
+
+	// auditConditionTypes are the condition types the controller sets on a
+	// RedisAudit
+	auditConditionTypes = []string{typeComplete, typeError}
+
+	// batchConditionTypes are the condition types the controller sets on a
+	// RedisEntryBatch
+	batchConditionTypes = []string{typeAvailable}
+
+	// templateConditionTypes are the condition types the controller sets on
+	// a RedisEntryTemplate
+	templateConditionTypes = []string{typeAvailable, typeError}
+
+	// transactionConditionTypes are the condition types the controller sets
+	// on a RedisTransaction
+	transactionConditionTypes = []string{typeAvailable, typeError}
+
+	// connectionConditionTypes are the condition types the controller sets on
+	// a RedisConnection
+	connectionConditionTypes = []string{typeAvailable, typeError}
+)
+
+// pruneConditions removes conditions whose type is not in known, such as
+// types set by older versions of the controller, and repeated types, keeping
+// the first. It reports whether anything was removed.
+func pruneConditions(conditions *[]metav1.Condition, known []string) bool {
+	seen := map[string]bool{}
+	before := len(*conditions)
+	*conditions = slices.DeleteFunc(*conditions, func(cond metav1.Condition) bool {
+		stale := !slices.Contains(known, cond.Type) || seen[cond.Type]
+		seen[cond.Type] = true
+		return stale
+	})
+	return len(*conditions) != before
+}
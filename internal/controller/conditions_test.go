@@ -0,0 +1,56 @@
+package controller
+
+import (
+	"time"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+var _ = ginkgo.Describe("Condition Pruning", func() {
+	ginkgo.It("should drop unknown and repeated condition types", func() {
+		conditions := []metav1.Condition{
+			{Type: typeAvailable, Reason: reasonSuccess},
+			{Type: "Ready", Reason: "Legacy"},
+			{Type: typeError, Reason: reasonRedisError},
+			{Type: typeAvailable, Reason: "Duplicate"},
+		}
+		gomega.Expect(pruneConditions(&conditions, entryConditionTypes)).To(gomega.BeTrue())
+		gomega.Expect(conditions).To(gomega.Equal([]metav1.Condition{
+			{Type: typeAvailable, Reason: reasonSuccess},
+			{Type: typeError, Reason: reasonRedisError},
+		}))
+
+		gomega.Expect(pruneConditions(&conditions, entryConditionTypes)).To(gomega.BeFalse())
+	})
+})
+
+var _ = ginkgo.Describe("Condition Transitions", func() {
+	ginkgo.It("should only move LastTransitionTime when the status changes", func() {
+		start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		clock := clocktesting.NewFakePassiveClock(start)
+		r := &RedisEntryReconciler{Clock: clock}
+		entry := &redisv1alpha1.RedisEntry{ObjectMeta: metav1.ObjectMeta{Generation: 1}}
+
+		r.setCondition(entry, typeError, reasonRedisError, "connection refused")
+		clock.SetTime(start.Add(time.Minute))
+		entry.Generation = 2
+		r.setCondition(entry, typeError, reasonAuthFailed, "WRONGPASS invalid username-password pair")
+
+		cond := meta.FindStatusCondition(entry.Status.Conditions, typeError)
+		gomega.Expect(cond.Reason).To(gomega.Equal(reasonAuthFailed))
+		gomega.Expect(cond.Message).To(gomega.HavePrefix("WRONGPASS"))
+		gomega.Expect(cond.ObservedGeneration).To(gomega.Equal(int64(2)))
+		gomega.Expect(cond.LastTransitionTime.Time).To(gomega.Equal(start))
+
+		// A condition that was removed and set again has transitioned
+		meta.RemoveStatusCondition(&entry.Status.Conditions, typeError)
+		r.setCondition(entry, typeError, reasonRedisError, "connection refused")
+		cond = meta.FindStatusCondition(entry.Status.Conditions, typeError)
+		gomega.Expect(cond.LastTransitionTime.Time).To(gomega.Equal(start.Add(time.Minute)))
+	})
+})
@@ -0,0 +1,73 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	redisv9 "github.com/redis/go-redis/v9"
+)
+
+// DefaultRedisAddress is the Redis service installed by the bundled Helm chart.
+const DefaultRedisAddress = "redis-redis-service:6379"
+
+// RedisOptionsFromAddress builds client options from either a plain host:port
+// address or a redis://, rediss:// or unix:// URL. For URLs the user,
+// password, database and TLS settings are taken from the URL itself.
+func RedisOptionsFromAddress(address string) (*redisv9.Options, error) {
+	if !strings.Contains(address, "://") {
+		if address == "" {
+			return nil, fmt.Errorf("redis address must not be empty")
+		}
+		return &redisv9.Options{Addr: address}, nil
+	}
+
+	opts, err := redisv9.ParseURL(address)
+	if err != nil {
+		// The URL may carry credentials, so never echo it back
+		return nil, fmt.Errorf("invalid redis URL: %w", err)
+	}
+	return opts, nil
+}
+
+// proxyOptions returns a copy of opts suitable for connecting through a Redis
+// proxy. Proxies such as Twemproxy reject the HELLO and CLIENT SETINFO
+// commands that the client otherwise sends on connect.
+func proxyOptions(opts *redisv9.Options) *redisv9.Options {
+	proxied := *opts
+	proxied.Protocol = 2
+	proxied.DisableIdentity = true
+	return &proxied
+}
+
+// clusterOptions returns the options of a Redis Cluster client whose nodes
+// are reached like opts, with opts.Addr as the seed node.
+func clusterOptions(opts *redisv9.Options) *redisv9.ClusterOptions {
+	return &redisv9.ClusterOptions{
+		Addrs:           []string{opts.Addr},
+		Dialer:          opts.Dialer,
+		Protocol:        opts.Protocol,
+		Username:        opts.Username,
+		Password:        opts.Password,
+		DialTimeout:     opts.DialTimeout,
+		ReadTimeout:     opts.ReadTimeout,
+		WriteTimeout:    opts.WriteTimeout,
+		TLSConfig:       opts.TLSConfig,
+		DisableIdentity: opts.DisableIdentity,
+	}
+}
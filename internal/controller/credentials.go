@@ -0,0 +1,162 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	redisv9 "github.com/redis/go-redis/v9"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// NamespaceCredentialsSecret is the Secret looked up in an entry's
+	// namespace when per-namespace credentials are enabled
+	NamespaceCredentialsSecret = "redis-ctrl-credentials"
+
+	// Keys of the credentials Secret
+	credentialsUsernameKey = "username"
+	credentialsPasswordKey = "password"
+)
+
+// SecretCacheOptions limits the manager's Secret cache to the credentials
+// Secrets, without managed fields, so that memory doesn't grow with the
+// unrelated Secrets in the cluster. Other Secrets are read from the API
+// server through APIReader.
+func SecretCacheOptions() cache.ByObject {
+	return cache.ByObject{
+		Field:     fields.OneTermEqualSelector("metadata.name", NamespaceCredentialsSecret),
+		Transform: cache.TransformStripManagedFields(),
+	}
+}
+
+// namespaceClient is a Redis client authenticated with a namespace's
+// credentials, along with the Secret version it was built from.
+type namespaceClient struct {
+	resourceVersion string
+	client          *redisv9.Client
+}
+
+// namespaceClients caches one Redis client per namespace that provides its
+// own credentials. The zero value is ready to use once base is set.
+type namespaceClients struct {
+	mu      sync.Mutex
+	base    *redisv9.Options
+	clients map[string]namespaceClient
+
+	// onClose, when set, is called with clients replaced after a Secret change
+	onClose func(*redisv9.Client)
+}
+
+// get returns the client for the credentials in secret, replacing the cached
+// client when the Secret has changed.
+func (c *namespaceClients) get(secret *corev1.Secret) (*redisv9.Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cached, ok := c.clients[secret.Namespace]; ok {
+		if cached.resourceVersion == secret.ResourceVersion {
+			return cached.client, nil
+		}
+		if c.onClose != nil {
+			c.onClose(cached.client)
+		}
+		_ = cached.client.Close()
+		delete(c.clients, secret.Namespace)
+	}
+
+	password, ok := secret.Data[credentialsPasswordKey]
+	if !ok {
+		return nil, fmt.Errorf("secret %s/%s has no %q key", secret.Namespace, secret.Name, credentialsPasswordKey)
+	}
+	opts := &redisv9.Options{Addr: DefaultRedisAddress}
+	if c.base != nil {
+		opts = c.base
+	}
+	scoped := *opts
+	scoped.Username = string(secret.Data[credentialsUsernameKey])
+	scoped.Password = string(password)
+
+	redisClient := redisv9.NewClient(&scoped)
+	if c.clients == nil {
+		c.clients = map[string]namespaceClient{}
+	}
+	c.clients[secret.Namespace] = namespaceClient{resourceVersion: secret.ResourceVersion, client: redisClient}
+	return redisClient, nil
+}
+
+// redisClientFor returns the Redis client to use for entries in a namespace:
+// a client with the namespace's own credentials when NamespaceCredentials is
+// enabled and the namespace has a credentials Secret, and the shared client
+// otherwise. The second result reports whether the shared client is used.
+func (r *RedisEntryReconciler) redisClientFor(ctx context.Context, namespace string) (redisv9.UniversalClient, bool, error) {
+	if !r.NamespaceCredentials {
+		return r.RedisClient, true, nil
+	}
+
+	secret := &corev1.Secret{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: NamespaceCredentialsSecret}, secret)
+	if apierrors.IsNotFound(err) {
+		return r.RedisClient, true, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read credentials secret: %w", err)
+	}
+	redisClient, err := r.namespaceClients.get(secret)
+	if err != nil {
+		return nil, false, err
+	}
+	return redisClient, false, nil
+}
+
+// isAuthError reports whether Redis rejected the client's credentials, which
+// won't change by retrying.
+func isAuthError(err error) bool {
+	message := err.Error()
+	return strings.Contains(message, "NOAUTH") || strings.Contains(message, "WRONGPASS")
+}
+
+// entriesForCredentials maps a change to a namespace's credentials Secret to
+// every RedisEntry in that namespace.
+func (r *RedisEntryReconciler) entriesForCredentials(ctx context.Context, obj client.Object) []ctrl.Request {
+	if obj.GetName() != NamespaceCredentialsSecret {
+		return nil
+	}
+	var requests []ctrl.Request
+	err := forEachEntry(ctx, r.Client, r.APIReader, func(entry *redisv1alpha1.RedisEntry) error {
+		if r.managesEntry(entry) {
+			name := client.ObjectKeyFromObject(entry)
+			r.Metrics.enqueued(name, backlogOther)
+			requests = append(requests, ctrl.Request{NamespacedName: name})
+		}
+		return nil
+	}, client.InNamespace(obj.GetNamespace()))
+	if err != nil {
+		return nil
+	}
+	return requests
+}
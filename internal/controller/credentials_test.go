@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"context"
+	"errors"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	redismock "github.com/go-redis/redismock/v9"
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	redisv9 "github.com/redis/go-redis/v9"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = ginkgo.Describe("Namespace Credentials", func() {
+	var (
+		ctx       context.Context
+		mockRedis *redisv9.Client
+		mock      redismock.ClientMock
+		r         *RedisEntryReconciler
+	)
+
+	ginkgo.BeforeEach(func() {
+		ctx = context.Background()
+		s := runtime.NewScheme()
+		gomega.Expect(redisv1alpha1.AddToScheme(s)).To(gomega.Succeed())
+		gomega.Expect(corev1.AddToScheme(s)).To(gomega.Succeed())
+
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: NamespaceCredentialsSecret, Namespace: "tenant-a"},
+			Data: map[string][]byte{
+				"username": []byte("tenant-a"),
+				"password": []byte("s3cret"),
+			},
+		}
+		mockRedis, mock = redismock.NewClientMock()
+		r = &RedisEntryReconciler{
+			Client: fake.NewClientBuilder().WithScheme(s).WithObjects(secret).
+				WithStatusSubresource(&redisv1alpha1.RedisEntry{}).Build(),
+			Scheme:               s,
+			RedisClient:          mockRedis,
+			NamespaceCredentials: true,
+		}
+		r.namespaceClients.base = &redisv9.Options{Addr: "redis.example:6379", DB: 3}
+	})
+
+	ginkgo.AfterEach(func() {
+		for _, cached := range r.namespaceClients.clients {
+			gomega.Expect(cached.client.Close()).To(gomega.Succeed())
+		}
+	})
+
+	ginkgo.It("should authenticate with the namespace's credentials", func() {
+		redisClient, shared, err := r.redisClientFor(ctx, "tenant-a")
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(shared).To(gomega.BeFalse())
+
+		opts := redisClient.(*redisv9.Client).Options()
+		gomega.Expect(opts.Addr).To(gomega.Equal("redis.example:6379"))
+		gomega.Expect(opts.DB).To(gomega.Equal(3))
+		gomega.Expect(opts.Username).To(gomega.Equal("tenant-a"))
+		gomega.Expect(opts.Password).To(gomega.Equal("s3cret"))
+
+		// The client is reused while the Secret is unchanged
+		again, _, err := r.redisClientFor(ctx, "tenant-a")
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(again).To(gomega.BeIdenticalTo(redisClient))
+	})
+
+	ginkgo.It("should fall back to the shared client without a Secret", func() {
+		redisClient, shared, err := r.redisClientFor(ctx, "tenant-b")
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(shared).To(gomega.BeTrue())
+		gomega.Expect(redisClient).To(gomega.BeIdenticalTo(mockRedis))
+	})
+
+	ginkgo.It("should ignore Secrets when the mode is disabled", func() {
+		r.NamespaceCredentials = false
+		redisClient, shared, err := r.redisClientFor(ctx, "tenant-a")
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(shared).To(gomega.BeTrue())
+		gomega.Expect(redisClient).To(gomega.BeIdenticalTo(mockRedis))
+	})
+
+	ginkgo.It("should not retry entries rejected for their credentials", func() {
+		entry := &redisv1alpha1.RedisEntry{
+			ObjectMeta: metav1.ObjectMeta{Name: "locked-out", Namespace: "tenant-b"},
+			Spec:       redisv1alpha1.RedisEntrySpec{Key: "k", Value: "v"},
+		}
+		gomega.Expect(r.Create(ctx, entry)).To(gomega.Succeed())
+		mock.ExpectSet("k", "v", 0).SetErr(errors.New("WRONGPASS invalid username-password pair or user is disabled."))
+
+		result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(entry)})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(result).To(gomega.Equal(ctrl.Result{}))
+
+		gomega.Expect(r.Get(ctx, client.ObjectKeyFromObject(entry), entry)).To(gomega.Succeed())
+		cond := meta.FindStatusCondition(entry.Status.Conditions, typeError)
+		gomega.Expect(cond).NotTo(gomega.BeNil())
+		gomega.Expect(cond.Reason).To(gomega.Equal(reasonAuthFailed))
+		gomega.Expect(mock.ExpectationsWereMet()).To(gomega.Succeed())
+	})
+
+	ginkgo.It("should only cache credentials Secrets", func() {
+		opts := SecretCacheOptions()
+		gomega.Expect(opts.Field.Matches(fields.Set{"metadata.name": NamespaceCredentialsSecret})).To(gomega.BeTrue())
+		gomega.Expect(opts.Field.Matches(fields.Set{"metadata.name": "tls-cert"})).To(gomega.BeFalse())
+	})
+})
@@ -0,0 +1,99 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// untilDeadline returns the time left before the entry's active deadline. The
+// second result is false when the entry has no deadline.
+func untilDeadline(redisEntry *redisv1alpha1.RedisEntry, now time.Time) (time.Duration, bool) {
+	if redisEntry.Spec.ActiveDeadlineSeconds == nil {
+		return 0, false
+	}
+	deadline := redisEntry.CreationTimestamp.Add(time.Duration(*redisEntry.Spec.ActiveDeadlineSeconds) * time.Second)
+	return deadline.Sub(now), true
+}
+
+// isCompleted reports whether the current generation of the entry has already
+// passed its deadline and been removed from Redis.
+func isCompleted(redisEntry *redisv1alpha1.RedisEntry) bool {
+	cond := meta.FindStatusCondition(redisEntry.Status.Conditions, typeCompleted)
+	return cond != nil && cond.Status == metav1.ConditionTrue && cond.ObservedGeneration == redisEntry.Generation
+}
+
+// deleteEntry removes every key declared by the entry. Like writeEntry, keys
+// spread across cluster slots are deleted one at a time in a pipeline.
+func (r *RedisEntryReconciler) deleteEntry(ctx context.Context, store KVStore, redisEntry *redisv1alpha1.RedisEntry) error {
+	keys := entryKeys(redisEntry)
+	if !store.CrossSlot(keys...) {
+		return store.Del(ctx, keys...)
+	}
+	_, err := store.Pipeline(ctx, false, func(pipe KVPipe) {
+		for _, key := range keys {
+			pipe.Del(key)
+		}
+	})
+	return err
+}
+
+// complete records that the entry's deadline passed and its keys were deleted.
+func (r *RedisEntryReconciler) complete(redisEntry *redisv1alpha1.RedisEntry) {
+	now := metav1.NewTime(r.now())
+	redisEntry.Status.CompletionTime = &now
+	meta.RemoveStatusCondition(&redisEntry.Status.Conditions, typeAvailable)
+	meta.RemoveStatusCondition(&redisEntry.Status.Conditions, typeError)
+	r.setCondition(redisEntry, typeCompleted, reasonDeadlineExceeded, "Active deadline exceeded, keys were deleted from Redis")
+}
+
+// untilCleanup returns the time left before a completed entry is deleted.
+// The second result is false when the entry is not deleted after finishing.
+func untilCleanup(redisEntry *redisv1alpha1.RedisEntry, now time.Time) (time.Duration, bool) {
+	if redisEntry.Spec.TTLSecondsAfterFinished == nil || redisEntry.Status.CompletionTime == nil {
+		return 0, false
+	}
+	cleanup := redisEntry.Status.CompletionTime.Add(time.Duration(*redisEntry.Spec.TTLSecondsAfterFinished) * time.Second)
+	return cleanup.Sub(now), true
+}
+
+// cleanUpFinished deletes a completed entry once its ttlSecondsAfterFinished
+// has passed, and otherwise comes back when it does.
+func (r *RedisEntryReconciler) cleanUpFinished(ctx context.Context, redisEntry *redisv1alpha1.RedisEntry) (ctrl.Result, error) {
+	remaining, ok := untilCleanup(redisEntry, r.now())
+	if !ok {
+		return ctrl.Result{}, nil
+	}
+	if remaining > 0 {
+		return ctrl.Result{RequeueAfter: remaining}, nil
+	}
+	// The UID precondition keeps a recreated entry of the same name
+	if err := r.Delete(ctx, redisEntry, client.Preconditions{UID: &redisEntry.UID}); client.IgnoreNotFound(err) != nil {
+		log.FromContext(ctx).Error(err, "Failed to delete finished RedisEntry")
+		return ctrl.Result{}, err
+	}
+	log.FromContext(ctx).Info("Deleted finished RedisEntry", "ttlSecondsAfterFinished", *redisEntry.Spec.TTLSecondsAfterFinished)
+	return ctrl.Result{}, nil
+}
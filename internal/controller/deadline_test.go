@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	redismock "github.com/go-redis/redismock/v9"
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clocktesting "k8s.io/utils/clock/testing"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = ginkgo.Describe("Active Deadline", func() {
+	var (
+		ctx  context.Context
+		mock redismock.ClientMock
+		name types.NamespacedName
+	)
+
+	// newReconciler returns a reconciler whose clock reads the given time after
+	// the entry was created
+	newReconciler := func(age time.Duration) *RedisEntryReconciler {
+		created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		s := runtime.NewScheme()
+		gomega.Expect(redisv1alpha1.AddToScheme(s)).To(gomega.Succeed())
+		deadline := int64(60)
+		entry := &redisv1alpha1.RedisEntry{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "timeboxed",
+				Namespace:         "default",
+				CreationTimestamp: metav1.NewTime(created),
+			},
+			Spec: redisv1alpha1.RedisEntrySpec{
+				Key:                   "timeboxed-key",
+				Value:                 "v",
+				Entries:               map[string]string{"timeboxed-extra": "e"},
+				ActiveDeadlineSeconds: &deadline,
+			},
+		}
+		mockRedis, m := redismock.NewClientMock()
+		mock = m
+		return &RedisEntryReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(s).
+				WithObjects(entry).
+				WithStatusSubresource(&redisv1alpha1.RedisEntry{}).
+				Build(),
+			Scheme:      s,
+			RedisClient: mockRedis,
+			Clock:       clocktesting.NewFakePassiveClock(created.Add(age)),
+		}
+	}
+
+	ginkgo.BeforeEach(func() {
+		ctx = context.Background()
+		name = types.NamespacedName{Name: "timeboxed", Namespace: "default"}
+	})
+
+	ginkgo.AfterEach(func() {
+		gomega.Expect(mock.ExpectationsWereMet()).To(gomega.Succeed())
+	})
+
+	ginkgo.It("should requeue for the deadline while the entry is active", func() {
+		r := newReconciler(10 * time.Second)
+		mock.ExpectTxPipeline()
+		mock.ExpectSet("timeboxed-key", "v", 0).SetVal("OK")
+		mock.ExpectMSet("timeboxed-extra", "e").SetVal("OK")
+		mock.ExpectTxPipelineExec()
+
+		result, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(result.RequeueAfter).To(gomega.Equal(50 * time.Second))
+	})
+
+	ginkgo.It("should delete the keys and complete once the deadline passed", func() {
+		r := newReconciler(2 * time.Minute)
+		mock.ExpectUnlink("timeboxed-key", "timeboxed-extra").SetVal(2)
+
+		result, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(result).To(gomega.Equal(reconcile.Result{}))
+
+		updated := &redisv1alpha1.RedisEntry{}
+		gomega.Expect(r.Get(ctx, name, updated)).To(gomega.Succeed())
+		gomega.Expect(meta.IsStatusConditionTrue(updated.Status.Conditions, typeCompleted)).To(gomega.BeTrue())
+		gomega.Expect(updated.Status.CompletionTime.Time).To(gomega.BeTemporally("==", time.Date(2025, 1, 1, 0, 2, 0, 0, time.UTC)))
+
+		// A completed entry is left alone
+		_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+	})
+
+	ginkgo.It("should delete the entry itself after ttlSecondsAfterFinished", func() {
+		r := newReconciler(2 * time.Minute)
+		entry := &redisv1alpha1.RedisEntry{}
+		gomega.Expect(r.Get(ctx, name, entry)).To(gomega.Succeed())
+		entry.Spec.TTLSecondsAfterFinished = ptr.To(int64(3600))
+		gomega.Expect(r.Update(ctx, entry)).To(gomega.Succeed())
+
+		mock.ExpectUnlink("timeboxed-key", "timeboxed-extra").SetVal(2)
+		result, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(result.RequeueAfter).To(gomega.Equal(time.Hour))
+
+		// Before the TTL passes the entry is kept
+		clock := r.Clock.(*clocktesting.FakePassiveClock)
+		clock.SetTime(clock.Now().Add(30 * time.Minute))
+		result, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(result.RequeueAfter).To(gomega.Equal(30 * time.Minute))
+
+		clock.SetTime(clock.Now().Add(30 * time.Minute))
+		_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(r.Get(ctx, name, entry)).To(gomega.Succeed())
+		gomega.Expect(entry.DeletionTimestamp).NotTo(gomega.BeNil())
+
+		// The keys are already gone, so the finalizer lets go right away
+		_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(apierrors.IsNotFound(r.Get(ctx, name, entry))).To(gomega.BeTrue())
+	})
+})
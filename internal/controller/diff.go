@@ -0,0 +1,201 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// DiffPath is where DiffHandler is served on the metrics server.
+const DiffPath = "/diff"
+
+// Key states in a diff report
+const (
+	// DiffInSync means the key holds the declared value and TTL
+	DiffInSync = "InSync"
+	// DiffExpired means the key is absent after its declared TTL ran out
+	DiffExpired = "Expired"
+	// DiffDrifted means the key holds a different value
+	DiffDrifted = "Drifted"
+	// DiffMissing means the key is absent although it never expires
+	DiffMissing = "Missing"
+	// DiffTTLMismatch means the key expires although no TTL is declared, or
+	// the other way around
+	DiffTTLMismatch = "TTLMismatch"
+	// DiffError means the entry could not be compared
+	DiffError = "Error"
+)
+
+// KeyDiff compares one key of a RedisEntry with Redis.
+type KeyDiff struct {
+	Key   string `json:"key"`
+	State string `json:"state"`
+
+	// Expected and Actual are only set for drifted keys, rendered as a
+	// SHA-256 prefix unless values may be logged verbatim
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+
+	// ExpectedTTLSeconds is the declared TTL; ActualTTLSeconds is the
+	// remaining TTL, with -1 meaning the key never expires
+	ExpectedTTLSeconds *int64 `json:"expectedTTLSeconds,omitempty"`
+	ActualTTLSeconds   *int64 `json:"actualTTLSeconds,omitempty"`
+}
+
+// EntryDiff compares all keys of a RedisEntry with Redis.
+type EntryDiff struct {
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	InSync    bool      `json:"inSync"`
+	Keys      []KeyDiff `json:"keys,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// firstDifference returns the first key that is not in sync.
+func (d *EntryDiff) firstDifference() (string, string) {
+	if d.Error != "" {
+		return "", DiffError
+	}
+	for _, key := range d.Keys {
+		if key.State != DiffInSync && key.State != DiffExpired {
+			return key.Key, key.State
+		}
+	}
+	return "", DiffInSync
+}
+
+// diffEntries compares every managed entry matching opts and, unless nil,
+// match with Redis and passes each result to fn. Entries past their active
+// deadline are skipped, since their keys are deleted on purpose.
+func (r *RedisEntryReconciler) diffEntries(ctx context.Context, match func(*redisv1alpha1.RedisEntry) bool,
+	fn func(EntryDiff) error, opts ...client.ListOption) error {
+	return forEachEntry(ctx, r.Client, r.APIReader, func(redisEntry *redisv1alpha1.RedisEntry) error {
+		if !r.managesEntry(redisEntry) || isCompleted(redisEntry) {
+			return nil
+		}
+		if match != nil && !match(redisEntry) {
+			return nil
+		}
+		return fn(r.diffEntry(ctx, redisEntry))
+	}, opts...)
+}
+
+// diffEntry compares the keys of a single entry with Redis.
+func (r *RedisEntryReconciler) diffEntry(ctx context.Context, redisEntry *redisv1alpha1.RedisEntry) EntryDiff {
+	diff := EntryDiff{Namespace: redisEntry.Namespace, Name: redisEntry.Name}
+	redisClient, err := r.redisClientForEntry(ctx, redisEntry)
+	if err != nil {
+		diff.Error = err.Error()
+		return diff
+	}
+	defer redisClient.release()
+	value, err := r.resolveValue(ctx, redisEntry)
+	if err != nil {
+		diff.Error = err.Error()
+		return diff
+	}
+
+	store := r.store(redisClient.client, redisClient.server)
+	keys, desired, _ := desiredState(redisEntry, value)
+	values, err := store.Get(ctx, keys...)
+	if err != nil {
+		diff.Error = err.Error()
+		return diff
+	}
+	ttls, err := store.TTL(ctx, keys...)
+	if err != nil {
+		diff.Error = err.Error()
+		return diff
+	}
+
+	shown := r.valueLogMode(redisEntry)
+	if shown != ValueLogAlways {
+		shown = ValueLogHashed
+	}
+	diff.InSync = true
+	for i, key := range keys {
+		keyDiff := KeyDiff{Key: key, ExpectedTTLSeconds: redisEntry.Spec.TTL}
+		// TTL returns -1 for keys without an expiry and -2 for missing keys
+		ttl := ttls[i]
+		if ttl == -1 || ttl >= 0 {
+			seconds := int64(-1)
+			if ttl >= 0 {
+				seconds = int64(ttl / time.Second)
+			}
+			keyDiff.ActualTTLSeconds = &seconds
+		}
+		switch actual := values[i]; {
+		case actual == nil && redisEntry.Spec.TTL != nil:
+			keyDiff.State = DiffExpired
+		case actual == nil:
+			keyDiff.State = DiffMissing
+		case *actual != desired[i]:
+			keyDiff.State = DiffDrifted
+			keyDiff.Expected, keyDiff.Actual = shown.redact(desired[i]), shown.redact(*actual)
+		case (redisEntry.Spec.TTL != nil) != (ttl != -1):
+			keyDiff.State = DiffTTLMismatch
+		default:
+			keyDiff.State = DiffInSync
+		}
+		if keyDiff.State != DiffInSync && keyDiff.State != DiffExpired {
+			diff.InSync = false
+		}
+		diff.Keys = append(diff.Keys, keyDiff)
+	}
+	return diff
+}
+
+// DiffHandler returns an HTTP handler that streams a JSON report comparing
+// every managed RedisEntry's declared value and TTL with Redis, one entry per
+// line.
+func (r *RedisEntryReconciler) DiffHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "diff requires GET", http.StatusMethodNotAllowed)
+			return
+		}
+		if r.RedisClient == nil {
+			http.Error(w, "controller is not set up", http.StatusServiceUnavailable)
+			return
+		}
+
+		encoder := json.NewEncoder(w)
+		written := false
+		err := r.diffEntries(req.Context(), nil, func(diff EntryDiff) error {
+			if !written {
+				w.Header().Set("Content-Type", "application/x-ndjson")
+				written = true
+			}
+			return encoder.Encode(diff)
+		})
+		if err == nil {
+			return
+		}
+		log.FromContext(req.Context()).Error(err, "Failed to produce diff report")
+		if !written {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
@@ -0,0 +1,142 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	redismock "github.com/go-redis/redismock/v9"
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	redisv9 "github.com/redis/go-redis/v9"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = ginkgo.Describe("Entry Diff Report", func() {
+	var (
+		ctx        context.Context
+		mock       redismock.ClientMock
+		reconciler *RedisEntryReconciler
+		entry      *redisv1alpha1.RedisEntry
+	)
+
+	ginkgo.BeforeEach(func() {
+		ctx = context.Background()
+		s := runtime.NewScheme()
+		gomega.Expect(redisv1alpha1.AddToScheme(s)).To(gomega.Succeed())
+
+		entry = &redisv1alpha1.RedisEntry{
+			ObjectMeta: metav1.ObjectMeta{Name: "diff-entry", Namespace: "default"},
+			Spec: redisv1alpha1.RedisEntrySpec{
+				Key:     "a",
+				Value:   "1",
+				Entries: map[string]string{"b": "2"},
+			},
+		}
+		var mockRedis *redisv9.Client
+		mockRedis, mock = redismock.NewClientMock()
+		reconciler = &RedisEntryReconciler{
+			Client:      fake.NewClientBuilder().WithScheme(s).WithObjects(entry).Build(),
+			Scheme:      s,
+			RedisClient: mockRedis,
+		}
+	})
+
+	ginkgo.AfterEach(func() {
+		gomega.Expect(mock.ExpectationsWereMet()).To(gomega.Succeed())
+	})
+
+	ginkgo.It("should report drifted values without revealing them", func() {
+		mock.ExpectMGet("a", "b").SetVal([]interface{}{"1", "changed"})
+		mock.ExpectPTTL("a").SetVal(time.Duration(-1))
+		mock.ExpectPTTL("b").SetVal(time.Duration(-1))
+
+		diff := reconciler.diffEntry(ctx, entry)
+		gomega.Expect(diff.InSync).To(gomega.BeFalse())
+		gomega.Expect(diff.Keys[0].State).To(gomega.Equal(DiffInSync))
+		gomega.Expect(diff.Keys[1].State).To(gomega.Equal(DiffDrifted))
+		gomega.Expect(diff.Keys[1].Expected).To(gomega.HavePrefix("sha256:"))
+		gomega.Expect(diff.Keys[1].Actual).NotTo(gomega.ContainSubstring("changed"))
+		key, state := diff.firstDifference()
+		gomega.Expect(key).To(gomega.Equal("b"))
+		gomega.Expect(state).To(gomega.Equal(DiffDrifted))
+	})
+
+	ginkgo.It("should distinguish expired, missing and TTL mismatches", func() {
+		entry.Spec.TTL = new(int64)
+		*entry.Spec.TTL = 60
+		mock.ExpectMGet("a", "b").SetVal([]interface{}{nil, "2"})
+		mock.ExpectPTTL("a").SetVal(time.Duration(-2))
+		mock.ExpectPTTL("b").SetVal(time.Duration(-1))
+
+		diff := reconciler.diffEntry(ctx, entry)
+		gomega.Expect(diff.Keys[0].State).To(gomega.Equal(DiffExpired))
+		gomega.Expect(diff.Keys[0].ActualTTLSeconds).To(gomega.BeNil())
+		gomega.Expect(diff.Keys[1].State).To(gomega.Equal(DiffTTLMismatch))
+		gomega.Expect(*diff.Keys[1].ActualTTLSeconds).To(gomega.Equal(int64(-1)))
+
+		entry.Spec.TTL = nil
+		mock.ExpectMGet("a", "b").SetVal([]interface{}{nil, "2"})
+		mock.ExpectPTTL("a").SetVal(time.Duration(-2))
+		mock.ExpectPTTL("b").SetVal(30 * time.Second)
+
+		diff = reconciler.diffEntry(ctx, entry)
+		gomega.Expect(diff.Keys[0].State).To(gomega.Equal(DiffMissing))
+		gomega.Expect(diff.Keys[1].State).To(gomega.Equal(DiffTTLMismatch))
+		gomega.Expect(*diff.Keys[1].ActualTTLSeconds).To(gomega.Equal(int64(30)))
+	})
+
+	ginkgo.It("should stream one entry per line", func() {
+		mock.ExpectMGet("a", "b").SetVal([]interface{}{"1", "2"})
+		mock.ExpectPTTL("a").SetVal(time.Duration(-1))
+		mock.ExpectPTTL("b").SetVal(time.Duration(-1))
+
+		recorder := httptest.NewRecorder()
+		reconciler.DiffHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, DiffPath, nil))
+		gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusOK))
+
+		lines := strings.Split(strings.TrimSpace(recorder.Body.String()), "\n")
+		gomega.Expect(lines).To(gomega.HaveLen(1))
+		var diff EntryDiff
+		gomega.Expect(json.Unmarshal([]byte(lines[0]), &diff)).To(gomega.Succeed())
+		gomega.Expect(diff.Name).To(gomega.Equal("diff-entry"))
+		gomega.Expect(diff.InSync).To(gomega.BeTrue())
+	})
+
+	ginkgo.It("should add drifted entries to an audit report", func() {
+		mock.ExpectMGet("a", "b").SetVal([]interface{}{nil, "2"})
+		mock.ExpectPTTL("a").SetVal(time.Duration(-2))
+		mock.ExpectPTTL("b").SetVal(time.Duration(-1))
+
+		// Entries in other namespaces or on other connections are not compared
+		gomega.Expect(reconciler.Create(ctx, &redisv1alpha1.RedisEntry{
+			ObjectMeta: metav1.ObjectMeta{Name: "elsewhere", Namespace: "other"},
+			Spec:       redisv1alpha1.RedisEntrySpec{Key: "c", Value: "3"},
+		})).To(gomega.Succeed())
+		gomega.Expect(reconciler.Create(ctx, &redisv1alpha1.RedisEntry{
+			ObjectMeta: metav1.ObjectMeta{Name: "remote", Namespace: "default"},
+			Spec: redisv1alpha1.RedisEntrySpec{
+				Key:           "d",
+				Value:         "4",
+				ConnectionRef: &corev1.LocalObjectReference{Name: "cache"},
+			},
+		})).To(gomega.Succeed())
+
+		audits := &RedisAuditReconciler{Entries: reconciler}
+		audit := &redisv1alpha1.RedisAudit{ObjectMeta: metav1.ObjectMeta{Name: "audit", Namespace: "default"}}
+		report := &redisv1alpha1.RedisAuditStatus{}
+		gomega.Expect(audits.compareEntries(ctx, audit, report)).To(gomega.Succeed())
+		gomega.Expect(report.EntriesInSync).To(gomega.BeZero())
+		gomega.Expect(report.EntriesDrifted).To(gomega.Equal(int64(1)))
+		gomega.Expect(report.DriftedEntries).To(gomega.ConsistOf(redisv1alpha1.DriftedEntry{
+			Namespace: "default", Name: "diff-entry", Key: "a", State: DiffMissing,
+		}))
+	})
+})
@@ -0,0 +1,181 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// DrainPath is where DrainHandler is served on the metrics server.
+const DrainPath = "/drain"
+
+// drainRequeueDelay is how long reconciles refused while draining wait; the
+// process normally exits first and the next leader picks the entry up.
+const drainRequeueDelay = 5 * time.Second
+
+// drainState tracks reconciles in flight so the controller can stop taking
+// new work and wait for the running syncs before it exits. The zero value is
+// ready to use.
+type drainState struct {
+	mu        sync.Mutex
+	draining  bool
+	requested chan struct{}
+	inFlight  sync.WaitGroup
+}
+
+// begin registers a reconcile. It returns false once draining started, in
+// which case the reconcile must not write anything; otherwise end must be
+// called when it returns.
+func (d *drainState) begin() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.draining {
+		return false
+	}
+	d.inFlight.Add(1)
+	return true
+}
+
+// end marks a reconcile registered by begin as finished.
+func (d *drainState) end() {
+	d.inFlight.Done()
+}
+
+// requestedChan returns the channel closed by request.
+func (d *drainState) requestedChan() chan struct{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.requestedLocked()
+}
+
+// requestedLocked is requestedChan with mu held.
+func (d *drainState) requestedLocked() chan struct{} {
+	if d.requested == nil {
+		d.requested = make(chan struct{})
+	}
+	return d.requested
+}
+
+// request asks the process to drain and exit. It reports whether this was
+// the first request.
+func (d *drainState) request() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	ch := d.requestedLocked()
+	select {
+	case <-ch:
+		return false
+	default:
+		close(ch)
+		return true
+	}
+}
+
+// stop refuses new reconciles and waits until those in flight finished or
+// ctx is done.
+func (d *drainState) stop(ctx context.Context) error {
+	d.mu.Lock()
+	d.draining = true
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.inFlight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("reconciles still in flight: %w", ctx.Err())
+	}
+}
+
+// DrainRequested returns a channel that is closed when a drain was requested
+// through DrainHandler. The caller is expected to call Drain and then stop the
+// manager.
+func (r *RedisEntryReconciler) DrainRequested() <-chan struct{} {
+	return r.drain.requestedChan()
+}
+
+// Drain prepares the controller to exit without leaving grouped writes half
+// done: it stops starting reconciles of entries and transactions, waits for
+// those in flight, and then writes the status updates deferred by
+// StatusCoalesceWindow. It must be called before the manager is stopped,
+// since stopping it cancels in-flight reconciles. Reconciles requested while
+// draining are requeued and left to the next leader.
+func (r *RedisEntryReconciler) Drain(ctx context.Context) error {
+	log := log.FromContext(ctx)
+	log.Info("Draining, waiting for in-flight reconciles")
+	if err := r.drain.stop(ctx); err != nil {
+		return err
+	}
+	if err := r.flushStatuses(ctx); err != nil {
+		return err
+	}
+	log.Info("Drained")
+	return nil
+}
+
+// flushStatuses writes the sync attempts of every entry whose status writes
+// were deferred.
+func (r *RedisEntryReconciler) flushStatuses(ctx context.Context) error {
+	var errs []error
+	for _, name := range r.statuses.deferred() {
+		redisEntry := &redisv1alpha1.RedisEntry{}
+		if err := r.Get(ctx, name, redisEntry); err != nil {
+			if !apierrors.IsNotFound(err) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		redisEntry.Status.SyncAttempts += r.statuses.pending(name)
+		if err := r.Client.Status().Update(ctx, redisEntry); err != nil {
+			errs = append(errs, fmt.Errorf("failed to flush status of %s: %w", name, err))
+			continue
+		}
+		r.statuses.wrote(name, r.now())
+	}
+	return errors.Join(errs...)
+}
+
+// DrainHandler returns an HTTP handler that asks the controller to drain and
+// exit, for example from a preStop hook. It accepts POST requests and returns
+// before the drain completes.
+func (r *RedisEntryReconciler) DrainHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "drain requires POST", http.StatusMethodNotAllowed)
+			return
+		}
+		if r.drain.request() {
+			log.FromContext(req.Context()).Info("Drain requested")
+		}
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprintln(w, "drain requested")
+	})
+}
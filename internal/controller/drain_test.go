@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = ginkgo.Describe("Drain", func() {
+	var (
+		ctx        context.Context
+		reconciler *RedisEntryReconciler
+		name       types.NamespacedName
+	)
+
+	ginkgo.BeforeEach(func() {
+		ctx = context.Background()
+		s := runtime.NewScheme()
+		gomega.Expect(redisv1alpha1.AddToScheme(s)).To(gomega.Succeed())
+		entry := &redisv1alpha1.RedisEntry{
+			ObjectMeta: metav1.ObjectMeta{Name: "drained", Namespace: "default"},
+			Spec:       redisv1alpha1.RedisEntrySpec{Key: "k", Value: "v"},
+			Status:     redisv1alpha1.RedisEntryStatus{SyncAttempts: 3},
+		}
+		name = types.NamespacedName{Name: "drained", Namespace: "default"}
+		reconciler = &RedisEntryReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(s).
+				WithObjects(entry).
+				WithStatusSubresource(&redisv1alpha1.RedisEntry{}).
+				Build(),
+			Scheme: s,
+		}
+	})
+
+	ginkgo.It("should wait for reconciles in flight and refuse new ones", func() {
+		gomega.Expect(reconciler.drain.begin()).To(gomega.BeTrue())
+
+		drained := make(chan error)
+		go func() { drained <- reconciler.Drain(ctx) }()
+		gomega.Consistently(drained, 50*time.Millisecond).ShouldNot(gomega.Receive())
+
+		// Without a Redis client this would otherwise record an error
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(result.RequeueAfter).To(gomega.Equal(drainRequeueDelay))
+		entry := &redisv1alpha1.RedisEntry{}
+		gomega.Expect(reconciler.Get(ctx, name, entry)).To(gomega.Succeed())
+		gomega.Expect(entry.Status.Conditions).To(gomega.BeEmpty())
+
+		reconciler.drain.end()
+		gomega.Eventually(drained).Should(gomega.Receive(gomega.BeNil()))
+	})
+
+	ginkgo.It("should give up waiting when the context is done", func() {
+		gomega.Expect(reconciler.drain.begin()).To(gomega.BeTrue())
+		defer reconciler.drain.end()
+
+		timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		gomega.Expect(reconciler.Drain(timeout)).To(gomega.MatchError(context.DeadlineExceeded))
+	})
+
+	ginkgo.It("should flush deferred status writes", func() {
+		now := time.Now()
+		reconciler.statuses.wrote(name, now)
+		gomega.Expect(reconciler.statuses.deferWrite(name, time.Minute, now)).To(gomega.BeTrue())
+		gomega.Expect(reconciler.statuses.deferWrite(name, time.Minute, now)).To(gomega.BeTrue())
+
+		gomega.Expect(reconciler.Drain(ctx)).To(gomega.Succeed())
+		entry := &redisv1alpha1.RedisEntry{}
+		gomega.Expect(reconciler.Get(ctx, name, entry)).To(gomega.Succeed())
+		gomega.Expect(entry.Status.SyncAttempts).To(gomega.Equal(int64(5)))
+		gomega.Expect(reconciler.statuses.deferred()).To(gomega.BeEmpty())
+	})
+
+	ginkgo.It("should signal drain requests from the endpoint", func() {
+		send := func(method string) int {
+			recorder := httptest.NewRecorder()
+			reconciler.DrainHandler().ServeHTTP(recorder, httptest.NewRequest(method, DrainPath, nil))
+			return recorder.Code
+		}
+		gomega.Expect(send(http.MethodGet)).To(gomega.Equal(http.StatusMethodNotAllowed))
+		gomega.Expect(reconciler.DrainRequested()).NotTo(gomega.BeClosed())
+
+		gomega.Expect(send(http.MethodPost)).To(gomega.Equal(http.StatusAccepted))
+		gomega.Expect(send(http.MethodPost)).To(gomega.Equal(http.StatusAccepted))
+		gomega.Expect(reconciler.DrainRequested()).To(gomega.BeClosed())
+	})
+})
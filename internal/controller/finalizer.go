@@ -0,0 +1,111 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// deletionPolicy returns the entry's deletion policy, defaulting to Delete.
+func deletionPolicy(redisEntry *redisv1alpha1.RedisEntry) redisv1alpha1.DeletionPolicy {
+	if redisEntry.Spec.DeletionPolicy == "" {
+		return redisv1alpha1.DeletionPolicyDelete
+	}
+	return redisEntry.Spec.DeletionPolicy
+}
+
+// updateFinalizer makes sure the entry is not removed before its keys are
+// handled, unless its keys are orphaned.
+func (r *RedisEntryReconciler) updateFinalizer(ctx context.Context, redisEntry *redisv1alpha1.RedisEntry) error {
+	var changed bool
+	if deletionPolicy(redisEntry) == redisv1alpha1.DeletionPolicyOrphan {
+		changed = controllerutil.RemoveFinalizer(redisEntry, redisv1alpha1.KeyCleanupFinalizer)
+	} else {
+		changed = controllerutil.AddFinalizer(redisEntry, redisv1alpha1.KeyCleanupFinalizer)
+	}
+	if !changed {
+		return nil
+	}
+	return r.Update(ctx, redisEntry)
+}
+
+// finalize applies the deletion policy to the keys of an entry being deleted,
+// records the outcome in the Deleting condition and then releases the entry.
+// While Redis is unreachable the entry is kept and the deletion retried.
+// Keys the entry was refused, and keys already deleted after the active
+// deadline, are left alone.
+func (r *RedisEntryReconciler) finalize(ctx context.Context, s *entrySync) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+	defer s.close()
+
+	if !controllerutil.ContainsFinalizer(s.entry, redisv1alpha1.KeyCleanupFinalizer) {
+		return ctrl.Result{}, nil
+	}
+	reason, message := r.retainedKeys(s.entry)
+	if reason == "" {
+		if res := r.connect(ctx, s); res != nil {
+			return r.report(ctx, s, res)
+		}
+		if err := r.deleteEntry(ctx, s.store, s.entry); err != nil {
+			log.Error(err, "Failed to delete keys of deleted RedisEntry")
+			return r.report(ctx, s, r.fail(s, reasonRedisError, err.Error(), redisErrorRetryDelay))
+		}
+		log.Info("Deleted keys of deleted RedisEntry", "key", s.entry.Spec.Key)
+		reason, message = reasonKeysDeleted, "Keys were deleted from Redis"
+	} else {
+		log.Info("Leaving keys of deleted RedisEntry in Redis", "key", s.entry.Spec.Key, "reason", reason)
+	}
+
+	meta.RemoveStatusCondition(&s.entry.Status.Conditions, typeError)
+	r.setCondition(s.entry, typeDeleting, reason, message)
+	if err := r.updateStatus(ctx, s.entry, s.original); client.IgnoreNotFound(err) != nil {
+		log.Error(err, "Failed to update RedisEntry status")
+		return ctrl.Result{}, err
+	}
+	controllerutil.RemoveFinalizer(s.entry, redisv1alpha1.KeyCleanupFinalizer)
+	if err := r.Update(ctx, s.entry); client.IgnoreNotFound(err) != nil {
+		log.Error(err, "Failed to remove finalizer from RedisEntry")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// retainedKeys returns why the keys of a deleted entry are left in Redis, or
+// an empty reason when they are to be deleted.
+func (r *RedisEntryReconciler) retainedKeys(redisEntry *redisv1alpha1.RedisEntry) (reason, message string) {
+	if key, reserved := r.reservedKey(redisEntry); reserved {
+		return reasonReservedKey, fmt.Sprintf("Key %q uses a reserved prefix and was never written", key)
+	}
+	if err := r.invalidKey(redisEntry); err != nil {
+		return reasonInvalidKey, "Keys were never written: " + err.Error()
+	}
+	if isCompleted(redisEntry) {
+		return reasonDeadlineExceeded, "Keys were already deleted after the active deadline"
+	}
+	if policy := deletionPolicy(redisEntry); policy != redisv1alpha1.DeletionPolicyDelete {
+		return reasonKeysRetained, fmt.Sprintf("Deletion policy is %s, keys were left in Redis", policy)
+	}
+	return "", ""
+}
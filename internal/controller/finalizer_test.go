@@ -0,0 +1,155 @@
+package controller
+
+import (
+	"context"
+	"errors"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	redismock "github.com/go-redis/redismock/v9"
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = ginkgo.Describe("Key Cleanup Finalizer", func() {
+	var (
+		ctx  context.Context
+		mock redismock.ClientMock
+		r    *RedisEntryReconciler
+		name types.NamespacedName
+		// status is the last status written, which outlives the entry
+		status redisv1alpha1.RedisEntryStatus
+	)
+
+	ginkgo.BeforeEach(func() {
+		ctx = context.Background()
+		name = types.NamespacedName{Name: "cached", Namespace: "default"}
+		s := runtime.NewScheme()
+		gomega.Expect(redisv1alpha1.AddToScheme(s)).To(gomega.Succeed())
+		entry := &redisv1alpha1.RedisEntry{
+			ObjectMeta: metav1.ObjectMeta{Name: name.Name, Namespace: name.Namespace},
+			Spec:       redisv1alpha1.RedisEntrySpec{Key: "cache:key", Value: "v"},
+		}
+		mockRedis, m := redismock.NewClientMock()
+		mock = m
+		r = &RedisEntryReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(s).
+				WithObjects(entry).
+				WithStatusSubresource(&redisv1alpha1.RedisEntry{}).
+				WithInterceptorFuncs(interceptor.Funcs{
+					SubResourceUpdate: func(ctx context.Context, c client.Client, subResource string,
+						obj client.Object, opts ...client.SubResourceUpdateOption) error {
+						status = obj.(*redisv1alpha1.RedisEntry).Status
+						return c.SubResource(subResource).Update(ctx, obj, opts...)
+					},
+				}).
+				Build(),
+			Scheme:      s,
+			RedisClient: mockRedis,
+		}
+
+		mock.ExpectSet("cache:key", "v", 0).SetVal("OK")
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+	})
+
+	ginkgo.AfterEach(func() {
+		gomega.Expect(mock.ExpectationsWereMet()).To(gomega.Succeed())
+	})
+
+	// deleteEntry deletes the entry, which the finalizer keeps around
+	deleteEntry := func() {
+		entry := &redisv1alpha1.RedisEntry{}
+		gomega.Expect(r.Get(ctx, name, entry)).To(gomega.Succeed())
+		gomega.Expect(entry.Finalizers).To(gomega.ConsistOf(redisv1alpha1.KeyCleanupFinalizer))
+		gomega.Expect(r.Delete(ctx, entry)).To(gomega.Succeed())
+	}
+
+	// setPolicy changes the deletion policy and reconciles the change
+	setPolicy := func(policy redisv1alpha1.DeletionPolicy) {
+		entry := &redisv1alpha1.RedisEntry{}
+		gomega.Expect(r.Get(ctx, name, entry)).To(gomega.Succeed())
+		entry.Spec.DeletionPolicy = policy
+		gomega.Expect(r.Update(ctx, entry)).To(gomega.Succeed())
+		mock.ExpectSet("cache:key", "v", 0).SetVal("OK")
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+	}
+
+	// expectReleased checks that the entry is gone and recorded why
+	expectReleased := func(reason string) {
+		gomega.Expect(apierrors.IsNotFound(r.Get(ctx, name, &redisv1alpha1.RedisEntry{}))).To(gomega.BeTrue())
+		cond := meta.FindStatusCondition(status.Conditions, typeDeleting)
+		gomega.Expect(cond).NotTo(gomega.BeNil())
+		gomega.Expect(cond.Reason).To(gomega.Equal(reason))
+	}
+
+	ginkgo.It("should delete the key before releasing the entry", func() {
+		deleteEntry()
+		mock.ExpectUnlink("cache:key").SetVal(1)
+
+		result, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(result).To(gomega.Equal(reconcile.Result{}))
+		expectReleased(reasonKeysDeleted)
+	})
+
+	ginkgo.It("should keep the entry while Redis is unavailable", func() {
+		deleteEntry()
+		mock.ExpectUnlink("cache:key").SetErr(errors.New("connection refused"))
+
+		result, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(result.RequeueAfter).To(gomega.Equal(redisErrorRetryDelay))
+
+		entry := &redisv1alpha1.RedisEntry{}
+		gomega.Expect(r.Get(ctx, name, entry)).To(gomega.Succeed())
+		gomega.Expect(entry.Finalizers).To(gomega.ConsistOf(redisv1alpha1.KeyCleanupFinalizer))
+		cond := meta.FindStatusCondition(entry.Status.Conditions, typeError)
+		gomega.Expect(cond).NotTo(gomega.BeNil())
+		gomega.Expect(cond.Reason).To(gomega.Equal(reasonRedisError))
+
+		mock.ExpectUnlink("cache:key").SetVal(1)
+		_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		expectReleased(reasonKeysDeleted)
+		gomega.Expect(meta.FindStatusCondition(status.Conditions, typeError)).To(gomega.BeNil())
+	})
+
+	ginkgo.It("should keep the keys with the Retain policy", func() {
+		setPolicy(redisv1alpha1.DeletionPolicyRetain)
+		deleteEntry()
+
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		expectReleased(reasonKeysRetained)
+	})
+
+	ginkgo.It("should drop the finalizer with the Orphan policy", func() {
+		setPolicy(redisv1alpha1.DeletionPolicyOrphan)
+
+		entry := &redisv1alpha1.RedisEntry{}
+		gomega.Expect(r.Get(ctx, name, entry)).To(gomega.Succeed())
+		gomega.Expect(entry.Finalizers).To(gomega.BeEmpty())
+		gomega.Expect(r.Delete(ctx, entry)).To(gomega.Succeed())
+		gomega.Expect(apierrors.IsNotFound(r.Get(ctx, name, entry))).To(gomega.BeTrue())
+	})
+
+	ginkgo.It("should leave keys the entry was refused alone", func() {
+		r.ReservedKeyPrefixes = []string{"cache:"}
+		deleteEntry()
+
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		expectReleased(reasonReservedKey)
+	})
+})
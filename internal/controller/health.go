@@ -0,0 +1,252 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	redisv9 "github.com/redis/go-redis/v9"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	// defaultHealthCheckInterval is used when no interval is configured
+	defaultHealthCheckInterval = 10 * time.Second
+
+	// DefaultMarkerKey is the key used to detect that Redis lost its data
+	DefaultMarkerKey = "redis-ctrl:marker"
+)
+
+// healthMonitor periodically pings the controller's own Redis. When the
+// previously unreachable server answers again, it enqueues the RedisEntries
+// written to it through a channel source so they resync right away instead of
+// waiting out their retry delays. Entries with a connectionRef are resynced
+// when the RedisConnection check sees their server recover.
+type healthMonitor struct {
+	client      client.Reader
+	redisClient redisv9.UniversalClient
+	interval    time.Duration
+	events      chan event.GenericEvent
+
+	// resyncs holds at most one pending resync requested through the
+	// resync endpoint
+	resyncs chan struct{}
+
+	// selector limits resyncs to the entries the controller manages
+	selector labels.Selector
+
+	// apiReader, when set, pages through entries on the API server
+	apiReader client.Reader
+
+	// markerKey, when set, is kept in Redis to detect that the server lost
+	// its data
+	markerKey string
+	metrics   *Metrics
+
+	// latencyLatest additionally exports the spikes recorded by the
+	// server's latency monitor on every check
+	latencyLatest bool
+
+	// latency holds the recent PING round trips
+	latency latencyWindow
+
+	// markerWritten is set once the marker key has been written; only
+	// accessed from the monitor goroutine
+	markerWritten bool
+
+	// healthy is only accessed from the monitor goroutine
+	healthy bool
+}
+
+// newHealthMonitor creates a monitor for a Redis client that is known to be
+// reachable.
+func newHealthMonitor(c client.Reader, redisClient redisv9.UniversalClient, interval time.Duration) *healthMonitor {
+	if interval <= 0 {
+		interval = defaultHealthCheckInterval
+	}
+	return &healthMonitor{
+		client:      c,
+		redisClient: redisClient,
+		interval:    interval,
+		events:      make(chan event.GenericEvent),
+		resyncs:     make(chan struct{}, 1),
+		healthy:     true,
+	}
+}
+
+// source returns the channel source that feeds recovery events to the controller.
+func (h *healthMonitor) source() source.Source {
+	return source.Channel(h.events, &handler.EnqueueRequestForObject{})
+}
+
+// Start runs the health checks until the context is cancelled.
+func (h *healthMonitor) Start(ctx context.Context) error {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			h.check(ctx)
+		case <-h.resyncs:
+			log.FromContext(ctx).WithName("redis-health").Info("Resyncing all entries on request")
+			h.resyncAll(ctx)
+		}
+	}
+}
+
+// NeedLeaderElection makes the monitor run only on the leader, which is the
+// only replica whose controller consumes the events.
+func (h *healthMonitor) NeedLeaderElection() bool {
+	return true
+}
+
+// check pings Redis once and triggers a resync on recovery or when the
+// marker key has disappeared.
+func (h *healthMonitor) check(ctx context.Context) {
+	log := log.FromContext(ctx).WithName("redis-health")
+
+	start := time.Now()
+	if err := h.redisClient.Ping(ctx).Err(); err != nil {
+		if h.healthy {
+			log.Error(err, "Redis became unreachable")
+		}
+		h.healthy = false
+		return
+	}
+	h.recordLatency(ctx, time.Since(start))
+
+	lost := h.checkMarker(ctx)
+	if h.healthy && !lost {
+		return
+	}
+	if !h.healthy {
+		log.Info("Redis is reachable again, resyncing its entries")
+	}
+	h.healthy = true
+	h.resync(ctx, onDefaultConnection)
+}
+
+// onDefaultConnection reports whether an entry is written with the
+// controller's own client rather than through a RedisConnection.
+func onDefaultConnection(entry *redisv1alpha1.RedisEntry) bool {
+	return entry.Spec.ConnectionRef == nil
+}
+
+// recordLatency adds a PING round trip to the latency window and exports the
+// latency quantiles and, when enabled, the server's latest latency spikes.
+func (h *healthMonitor) recordLatency(ctx context.Context, rtt time.Duration) {
+	// Without metrics there is nowhere to publish the latency
+	if h.metrics == nil {
+		return
+	}
+	h.latency.add(rtt)
+	quantiles := h.latency.quantiles(0.5, 0.99)
+	h.metrics.recordPingLatency(quantiles[0], quantiles[1])
+
+	if !h.latencyLatest {
+		return
+	}
+	events, err := latestLatency(ctx, h.redisClient)
+	if err != nil {
+		log.FromContext(ctx).WithName("redis-health").V(1).Info("Failed to read latency monitor", "error", err.Error())
+		return
+	}
+	h.metrics.recordRedisLatency(events)
+}
+
+// checkMarker reports whether the marker key written by an earlier check is
+// gone, which means Redis was flushed, failed over to an empty replica or
+// restored from a backup, and writes the marker again.
+func (h *healthMonitor) checkMarker(ctx context.Context) bool {
+	if h.markerKey == "" {
+		return false
+	}
+	log := log.FromContext(ctx).WithName("redis-health")
+
+	exists, err := h.redisClient.Exists(ctx, h.markerKey).Result()
+	if err != nil {
+		log.Error(err, "Failed to check marker key", "key", h.markerKey)
+		return false
+	}
+	if exists == 1 {
+		return false
+	}
+
+	lost := h.markerWritten
+	if lost {
+		log.Info("Marker key disappeared, Redis lost its data; resyncing its entries", "key", h.markerKey)
+		h.metrics.recordDatasetLoss()
+	}
+	if err := h.redisClient.Set(ctx, h.markerKey, time.Now().UTC().Format(time.RFC3339), 0).Err(); err != nil {
+		log.Error(err, "Failed to write marker key", "key", h.markerKey)
+		return lost
+	}
+	h.markerWritten = true
+	return lost
+}
+
+// requestResync asks the monitor to resync all entries. It returns false if
+// a requested resync is already pending.
+func (h *healthMonitor) requestResync() bool {
+	select {
+	case h.resyncs <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// resyncAll sends a generic event for every RedisEntry.
+func (h *healthMonitor) resyncAll(ctx context.Context) {
+	h.resync(ctx, nil)
+}
+
+// resync sends a generic event for every RedisEntry accepted by match, or
+// for every entry when match is nil. opts narrow the listed entries.
+func (h *healthMonitor) resync(ctx context.Context, match func(*redisv1alpha1.RedisEntry) bool,
+	opts ...client.ListOption) {
+	log := log.FromContext(ctx).WithName("redis-health")
+
+	if h.selector != nil {
+		opts = append(opts, client.MatchingLabelsSelector{Selector: h.selector})
+	}
+	err := forEachEntry(ctx, h.client, h.apiReader, func(entry *redisv1alpha1.RedisEntry) error {
+		if match != nil && !match(entry) {
+			return nil
+		}
+		h.metrics.enqueued(client.ObjectKeyFromObject(entry), backlogDrift)
+		select {
+		case h.events <- event.GenericEvent{Object: entry}:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}, opts...)
+	if err != nil && ctx.Err() == nil {
+		log.Error(err, "Failed to list RedisEntries for resync")
+	}
+}
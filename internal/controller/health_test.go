@@ -0,0 +1,138 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	redismock "github.com/go-redis/redismock/v9"
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	redisv9 "github.com/redis/go-redis/v9"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+var _ = ginkgo.Describe("Redis Health Monitor", func() {
+	var (
+		ctx     context.Context
+		mock    redismock.ClientMock
+		monitor *healthMonitor
+	)
+
+	ginkgo.BeforeEach(func() {
+		ctx = context.Background()
+		s := runtime.NewScheme()
+		gomega.Expect(redisv1alpha1.AddToScheme(s)).To(gomega.Succeed())
+
+		entries := []client.Object{
+			&redisv1alpha1.RedisEntry{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "entry-a",
+					Namespace: "default",
+					Labels:    map[string]string{"team": "a"},
+				},
+				Spec: redisv1alpha1.RedisEntrySpec{Key: "a", Value: "1"},
+			},
+			&redisv1alpha1.RedisEntry{
+				ObjectMeta: metav1.ObjectMeta{Name: "entry-b", Namespace: "default"},
+				Spec:       redisv1alpha1.RedisEntrySpec{Key: "b", Value: "2"},
+			},
+			&redisv1alpha1.RedisEntry{
+				ObjectMeta: metav1.ObjectMeta{Name: "entry-remote", Namespace: "default"},
+				Spec: redisv1alpha1.RedisEntrySpec{
+					Key:           "c",
+					Value:         "3",
+					ConnectionRef: &corev1.LocalObjectReference{Name: "cache"},
+				},
+			},
+		}
+		fakeClient := fake.NewClientBuilder().WithScheme(s).WithObjects(entries...).Build()
+
+		var mockRedis *redisv9.Client
+		mockRedis, mock = redismock.NewClientMock()
+		monitor = newHealthMonitor(fakeClient, mockRedis, time.Second)
+	})
+
+	ginkgo.AfterEach(func() {
+		gomega.Expect(mock.ExpectationsWereMet()).To(gomega.Succeed())
+	})
+
+	ginkgo.It("should not resync while Redis stays healthy", func() {
+		mock.ExpectPing().SetVal("PONG")
+		monitor.check(ctx)
+		gomega.Consistently(monitor.events, 100*time.Millisecond).ShouldNot(gomega.Receive())
+	})
+
+	ginkgo.It("should enqueue the entries of the default connection when Redis recovers", func() {
+		mock.ExpectPing().SetErr(errors.New("connection refused"))
+		monitor.check(ctx)
+		gomega.Expect(monitor.healthy).To(gomega.BeFalse())
+
+		mock.ExpectPing().SetVal("PONG")
+		go monitor.check(ctx)
+
+		var names []string
+		for range 2 {
+			var evt interface{ GetName() string }
+			gomega.Eventually(func() bool {
+				select {
+				case e := <-monitor.events:
+					evt = e.Object
+					return true
+				default:
+					return false
+				}
+			}).Should(gomega.BeTrue())
+			names = append(names, evt.GetName())
+		}
+		gomega.Expect(names).To(gomega.ConsistOf("entry-a", "entry-b"))
+		gomega.Consistently(monitor.events, 100*time.Millisecond).ShouldNot(gomega.Receive())
+	})
+
+	ginkgo.It("should only resync entries matching the selector", func() {
+		monitor.selector = labels.SelectorFromSet(labels.Set{"team": "a"})
+		monitor.healthy = false
+
+		mock.ExpectPing().SetVal("PONG")
+		go monitor.check(ctx)
+
+		var evt event.GenericEvent
+		gomega.Eventually(monitor.events).Should(gomega.Receive(&evt))
+		gomega.Expect(evt.Object.GetName()).To(gomega.Equal("entry-a"))
+		gomega.Consistently(monitor.events, 100*time.Millisecond).ShouldNot(gomega.Receive())
+	})
+
+	ginkgo.It("should resync when the marker key disappears", func() {
+		monitor.markerKey = DefaultMarkerKey
+		monitor.selector = labels.SelectorFromSet(labels.Set{"team": "a"})
+
+		// The first check only writes the marker
+		mock.ExpectPing().SetVal("PONG")
+		mock.ExpectExists(DefaultMarkerKey).SetVal(0)
+		mock.Regexp().ExpectSet(DefaultMarkerKey, ".+", 0).SetVal("OK")
+		monitor.check(ctx)
+		gomega.Expect(monitor.markerWritten).To(gomega.BeTrue())
+
+		mock.ExpectPing().SetVal("PONG")
+		mock.ExpectExists(DefaultMarkerKey).SetVal(1)
+		monitor.check(ctx)
+		gomega.Consistently(monitor.events, 100*time.Millisecond).ShouldNot(gomega.Receive())
+
+		// After a flush the marker is rewritten and entries resynced
+		mock.ExpectPing().SetVal("PONG")
+		mock.ExpectExists(DefaultMarkerKey).SetVal(0)
+		mock.Regexp().ExpectSet(DefaultMarkerKey, ".+", 0).SetVal("OK")
+		go monitor.check(ctx)
+
+		var evt event.GenericEvent
+		gomega.Eventually(monitor.events).Should(gomega.Receive(&evt))
+		gomega.Expect(evt.Object.GetName()).To(gomega.Equal("entry-a"))
+	})
+})
@@ -103,6 +103,16 @@ var _ = ginkgo.Describe("RedisEntry Controller Integration Tests", func() {
 		// Cleanup RedisEntry if it exists
 		if redisEntry != nil {
 			err := k8sClient.Delete(ctx, redisEntry)
+			if err == nil {
+				// The finalizer deletes the key before releasing the entry
+				mock.ExpectUnlink(redisEntry.Spec.Key).SetVal(1)
+				_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+					NamespacedName: client.ObjectKeyFromObject(redisEntry),
+				})
+				gomega.Expect(err).NotTo(gomega.HaveOccurred())
+				err = k8sClient.Get(ctx, client.ObjectKeyFromObject(redisEntry), &redisv1alpha1.RedisEntry{})
+				gomega.Expect(apierrors.IsNotFound(err)).To(gomega.BeTrue())
+			}
 			if err != nil && !apierrors.IsNotFound(err) {
 				gomega.Expect(err).NotTo(gomega.HaveOccurred())
 			}
@@ -184,7 +194,7 @@ var _ = ginkgo.Describe("RedisEntry Controller Integration Tests", func() {
 					Namespace: "default",
 				},
 			})
-			gomega.Expect(err).To(gomega.HaveOccurred())
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
 
 			// Verify error status was set
 			updatedEntry := &redisv1alpha1.RedisEntry{}
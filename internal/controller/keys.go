@@ -0,0 +1,80 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+)
+
+// DefaultMaxKeyLength is the longest key accepted unless configured otherwise.
+const DefaultMaxKeyLength = 1024
+
+// KeyPolicy restricts the syntax of keys the controller writes, so keys stay
+// safe to use in tooling that splits on newlines or treats NUL as a
+// terminator.
+type KeyPolicy struct {
+	// MaxLength is the maximum key length in bytes; 0 means DefaultMaxKeyLength
+	MaxLength int
+
+	// Pattern, when set, must match every key
+	Pattern *regexp.Regexp
+}
+
+// validate returns why a key is unacceptable, or nil.
+func (p KeyPolicy) validate(key string) error {
+	if i := strings.IndexAny(key, "\r\n\x00"); i >= 0 {
+		return fmt.Errorf("key %q contains a control character at position %d", key, i)
+	}
+	maxLength := p.MaxLength
+	if maxLength <= 0 {
+		maxLength = DefaultMaxKeyLength
+	}
+	if len(key) > maxLength {
+		return fmt.Errorf("key %.32q... is %d bytes long, the limit is %d", key, len(key), maxLength)
+	}
+	if p.Pattern != nil && !p.Pattern.MatchString(key) {
+		return fmt.Errorf("key %q does not match the allowed pattern %s", key, p.Pattern)
+	}
+	return nil
+}
+
+// invalidKey validates every key declared by the entry and returns the first
+// problem found.
+func (r *RedisEntryReconciler) invalidKey(redisEntry *redisv1alpha1.RedisEntry) error {
+	if key, ok := checksumKey(redisEntry); ok {
+		if _, taken := redisEntry.Spec.Entries[key]; taken {
+			return fmt.Errorf("key %q is used for the checksum and cannot be declared in entries", key)
+		}
+	}
+	if redisEntry.Spec.ChunkSizeBytes != nil {
+		for key := range redisEntry.Spec.Entries {
+			if strings.HasPrefix(key, redisEntry.Spec.Key+chunkKeyInfix) {
+				return fmt.Errorf("key %q is reserved for chunks and cannot be declared in entries", key)
+			}
+		}
+	}
+	for _, key := range entryKeys(redisEntry) {
+		if err := r.KeyPolicy.validate(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
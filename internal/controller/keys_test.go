@@ -0,0 +1,31 @@
+package controller
+
+import (
+	"regexp"
+	"strings"
+
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+)
+
+var _ = ginkgo.Describe("Key Policy", func() {
+	ginkgo.It("should reject control characters", func() {
+		var policy KeyPolicy
+		gomega.Expect(policy.validate("app:config")).To(gomega.Succeed())
+		gomega.Expect(policy.validate("app\nconfig")).To(gomega.HaveOccurred())
+		gomega.Expect(policy.validate("app\rconfig")).To(gomega.HaveOccurred())
+		gomega.Expect(policy.validate("app\x00config")).To(gomega.HaveOccurred())
+	})
+
+	ginkgo.It("should enforce the maximum length", func() {
+		gomega.Expect(KeyPolicy{}.validate(strings.Repeat("k", DefaultMaxKeyLength))).To(gomega.Succeed())
+		gomega.Expect(KeyPolicy{}.validate(strings.Repeat("k", DefaultMaxKeyLength+1))).To(gomega.HaveOccurred())
+		gomega.Expect(KeyPolicy{MaxLength: 8}.validate("app:config")).To(gomega.HaveOccurred())
+	})
+
+	ginkgo.It("should enforce the optional pattern", func() {
+		policy := KeyPolicy{Pattern: regexp.MustCompile(`^[a-z0-9:_-]+$`)}
+		gomega.Expect(policy.validate("app:config")).To(gomega.Succeed())
+		gomega.Expect(policy.validate("App Config")).To(gomega.MatchError(gomega.ContainSubstring("does not match")))
+	})
+})
@@ -0,0 +1,287 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	redisv9 "github.com/redis/go-redis/v9"
+)
+
+// KVStore is the key-value backend entries are synced to. The reconciler
+// decides which keys to read and write; the store decides which commands do
+// it. redisStore implements it on top of go-redis.
+type KVStore interface {
+	// Get returns the string values of keys, with nil for missing keys.
+	Get(ctx context.Context, keys ...string) ([]*string, error)
+
+	// StrLen returns the length of a string value, or 0 for a missing key.
+	StrLen(ctx context.Context, key string) (int64, error)
+
+	// TTL returns the remaining time to live of keys: -1 for keys without an
+	// expiry and -2 for missing keys, like PTTL.
+	TTL(ctx context.Context, keys ...string) ([]time.Duration, error)
+
+	// Set writes a single key; a zero ttl means no expiry.
+	Set(ctx context.Context, key, value string, ttl time.Duration) error
+
+	// Del removes keys.
+	Del(ctx context.Context, keys ...string) error
+
+	// Pipeline sends the writes queued by fn in one round trip, inside a
+	// transaction when atomic is set and the store supports transactions.
+	// It reports whether the writes were applied atomically.
+	Pipeline(ctx context.Context, atomic bool, fn func(KVPipe)) (bool, error)
+
+	// CrossSlot reports whether keys are spread over shards, so they cannot
+	// be written by one multi-key command or transaction.
+	CrossSlot(keys ...string) bool
+}
+
+// KVPipe queues writes for KVStore.Pipeline.
+type KVPipe interface {
+	Set(key, value string, ttl time.Duration)
+	// MSet writes key/value pairs without an expiry.
+	MSet(pairs ...string)
+	Expire(key string, ttl time.Duration)
+	Del(keys ...string)
+}
+
+// KeyError is the failed write of a single key.
+type KeyError struct {
+	Key string
+	Err error
+}
+
+// WriteError is returned by KVStore.Pipeline when some of the queued writes
+// failed and others were applied.
+type WriteError struct {
+	// Failed lists the keys whose writes failed
+	Failed []KeyError
+
+	// Keys is the number of keys the pipeline wrote to
+	Keys int
+}
+
+func (e *WriteError) Error() string {
+	failures := make([]string, len(e.Failed))
+	for i, failure := range e.Failed {
+		failures[i] = fmt.Sprintf("%s: %v", failure.Key, failure.Err)
+	}
+	return fmt.Sprintf("failed to write %d of %d keys: %s", len(e.Failed), e.Keys, strings.Join(failures, "; "))
+}
+
+func (e *WriteError) Unwrap() []error {
+	errs := make([]error, len(e.Failed))
+	for i, failure := range e.Failed {
+		errs[i] = failure.Err
+	}
+	return errs
+}
+
+// redisStore is a KVStore backed by a go-redis client.
+type redisStore struct {
+	client redisv9.UniversalClient
+
+	// proxy sends transactions as plain pipelines, since Redis proxies don't
+	// forward MULTI/EXEC
+	proxy bool
+
+	// unlink deletes keys with the non-blocking UNLINK
+	unlink bool
+}
+
+// store returns the KVStore for a Redis client, using the commands its server
+// and ProxyMode allow. A nil server is assumed to support every command.
+func (r *RedisEntryReconciler) store(redisClient redisv9.UniversalClient, server *ServerInfo) KVStore {
+	return &redisStore{
+		client: redisClient,
+		proxy:  r.ProxyMode,
+		// Proxies generally don't forward UNLINK either
+		unlink: !r.ProxyMode && server.Supports(CapabilityUnlink),
+	}
+}
+
+func (s *redisStore) Get(ctx context.Context, keys ...string) ([]*string, error) {
+	if s.CrossSlot(keys...) {
+		return s.getEach(ctx, keys)
+	}
+	values, err := s.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*string, len(values))
+	for i, value := range values {
+		if value, ok := value.(string); ok {
+			result[i] = &value
+		}
+	}
+	return result, nil
+}
+
+// getEach reads keys with one GET each, for keys MGET can't read together.
+func (s *redisStore) getEach(ctx context.Context, keys []string) ([]*string, error) {
+	cmds := make([]*redisv9.StringCmd, len(keys))
+	_, err := s.client.Pipelined(ctx, func(pipe redisv9.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = pipe.Get(ctx, key)
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, redisv9.Nil) {
+		return nil, err
+	}
+	result := make([]*string, len(keys))
+	for i, cmd := range cmds {
+		if value, err := cmd.Result(); err == nil {
+			result[i] = &value
+		} else if !errors.Is(err, redisv9.Nil) {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func (s *redisStore) StrLen(ctx context.Context, key string) (int64, error) {
+	return s.client.StrLen(ctx, key).Result()
+}
+
+func (s *redisStore) TTL(ctx context.Context, keys ...string) ([]time.Duration, error) {
+	cmds := make([]*redisv9.DurationCmd, len(keys))
+	_, err := s.client.Pipelined(ctx, func(pipe redisv9.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = pipe.PTTL(ctx, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	ttls := make([]time.Duration, len(keys))
+	for i, cmd := range cmds {
+		ttls[i] = cmd.Val()
+	}
+	return ttls, nil
+}
+
+func (s *redisStore) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	return s.client.Set(ctx, key, value, ttl).Err()
+}
+
+func (s *redisStore) Del(ctx context.Context, keys ...string) error {
+	if s.unlink {
+		return s.client.Unlink(ctx, keys...).Err()
+	}
+	return s.client.Del(ctx, keys...).Err()
+}
+
+func (s *redisStore) Pipeline(ctx context.Context, atomic bool, fn func(KVPipe)) (bool, error) {
+	pipelined := s.client.Pipelined
+	atomic = atomic && !s.proxy
+	if atomic {
+		pipelined = s.client.TxPipelined
+	}
+	var queued *redisPipe
+	cmds, err := pipelined(ctx, func(pipe redisv9.Pipeliner) error {
+		queued = &redisPipe{ctx: ctx, pipe: pipe}
+		fn(queued)
+		return nil
+	})
+	if err != nil && queued != nil {
+		err = pipelineError(err, cmds, queued.keys)
+	}
+	return atomic, err
+}
+
+// pipelineError attributes the failed commands of a pipeline to the keys they
+// wrote. When no command or every command failed, such as when the
+// connection broke, err is returned as is.
+func pipelineError(err error, cmds []redisv9.Cmder, keys [][]string) error {
+	writeErr := &WriteError{}
+	seen := map[string]bool{}
+	failed := 0
+	for i, cmd := range cmds {
+		if i >= len(keys) {
+			break
+		}
+		for _, key := range keys[i] {
+			if !seen[key] {
+				seen[key] = true
+				writeErr.Keys++
+			}
+		}
+		if cmd.Err() == nil {
+			continue
+		}
+		failed++
+		for _, key := range keys[i] {
+			if !slices.ContainsFunc(writeErr.Failed, func(f KeyError) bool { return f.Key == key }) {
+				writeErr.Failed = append(writeErr.Failed, KeyError{Key: key, Err: cmd.Err()})
+			}
+		}
+	}
+	if failed == 0 || failed == len(cmds) {
+		return err
+	}
+	return writeErr
+}
+
+func (s *redisStore) CrossSlot(keys ...string) bool {
+	return isCluster(s.client) && len(keys) > 1 && !sameSlot(keys)
+}
+
+// redisPipe queues writes on a go-redis pipeline.
+type redisPipe struct {
+	ctx  context.Context
+	pipe redisv9.Pipeliner
+
+	// keys holds the keys written by each queued command
+	keys [][]string
+}
+
+func (p *redisPipe) Set(key, value string, ttl time.Duration) {
+	p.pipe.Set(p.ctx, key, value, ttl)
+	p.keys = append(p.keys, []string{key})
+}
+
+func (p *redisPipe) MSet(pairs ...string) {
+	values := make([]interface{}, len(pairs))
+	keys := make([]string, 0, len(pairs)/2)
+	for i, v := range pairs {
+		values[i] = v
+		if i%2 == 0 {
+			keys = append(keys, v)
+		}
+	}
+	p.pipe.MSet(p.ctx, values...)
+	p.keys = append(p.keys, keys)
+}
+
+func (p *redisPipe) Expire(key string, ttl time.Duration) {
+	p.pipe.Expire(p.ctx, key, ttl)
+	p.keys = append(p.keys, []string{key})
+}
+
+func (p *redisPipe) Del(keys ...string) {
+	p.pipe.Del(p.ctx, keys...)
+	p.keys = append(p.keys, keys)
+}
@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	redismock "github.com/go-redis/redismock/v9"
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	redisv9 "github.com/redis/go-redis/v9"
+)
+
+// mapStore is an in-memory KVStore without transactions.
+type mapStore map[string]string
+
+func (m mapStore) Get(_ context.Context, keys ...string) ([]*string, error) {
+	values := make([]*string, len(keys))
+	for i, key := range keys {
+		if value, ok := m[key]; ok {
+			values[i] = &value
+		}
+	}
+	return values, nil
+}
+
+func (m mapStore) StrLen(_ context.Context, key string) (int64, error) {
+	return int64(len(m[key])), nil
+}
+
+func (m mapStore) TTL(_ context.Context, keys ...string) ([]time.Duration, error) {
+	ttls := make([]time.Duration, len(keys))
+	for i := range ttls {
+		ttls[i] = -1
+	}
+	return ttls, nil
+}
+
+func (m mapStore) Set(_ context.Context, key, value string, _ time.Duration) error {
+	m[key] = value
+	return nil
+}
+
+func (m mapStore) Del(_ context.Context, keys ...string) error {
+	for _, key := range keys {
+		delete(m, key)
+	}
+	return nil
+}
+
+func (m mapStore) Pipeline(_ context.Context, _ bool, fn func(KVPipe)) (bool, error) {
+	fn(mapPipe(m))
+	return false, nil
+}
+
+func (m mapStore) CrossSlot(...string) bool {
+	return false
+}
+
+type mapPipe mapStore
+
+func (p mapPipe) Set(key, value string, _ time.Duration) { p[key] = value }
+func (p mapPipe) Expire(string, time.Duration)           {}
+
+func (p mapPipe) MSet(pairs ...string) {
+	for i := 0; i+1 < len(pairs); i += 2 {
+		p[pairs[i]] = pairs[i+1]
+	}
+}
+
+func (p mapPipe) Del(keys ...string) {
+	for _, key := range keys {
+		delete(p, key)
+	}
+}
+
+var _ = ginkgo.Describe("KV Store", func() {
+	ginkgo.It("should sync entries to any store implementation", func() {
+		ctx := context.Background()
+		store := mapStore{}
+		entry := &redisv1alpha1.RedisEntry{
+			Spec: redisv1alpha1.RedisEntrySpec{
+				Key:     "app:config",
+				Value:   "v",
+				Entries: map[string]string{"app:config:meta": "m"},
+			},
+		}
+
+		r := &RedisEntryReconciler{}
+		gomega.Expect(r.writeEntry(ctx, store, entry, "v", 0)).To(gomega.Succeed())
+		gomega.Expect(store).To(gomega.Equal(mapStore{"app:config": "v", "app:config:meta": "m"}))
+		// The store has no transactions
+		gomega.Expect(entry.Status.WriteMode).To(gomega.Equal(redisv1alpha1.WriteModePipeline))
+
+		gomega.Expect(r.deleteEntry(ctx, store, entry)).To(gomega.Succeed())
+		gomega.Expect(store).To(gomega.BeEmpty())
+	})
+})
+
+var _ = ginkgo.Describe("Partial Write Errors", func() {
+	ginkgo.It("should report the keys a pipelined write failed on", func() {
+		ctx := context.Background()
+		clusterClient, mock := redismock.NewClusterMock()
+		entry := &redisv1alpha1.RedisEntry{
+			Spec: redisv1alpha1.RedisEntrySpec{
+				Key:     "foo",
+				Value:   "1",
+				Entries: map[string]string{"somekey": "2"},
+			},
+		}
+
+		mock.ExpectSet("foo", "1", 0).SetVal("OK")
+		mock.ExpectSet("somekey", "2", 0).SetErr(errors.New("OOM command not allowed"))
+		r := &RedisEntryReconciler{}
+		err := r.writeEntry(ctx, r.store(clusterClient, nil), entry, "1", 0)
+		gomega.Expect(err).To(gomega.MatchError("failed to write 1 of 2 keys: somekey: OOM command not allowed"))
+		gomega.Expect(failedKeys(err)).To(gomega.Equal([]redisv1alpha1.KeyFailure{
+			{Key: "somekey", Error: "OOM command not allowed"},
+		}))
+
+		gomega.Expect(mock.ExpectationsWereMet()).To(gomega.Succeed())
+	})
+
+	ginkgo.It("should pass on errors that affect every key", func() {
+		ctx := context.Background()
+		refused := errors.New("connection refused")
+		cmds := []redisv9.Cmder{redisv9.NewStatusCmd(ctx), redisv9.NewStatusCmd(ctx)}
+		for _, cmd := range cmds {
+			cmd.SetErr(refused)
+		}
+		err := pipelineError(refused, cmds, [][]string{{"foo"}, {"somekey"}})
+		gomega.Expect(err).To(gomega.BeIdenticalTo(refused))
+		gomega.Expect(failedKeys(err)).To(gomega.BeNil())
+	})
+})
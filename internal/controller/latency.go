@@ -0,0 +1,111 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+
+	redisv9 "github.com/redis/go-redis/v9"
+)
+
+// latencySamples is the number of recent PING round trips the latency
+// quantiles are computed over; at the default health check interval this
+// covers the last ten minutes.
+const latencySamples = 60
+
+// latencyWindow keeps the most recent PING round trips. It is only accessed
+// from the health monitor goroutine.
+type latencyWindow struct {
+	samples []time.Duration
+	next    int
+}
+
+// add records a round trip, replacing the oldest once the window is full.
+func (w *latencyWindow) add(rtt time.Duration) {
+	if len(w.samples) < latencySamples {
+		w.samples = append(w.samples, rtt)
+		return
+	}
+	w.samples[w.next] = rtt
+	w.next = (w.next + 1) % latencySamples
+}
+
+// quantiles returns the given quantiles of the recorded round trips, using
+// the nearest-rank method.
+func (w *latencyWindow) quantiles(qs ...float64) []time.Duration {
+	sorted := slices.Clone(w.samples)
+	slices.Sort(sorted)
+	values := make([]time.Duration, len(qs))
+	if len(sorted) == 0 {
+		return values
+	}
+	for i, q := range qs {
+		rank := int(math.Ceil(q * float64(len(sorted))))
+		values[i] = sorted[min(max(rank, 1), len(sorted))-1]
+	}
+	return values
+}
+
+// latencyEvent is an entry of the LATENCY LATEST reply.
+type latencyEvent struct {
+	name   string
+	latest time.Duration
+	max    time.Duration
+}
+
+// latestLatency reads the latest spikes recorded by the server's latency
+// monitor. The reply is empty unless latency-monitor-threshold is set.
+func latestLatency(ctx context.Context, redisClient redisv9.UniversalClient) ([]latencyEvent, error) {
+	reply, err := redisClient.Do(ctx, "LATENCY", "LATEST").Slice()
+	if err != nil {
+		return nil, err
+	}
+	events := make([]latencyEvent, 0, len(reply))
+	for _, raw := range reply {
+		// Each event is [name, unix time, latest ms, max ms]
+		fields, ok := raw.([]interface{})
+		if !ok || len(fields) < 4 {
+			return nil, fmt.Errorf("unexpected LATENCY LATEST entry %v", raw)
+		}
+		name, _ := fields[0].(string)
+		latest, err1 := millis(fields[2])
+		maximum, err2 := millis(fields[3])
+		if name == "" || err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("unexpected LATENCY LATEST entry %v", raw)
+		}
+		events = append(events, latencyEvent{name: name, latest: latest, max: maximum})
+	}
+	return events, nil
+}
+
+// millis converts a millisecond count from a RESP reply to a duration.
+func millis(value interface{}) (time.Duration, error) {
+	switch v := value.(type) {
+	case int64:
+		return time.Duration(v) * time.Millisecond, nil
+	case string:
+		n, err := strconv.ParseInt(v, 10, 64)
+		return time.Duration(n) * time.Millisecond, err
+	default:
+		return 0, fmt.Errorf("unexpected millisecond value %v", value)
+	}
+}
@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	redismock "github.com/go-redis/redismock/v9"
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+var _ = ginkgo.Describe("Redis Latency", func() {
+	ginkgo.It("should compute quantiles over the most recent round trips", func() {
+		var w latencyWindow
+		gomega.Expect(w.quantiles(0.5)).To(gomega.Equal([]time.Duration{0}))
+
+		for i := 1; i <= 100; i++ {
+			w.add(time.Duration(i) * time.Millisecond)
+		}
+		// Only the last latencySamples round trips, 41ms to 100ms, are kept
+		gomega.Expect(w.samples).To(gomega.HaveLen(latencySamples))
+		gomega.Expect(w.quantiles(0.5, 0.99)).To(gomega.Equal([]time.Duration{
+			70 * time.Millisecond, 100 * time.Millisecond,
+		}))
+	})
+
+	ginkgo.It("should export ping quantiles and latency monitor events", func() {
+		mockRedis, mock := redismock.NewClientMock()
+		m := NewMetrics(MetricsOptions{})
+		monitor := newHealthMonitor(nil, mockRedis, time.Second)
+		monitor.metrics = m
+		monitor.latencyLatest = true
+
+		mock.ExpectDo("LATENCY", "LATEST").SetVal([]interface{}{
+			[]interface{}{"command", int64(1700000000), int64(250), int64(900)},
+		})
+		monitor.recordLatency(context.Background(), 2*time.Millisecond)
+		gomega.Expect(mock.ExpectationsWereMet()).To(gomega.Succeed())
+
+		gomega.Expect(testutil.ToFloat64(m.pingLatency.WithLabelValues("default", "0.99"))).To(gomega.Equal(0.002))
+		gomega.Expect(testutil.ToFloat64(m.redisLatency.WithLabelValues("default", "command"))).To(gomega.Equal(0.25))
+	})
+
+	ginkgo.It("should reject malformed latency monitor replies", func() {
+		mockRedis, mock := redismock.NewClientMock()
+		mock.ExpectDo("LATENCY", "LATEST").SetVal([]interface{}{[]interface{}{"command"}})
+		_, err := latestLatency(context.Background(), mockRedis)
+		gomega.Expect(err).To(gomega.HaveOccurred())
+	})
+})
@@ -0,0 +1,65 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// listPageSize is the number of RedisEntries fetched per page when listing
+// from the API server.
+const listPageSize = 500
+
+// forEachEntry calls fn for every RedisEntry matching opts. With an
+// apiReader the entries are fetched from the API server in pages, so only one
+// page is held in memory at a time. The manager's cache doesn't support
+// continuation, so without an apiReader a single List is served from cache.
+func forEachEntry(ctx context.Context, cached, apiReader client.Reader,
+	fn func(*redisv1alpha1.RedisEntry) error, opts ...client.ListOption) error {
+	if apiReader == nil {
+		entries := &redisv1alpha1.RedisEntryList{}
+		if err := cached.List(ctx, entries, opts...); err != nil {
+			return err
+		}
+		for i := range entries.Items {
+			if err := fn(&entries.Items[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	continueToken := ""
+	for {
+		entries := &redisv1alpha1.RedisEntryList{}
+		pageOpts := append([]client.ListOption{client.Limit(listPageSize), client.Continue(continueToken)}, opts...)
+		if err := apiReader.List(ctx, entries, pageOpts...); err != nil {
+			return err
+		}
+		for i := range entries.Items {
+			if err := fn(&entries.Items[i]); err != nil {
+				return err
+			}
+		}
+		if continueToken = entries.Continue; continueToken == "" {
+			return nil
+		}
+	}
+}
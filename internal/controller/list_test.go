@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"context"
+	"strconv"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// pagingReader serves RedisEntry lists one entry per page and records the
+// page requests it received.
+type pagingReader struct {
+	client.Reader
+	entries []redisv1alpha1.RedisEntry
+	pages   []*client.ListOptions
+}
+
+func (p *pagingReader) List(_ context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	listOpts := &client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+	p.pages = append(p.pages, listOpts)
+
+	start := 0
+	if listOpts.Continue != "" {
+		start, _ = strconv.Atoi(listOpts.Continue)
+	}
+	entries := list.(*redisv1alpha1.RedisEntryList)
+	entries.Items = p.entries[start : start+1]
+	if start+1 < len(p.entries) {
+		entries.Continue = strconv.Itoa(start + 1)
+	}
+	return nil
+}
+
+var _ = ginkgo.Describe("Paginated Entry Listing", func() {
+	var (
+		ctx     context.Context
+		entries []redisv1alpha1.RedisEntry
+		cached  client.Client
+	)
+
+	ginkgo.BeforeEach(func() {
+		ctx = context.Background()
+		entries = nil
+		for _, name := range []string{"a", "b", "c"} {
+			entries = append(entries, redisv1alpha1.RedisEntry{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+				Spec:       redisv1alpha1.RedisEntrySpec{Key: name, Value: name},
+			})
+		}
+		s := runtime.NewScheme()
+		gomega.Expect(redisv1alpha1.AddToScheme(s)).To(gomega.Succeed())
+		cached = fake.NewClientBuilder().WithScheme(s).WithObjects(&entries[0]).Build()
+	})
+
+	// names collects the entry names visited by forEachEntry
+	names := func(apiReader client.Reader) []string {
+		var visited []string
+		gomega.Expect(forEachEntry(ctx, cached, apiReader, func(entry *redisv1alpha1.RedisEntry) error {
+			visited = append(visited, entry.Name)
+			return nil
+		})).To(gomega.Succeed())
+		return visited
+	}
+
+	ginkgo.It("should follow continue tokens on the API reader", func() {
+		reader := &pagingReader{entries: entries}
+		gomega.Expect(names(reader)).To(gomega.Equal([]string{"a", "b", "c"}))
+
+		gomega.Expect(reader.pages).To(gomega.HaveLen(3))
+		gomega.Expect(reader.pages[0].Limit).To(gomega.Equal(int64(listPageSize)))
+		gomega.Expect(reader.pages[0].Continue).To(gomega.BeEmpty())
+		gomega.Expect(reader.pages[2].Continue).To(gomega.Equal("2"))
+	})
+
+	ginkgo.It("should list from the cache without an API reader", func() {
+		gomega.Expect(names(nil)).To(gomega.Equal([]string{"a"}))
+	})
+})
@@ -0,0 +1,292 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sync"
+	"time"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	metricsNamespace = "redisctrl"
+
+	// defaultConnectionName names the controller's Redis connection when no
+	// name is configured
+	defaultConnectionName = "default"
+
+	// Sync results
+	resultSuccess = "success"
+	resultError   = "error"
+)
+
+// MetricsOptions controls which high-cardinality labels are attached to the
+// controller's custom metrics. Both are off by default so that large
+// installations don't create one series per entry or key.
+type MetricsOptions struct {
+	// PerEntryLabels adds namespace and name labels identifying the RedisEntry.
+	PerEntryLabels bool
+
+	// PerKeyLabels adds a key label carrying the Redis key.
+	PerKeyLabels bool
+
+	// MaxTTLSeries caps the number of keys whose remaining TTL is exported;
+	// 0 disables the TTL gauge.
+	MaxTTLSeries int
+
+	// ConnectionName is the connection label of series about the
+	// controller's own Redis server, telling apart controllers that manage
+	// different servers. Empty means "default". Series about entries with a
+	// connectionRef are labeled with their RedisConnection instead.
+	ConnectionName string
+}
+
+// Metrics holds the custom Prometheus collectors exported by the controller.
+// A nil *Metrics is valid and records nothing.
+type Metrics struct {
+	opts MetricsOptions
+
+	syncTotal    *prometheus.CounterVec
+	syncDuration *prometheus.HistogramVec
+	driftTotal   *prometheus.CounterVec
+	lastDrift    *prometheus.GaugeVec
+	ttlRemaining *prometheus.GaugeVec
+	datasetLoss  *prometheus.CounterVec
+	pingLatency  *prometheus.GaugeVec
+	redisLatency *prometheus.GaugeVec
+	backlog      *prometheus.GaugeVec
+
+	// ttlSeries remembers the labels of each entry's TTL series so they can
+	// be removed, and bounds their number
+	ttlMu     sync.Mutex
+	ttlSeries map[types.NamespacedName][]string
+
+	// queued remembers why each entry in the backlog was queued, and
+	// connections the connection label of each entry seen by the watch
+	backlogMu   sync.Mutex
+	queued      map[types.NamespacedName]backlogItem
+	connections map[types.NamespacedName]string
+}
+
+// NewMetrics creates the controller's collectors with the label set selected
+// by opts.
+func NewMetrics(opts MetricsOptions) *Metrics {
+	m := &Metrics{opts: opts}
+	m.syncTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "entry_syncs_total",
+		Help:      "Total number of RedisEntry sync attempts by result.",
+	}, m.labelNames("connection", "result"))
+	m.syncDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "entry_sync_duration_seconds",
+		Help:      "Duration of RedisEntry writes to Redis.",
+		Buckets:   prometheus.DefBuckets,
+	}, m.labelNames("connection"))
+	m.driftTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "drift_detected_total",
+		Help:      "Total number of times Redis was found to differ from an already synced entry.",
+	}, m.labelNames("connection"))
+	m.lastDrift = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "last_drift_timestamp_seconds",
+		Help:      "Unix time of the most recent drift detected on a connection.",
+	}, []string{"connection"})
+	m.ttlRemaining = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "key_ttl_remaining_seconds",
+		Help:      "Remaining TTL of a managed key with an expiry, sampled on resync. 0 means the key has expired.",
+	}, []string{"connection", "namespace", "name", "key"})
+	m.datasetLoss = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "dataset_loss_detected_total",
+		Help:      "Total number of times the marker key vanished from Redis, triggering a full resync.",
+	}, []string{"connection"})
+	m.pingLatency = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "redis_ping_latency_seconds",
+		Help:      "Quantiles of the PING round trip over the most recent health checks.",
+	}, []string{"connection", "quantile"})
+	m.redisLatency = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "redis_latency_latest_seconds",
+		Help:      "Latest latency spike per event recorded by the Redis latency monitor (LATENCY LATEST).",
+	}, []string{"connection", "event"})
+	m.backlog = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "entry_backlog",
+		Help:      "Number of RedisEntries waiting to be reconciled, by the reason they were queued: spec, drift, retry or other.",
+	}, []string{"connection", "reason"})
+	m.ttlSeries = map[types.NamespacedName][]string{}
+	m.queued = map[types.NamespacedName]backlogItem{}
+	m.connections = map[types.NamespacedName]string{}
+	return m
+}
+
+// Register adds the collectors to the given registry.
+func (m *Metrics) Register(registry prometheus.Registerer) error {
+	for _, c := range []prometheus.Collector{m.syncTotal, m.syncDuration, m.driftTotal, m.lastDrift, m.ttlRemaining, m.datasetLoss, m.pingLatency, m.redisLatency, m.backlog} {
+		if err := registry.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordSync counts a sync attempt and observes its duration.
+func (m *Metrics) recordSync(redisEntry *redisv1alpha1.RedisEntry, result string, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	connection := m.entryConnection(redisEntry)
+	m.syncTotal.WithLabelValues(m.labelValues(redisEntry, connection, result)...).Inc()
+	m.syncDuration.WithLabelValues(m.labelValues(redisEntry, connection)...).Observe(duration.Seconds())
+}
+
+// recordDrift counts a drift detection and stamps the connection's last drift time.
+func (m *Metrics) recordDrift(redisEntry *redisv1alpha1.RedisEntry, at time.Time) {
+	if m == nil {
+		return
+	}
+	connection := m.entryConnection(redisEntry)
+	m.driftTotal.WithLabelValues(m.labelValues(redisEntry, connection)...).Inc()
+	m.lastDrift.WithLabelValues(connection).Set(float64(at.Unix()))
+}
+
+// recordDatasetLoss counts a detected loss of the Redis dataset.
+func (m *Metrics) recordDatasetLoss() {
+	if m == nil {
+		return
+	}
+	m.datasetLoss.WithLabelValues(m.connection()).Inc()
+}
+
+// recordPingLatency exports the median and 99th percentile PING round trip.
+func (m *Metrics) recordPingLatency(p50, p99 time.Duration) {
+	if m == nil {
+		return
+	}
+	m.pingLatency.WithLabelValues(m.connection(), "0.5").Set(p50.Seconds())
+	m.pingLatency.WithLabelValues(m.connection(), "0.99").Set(p99.Seconds())
+}
+
+// recordRedisLatency exports the latest spike of each latency monitor event.
+func (m *Metrics) recordRedisLatency(events []latencyEvent) {
+	if m == nil {
+		return
+	}
+	for _, event := range events {
+		m.redisLatency.WithLabelValues(m.connection(), event.name).Set(event.latest.Seconds())
+	}
+}
+
+// connection returns the connection label value.
+func (m *Metrics) connection() string {
+	return connectionName(m.opts.ConnectionName)
+}
+
+// entryConnection returns the connection label of an entry: its
+// RedisConnection as namespace/name, or the controller's own connection.
+func (m *Metrics) entryConnection(redisEntry *redisv1alpha1.RedisEntry) string {
+	if ref := redisEntry.Spec.ConnectionRef; ref != nil {
+		return redisEntry.Namespace + "/" + ref.Name
+	}
+	return m.connection()
+}
+
+// connectionName returns name, or the default connection name when it is empty.
+func connectionName(name string) string {
+	if name == "" {
+		return defaultConnectionName
+	}
+	return name
+}
+
+// tracksTTL reports whether remaining TTLs should be sampled.
+func (m *Metrics) tracksTTL() bool {
+	return m != nil && m.opts.MaxTTLSeries > 0
+}
+
+// recordTTL exports the remaining TTL of an entry's key, as returned by PTTL.
+// Keys without an expiry have no series. Once MaxTTLSeries keys are tracked,
+// further keys are not exported.
+func (m *Metrics) recordTTL(redisEntry *redisv1alpha1.RedisEntry, ttl time.Duration) {
+	if !m.tracksTTL() {
+		return
+	}
+	name := types.NamespacedName{Namespace: redisEntry.Namespace, Name: redisEntry.Name}
+	// PTTL returns -1 for keys without an expiry and -2 for missing keys
+	if ttl == -1 {
+		m.forgetTTL(name)
+		return
+	}
+
+	m.ttlMu.Lock()
+	defer m.ttlMu.Unlock()
+	labels := []string{m.entryConnection(redisEntry), redisEntry.Namespace, redisEntry.Name, redisEntry.Spec.Key}
+	if previous, ok := m.ttlSeries[name]; ok {
+		if previous[0] != labels[0] || previous[3] != labels[3] {
+			m.ttlRemaining.DeleteLabelValues(previous...)
+		}
+	} else if len(m.ttlSeries) >= m.opts.MaxTTLSeries {
+		return
+	}
+	m.ttlSeries[name] = labels
+	m.ttlRemaining.WithLabelValues(labels...).Set(max(ttl, 0).Seconds())
+}
+
+// forgetTTL removes the TTL series of an entry.
+func (m *Metrics) forgetTTL(name types.NamespacedName) {
+	if !m.tracksTTL() {
+		return
+	}
+	m.ttlMu.Lock()
+	defer m.ttlMu.Unlock()
+	if labels, ok := m.ttlSeries[name]; ok {
+		m.ttlRemaining.DeleteLabelValues(labels...)
+		delete(m.ttlSeries, name)
+	}
+}
+
+// labelNames returns the given base labels followed by the optional
+// per-entry and per-key labels.
+func (m *Metrics) labelNames(base ...string) []string {
+	names := append([]string{}, base...)
+	if m.opts.PerEntryLabels {
+		names = append(names, "namespace", "name")
+	}
+	if m.opts.PerKeyLabels {
+		names = append(names, "key")
+	}
+	return names
+}
+
+// labelValues mirrors labelNames for a specific entry.
+func (m *Metrics) labelValues(redisEntry *redisv1alpha1.RedisEntry, base ...string) []string {
+	values := append([]string{}, base...)
+	if m.opts.PerEntryLabels {
+		values = append(values, redisEntry.Namespace, redisEntry.Name)
+	}
+	if m.opts.PerKeyLabels {
+		values = append(values, redisEntry.Spec.Key)
+	}
+	return values
+}
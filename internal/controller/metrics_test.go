@@ -0,0 +1,175 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = ginkgo.Describe("Controller Metrics", func() {
+	var entry *redisv1alpha1.RedisEntry
+
+	ginkgo.BeforeEach(func() {
+		entry = &redisv1alpha1.RedisEntry{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "metrics-entry",
+				Namespace: "default",
+			},
+			Spec: redisv1alpha1.RedisEntrySpec{
+				Key:   "metrics-key",
+				Value: "metrics-value",
+			},
+		}
+	})
+
+	// labelsOf gathers the registry and returns the label names of the sync counter
+	labelsOf := func(registry *prometheus.Registry) []string {
+		families, err := registry.Gather()
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		for _, family := range families {
+			if family.GetName() != "redisctrl_entry_syncs_total" {
+				continue
+			}
+			var names []string
+			for _, label := range family.GetMetric()[0].GetLabel() {
+				names = append(names, label.GetName())
+			}
+			return names
+		}
+		return nil
+	}
+
+	ginkgo.It("should omit per-entry and per-key labels by default", func() {
+		registry := prometheus.NewRegistry()
+		m := NewMetrics(MetricsOptions{})
+		gomega.Expect(m.Register(registry)).To(gomega.Succeed())
+
+		m.recordSync(entry, resultSuccess, time.Millisecond)
+		gomega.Expect(labelsOf(registry)).To(gomega.ConsistOf("connection", "result"))
+	})
+
+	ginkgo.It("should add entry and key labels when enabled", func() {
+		registry := prometheus.NewRegistry()
+		m := NewMetrics(MetricsOptions{PerEntryLabels: true, PerKeyLabels: true})
+		gomega.Expect(m.Register(registry)).To(gomega.Succeed())
+
+		m.recordSync(entry, resultError, time.Millisecond)
+		gomega.Expect(labelsOf(registry)).To(gomega.ConsistOf("connection", "result", "namespace", "name", "key"))
+	})
+
+	ginkgo.It("should label every series with the connection name", func() {
+		registry := prometheus.NewRegistry()
+		m := NewMetrics(MetricsOptions{ConnectionName: "sessions"})
+		gomega.Expect(m.Register(registry)).To(gomega.Succeed())
+
+		m.recordSync(entry, resultSuccess, time.Millisecond)
+		m.recordDrift(entry, time.Unix(100, 0))
+		m.recordDatasetLoss()
+		gomega.Expect(testutil.ToFloat64(m.syncTotal.WithLabelValues("sessions", resultSuccess))).To(gomega.Equal(1.0))
+		gomega.Expect(testutil.ToFloat64(m.driftTotal.WithLabelValues("sessions"))).To(gomega.Equal(1.0))
+		gomega.Expect(testutil.ToFloat64(m.lastDrift.WithLabelValues("sessions"))).To(gomega.Equal(100.0))
+		gomega.Expect(testutil.ToFloat64(m.datasetLoss.WithLabelValues("sessions"))).To(gomega.Equal(1.0))
+	})
+
+	ginkgo.It("should cap and remove TTL series", func() {
+		registry := prometheus.NewRegistry()
+		m := NewMetrics(MetricsOptions{MaxTTLSeries: 1})
+		gomega.Expect(m.Register(registry)).To(gomega.Succeed())
+
+		other := entry.DeepCopy()
+		other.Name = "other-entry"
+		other.Spec.Key = "other-key"
+
+		m.recordTTL(entry, 90*time.Second)
+		m.recordTTL(other, 30*time.Second)
+		gomega.Expect(testutil.ToFloat64(m.ttlRemaining.WithLabelValues("default", "default", "metrics-entry", "metrics-key"))).
+			To(gomega.Equal(90.0))
+		gomega.Expect(testutil.CollectAndCount(m.ttlRemaining)).To(gomega.Equal(1))
+
+		// A key that lost its expiry frees its slot
+		m.recordTTL(entry, -1)
+		m.recordTTL(other, 30*time.Second)
+		gomega.Expect(testutil.CollectAndCount(m.ttlRemaining)).To(gomega.Equal(1))
+		gomega.Expect(testutil.ToFloat64(m.ttlRemaining.WithLabelValues("default", "default", "other-entry", "other-key"))).
+			To(gomega.Equal(30.0))
+	})
+
+	ginkgo.It("should count queued entries by the reason they were first queued for", func() {
+		m := NewMetrics(MetricsOptions{})
+		backlog := func(reason string) float64 {
+			return testutil.ToFloat64(m.backlog.WithLabelValues(defaultConnectionName, reason))
+		}
+		name := client.ObjectKeyFromObject(entry)
+		changed := entry.DeepCopy()
+		changed.Generation = 2
+
+		record := m.backlogPredicate()
+		gomega.Expect(record.Update(event.UpdateEvent{ObjectOld: entry, ObjectNew: changed})).To(gomega.BeTrue())
+		m.enqueued(name, backlogRetry)
+		m.enqueued(types.NamespacedName{Namespace: "default", Name: "other"}, backlogDrift)
+		gomega.Expect(backlog(backlogSpec)).To(gomega.Equal(1.0))
+		gomega.Expect(backlog(backlogRetry)).To(gomega.Equal(0.0))
+		gomega.Expect(backlog(backlogDrift)).To(gomega.Equal(1.0))
+
+		m.dequeued(name)
+		m.dequeued(name)
+		gomega.Expect(backlog(backlogSpec)).To(gomega.Equal(0.0))
+		gomega.Expect(record.Update(event.UpdateEvent{ObjectOld: changed, ObjectNew: changed})).To(gomega.BeTrue())
+		gomega.Expect(backlog(backlogOther)).To(gomega.Equal(1.0))
+	})
+
+	ginkgo.It("should label entries with a connectionRef by their RedisConnection", func() {
+		m := NewMetrics(MetricsOptions{})
+		remote := entry.DeepCopy()
+		remote.Spec.ConnectionRef = &corev1.LocalObjectReference{Name: "cache"}
+		m.recordSync(remote, resultError, time.Millisecond)
+		gomega.Expect(testutil.ToFloat64(m.syncTotal.WithLabelValues("default/cache", resultError))).To(gomega.Equal(1.0))
+		gomega.Expect(testutil.ToFloat64(m.syncTotal.WithLabelValues(defaultConnectionName, resultError))).To(gomega.Equal(0.0))
+
+		// Entries queued by name are counted under the connection they were seen with
+		gomega.Expect(m.backlogPredicate().Create(event.CreateEvent{Object: remote})).To(gomega.BeTrue())
+		m.dequeued(client.ObjectKeyFromObject(remote))
+		m.enqueued(client.ObjectKeyFromObject(remote), backlogRetry)
+		gomega.Expect(testutil.ToFloat64(m.backlog.WithLabelValues("default/cache", backlogRetry))).To(gomega.Equal(1.0))
+		m.dequeued(client.ObjectKeyFromObject(remote))
+		gomega.Expect(testutil.ToFloat64(m.backlog.WithLabelValues("default/cache", backlogRetry))).To(gomega.Equal(0.0))
+	})
+
+	ginkgo.It("should queue failed syncs as retries", func() {
+		s := runtime.NewScheme()
+		gomega.Expect(redisv1alpha1.AddToScheme(s)).To(gomega.Succeed())
+		m := NewMetrics(MetricsOptions{})
+		r := &RedisEntryReconciler{
+			Client: fake.NewClientBuilder().WithScheme(s).WithObjects(entry).
+				WithStatusSubresource(&redisv1alpha1.RedisEntry{}).Build(),
+			Scheme:  s,
+			Metrics: m,
+		}
+		name := client.ObjectKeyFromObject(entry)
+		m.enqueued(name, backlogSpec)
+
+		// Without a Redis client the sync fails and is retried after a delay
+		_, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: name})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(testutil.ToFloat64(m.backlog.WithLabelValues(defaultConnectionName, backlogSpec))).To(gomega.Equal(0.0))
+		gomega.Expect(testutil.ToFloat64(m.backlog.WithLabelValues(defaultConnectionName, backlogRetry))).To(gomega.Equal(1.0))
+	})
+
+	ginkgo.It("should tolerate a nil recorder", func() {
+		var m *Metrics
+		gomega.Expect(func() { m.recordSync(entry, resultSuccess, time.Millisecond) }).NotTo(gomega.Panic())
+	})
+})
@@ -0,0 +1,137 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	"github.com/alicebob/miniredis/v2"
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	redisv9 "github.com/redis/go-redis/v9"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// newMiniRedis starts an in-process Redis for a single spec. Unlike
+// redismock it keeps state, so specs can check what ends up in Redis and
+// move its clock forward to expire keys.
+func newMiniRedis() (*miniredis.Miniredis, *redisv9.Client) {
+	server := miniredis.NewMiniRedis()
+	gomega.Expect(server.Start()).To(gomega.Succeed())
+	redisClient := redisv9.NewClient(&redisv9.Options{Addr: server.Addr()})
+	ginkgo.DeferCleanup(func() {
+		_ = redisClient.Close()
+		server.Close()
+	})
+	return server, redisClient
+}
+
+var _ = ginkgo.Describe("Controller against miniredis", func() {
+	var (
+		ctx         context.Context
+		server      *miniredis.Miniredis
+		redisClient *redisv9.Client
+		fakeClient  client.Client
+	)
+
+	ginkgo.BeforeEach(func() {
+		ctx = context.Background()
+		server, redisClient = newMiniRedis()
+
+		s := runtime.NewScheme()
+		gomega.Expect(redisv1alpha1.AddToScheme(s)).To(gomega.Succeed())
+		fakeClient = fake.NewClientBuilder().
+			WithScheme(s).
+			WithObjects(
+				&redisv1alpha1.RedisEntry{
+					ObjectMeta: metav1.ObjectMeta{Name: "session", Namespace: "default", Generation: 1},
+					Spec: redisv1alpha1.RedisEntrySpec{
+						Key:     "app:session",
+						Value:   "v1",
+						TTL:     ptr.To[int64](60),
+						Entries: map[string]string{"app:session:meta": "m"},
+					},
+				},
+				&redisv1alpha1.RedisAudit{
+					ObjectMeta: metav1.ObjectMeta{Name: "audit", Namespace: "default", Generation: 1},
+					Spec:       redisv1alpha1.RedisAuditSpec{Pattern: "app:*", MaxKeys: 10, DescribeUnmanaged: true},
+				},
+			).
+			WithStatusSubresource(&redisv1alpha1.RedisEntry{}, &redisv1alpha1.RedisAudit{}).
+			Build()
+	})
+
+	ginkgo.It("should rewrite keys after they expire", func() {
+		r := &RedisEntryReconciler{Client: fakeClient, RedisClient: redisClient}
+		request := reconcile.Request{NamespacedName: client.ObjectKey{Namespace: "default", Name: "session"}}
+
+		_, err := r.Reconcile(ctx, request)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(server.Get("app:session")).To(gomega.Equal("v1"))
+		gomega.Expect(server.TTL("app:session:meta")).To(gomega.Equal(time.Minute))
+
+		server.FastForward(61 * time.Second)
+		gomega.Expect(server.Exists("app:session")).To(gomega.BeFalse())
+
+		// Expiry is not drift; the keys are simply written again
+		_, err = r.Reconcile(ctx, request)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(server.Get("app:session")).To(gomega.Equal("v1"))
+		entry := &redisv1alpha1.RedisEntry{}
+		gomega.Expect(fakeClient.Get(ctx, request.NamespacedName, entry)).To(gomega.Succeed())
+		gomega.Expect(entry.Status.LastDriftDetected).To(gomega.BeNil())
+
+		// An external change to the value is
+		gomega.Expect(server.Set("app:session", "tampered")).To(gomega.Succeed())
+		_, err = r.Reconcile(ctx, request)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(server.Get("app:session")).To(gomega.Equal("v1"))
+		gomega.Expect(fakeClient.Get(ctx, request.NamespacedName, entry)).To(gomega.Succeed())
+		gomega.Expect(entry.Status.LastDriftDetected).NotTo(gomega.BeNil())
+	})
+
+	ginkgo.It("should audit the keyspace with a real SCAN", func() {
+		gomega.Expect(server.Set("app:session", "v1")).To(gomega.Succeed())
+		gomega.Expect(server.Set("app:session:meta", "m")).To(gomega.Succeed())
+		server.HSet("app:orphan", "field", "value")
+		server.SetTTL("app:orphan", 90*time.Second)
+		gomega.Expect(server.Set("other:key", "x")).To(gomega.Succeed())
+
+		r := &RedisAuditReconciler{Client: fakeClient, RedisClient: redisClient}
+		name := client.ObjectKey{Namespace: "default", Name: "audit"}
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		audit := &redisv1alpha1.RedisAudit{}
+		gomega.Expect(fakeClient.Get(ctx, name, audit)).To(gomega.Succeed())
+		gomega.Expect(meta.IsStatusConditionTrue(audit.Status.Conditions, typeComplete)).To(gomega.BeTrue())
+		gomega.Expect(audit.Status.ScannedKeys).To(gomega.Equal(int64(3)))
+		gomega.Expect(audit.Status.ManagedKeys).To(gomega.Equal(int64(2)))
+		gomega.Expect(audit.Status.UnmanagedKeyDetails).To(gomega.HaveLen(1))
+		gomega.Expect(audit.Status.UnmanagedKeyDetails[0].Key).To(gomega.Equal("app:orphan"))
+		gomega.Expect(audit.Status.UnmanagedKeyDetails[0].Type).To(gomega.Equal("hash"))
+		gomega.Expect(audit.Status.UnmanagedKeyDetails[0].TTLSeconds).To(gomega.Equal(ptr.To[int64](90)))
+	})
+
+	ginkgo.It("should resync entries after Redis is flushed", func() {
+		monitor := newHealthMonitor(fakeClient, redisClient, time.Second)
+		monitor.markerKey = DefaultMarkerKey
+		monitor.check(ctx)
+		gomega.Expect(server.Exists(DefaultMarkerKey)).To(gomega.BeTrue())
+
+		server.FlushAll()
+		go monitor.check(ctx)
+
+		var evt event.GenericEvent
+		gomega.Eventually(monitor.events).Should(gomega.Receive(&evt))
+		gomega.Expect(evt.Object.GetName()).To(gomega.Equal("session"))
+		gomega.Expect(server.Exists(DefaultMarkerKey)).To(gomega.BeTrue())
+	})
+})
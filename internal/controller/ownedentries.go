@@ -0,0 +1,236 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// reasonEntriesPending is used while some owned entries are not synced
+	reasonEntriesPending = "EntriesPending"
+
+	// reasonEntriesFailed is used when some owned entries failed to sync
+	reasonEntriesFailed = "EntriesFailed"
+
+	// ownedNameHashLength is the length of the key hash suffixed to the
+	// names of owned entries
+	ownedNameHashLength = 10
+
+	// maxObjectNameLength is the longest name Kubernetes accepts for a
+	// RedisEntry
+	maxObjectNameLength = 253
+)
+
+// errNameTaken is returned when the name of an owned entry is used by a
+// RedisEntry the owner does not control.
+var errNameTaken = errors.New("entry name is taken")
+
+// ownedEntries keeps the RedisEntries controlled by an owner, such as a
+// batch or a workload, in line with the keys it declares. Entries are found
+// through a label carrying the owner's UID rather than their names, so that
+// entries of keys the owner no longer declares can be deleted.
+type ownedEntries struct {
+	client client.Client
+	scheme *runtime.Scheme
+	owner  client.Object
+	label  string
+
+	// existing holds the entries not yet claimed by apply, by name
+	existing map[string]*redisv1alpha1.RedisEntry
+}
+
+// listOwnedEntries lists the entries labeled with the owner's UID.
+func listOwnedEntries(ctx context.Context, c client.Client, scheme *runtime.Scheme, owner client.Object, label string) (*ownedEntries, error) {
+	children := &redisv1alpha1.RedisEntryList{}
+	if err := c.List(ctx, children, client.InNamespace(owner.GetNamespace()),
+		client.MatchingLabels{label: string(owner.GetUID())}); err != nil {
+		return nil, err
+	}
+	o := &ownedEntries{
+		client:   c,
+		scheme:   scheme,
+		owner:    owner,
+		label:    label,
+		existing: make(map[string]*redisv1alpha1.RedisEntry, len(children.Items)),
+	}
+	for i := range children.Items {
+		o.existing[children.Items[i].Name] = &children.Items[i]
+	}
+	return o, nil
+}
+
+// entry builds the desired entry for a key. The name combines prefix with a
+// hash of the key, which keeps names valid and stable whatever the key. The
+// entry carries the owner's labels, so that an entry selector matches it
+// like its owner, and the owner's UID.
+func (o *ownedEntries) entry(prefix string, spec redisv1alpha1.RedisEntrySpec) *redisv1alpha1.RedisEntry {
+	labels := maps.Clone(o.owner.GetLabels())
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[o.label] = string(o.owner.GetUID())
+	return &redisv1alpha1.RedisEntry{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ownedEntryName(prefix, spec.Key),
+			Namespace: o.owner.GetNamespace(),
+			Labels:    labels,
+		},
+		Spec: spec,
+	}
+}
+
+// apply creates the desired entry, or updates the existing one when its spec
+// or labels differ.
+func (o *ownedEntries) apply(ctx context.Context, desired *redisv1alpha1.RedisEntry) (*redisv1alpha1.RedisEntry, error) {
+	entry, ok := o.existing[desired.Name]
+	if !ok {
+		if err := controllerutil.SetControllerReference(o.owner, desired, o.scheme); err != nil {
+			return nil, err
+		}
+		err := o.client.Create(ctx, desired)
+		if !apierrors.IsAlreadyExists(err) {
+			return desired, err
+		}
+		// The cache may not have caught up with an entry created by an
+		// earlier reconcile
+		entry = &redisv1alpha1.RedisEntry{}
+		if err := o.client.Get(ctx, client.ObjectKeyFromObject(desired), entry); err != nil {
+			return nil, err
+		}
+		if !metav1.IsControlledBy(entry, o.owner) {
+			return nil, fmt.Errorf("%w: RedisEntry %s is not owned by %s", errNameTaken, entry.Name, o.owner.GetName())
+		}
+	}
+	delete(o.existing, desired.Name)
+
+	if equality.Semantic.DeepEqual(entry.Spec, desired.Spec) && maps.Equal(entry.Labels, desired.Labels) {
+		return entry, nil
+	}
+	entry.Spec = desired.Spec
+	entry.Labels = desired.Labels
+	if err := o.client.Update(ctx, entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// prune deletes the entries that were not applied, which belong to keys
+// the owner no longer declares, and returns them.
+func (o *ownedEntries) prune(ctx context.Context) ([]*redisv1alpha1.RedisEntry, error) {
+	var deleted []*redisv1alpha1.RedisEntry
+	for name, entry := range o.existing {
+		if err := o.client.Delete(ctx, entry); client.IgnoreNotFound(err) != nil {
+			return deleted, fmt.Errorf("failed to delete RedisEntry %s: %w", name, err)
+		}
+		delete(o.existing, name)
+		deleted = append(deleted, entry)
+	}
+	return deleted, nil
+}
+
+// ownedEntryName derives the name of an owned entry from a prefix and the key.
+func ownedEntryName(prefix, key string) string {
+	sum := sha256.Sum256([]byte(key))
+	suffix := hex.EncodeToString(sum[:])[:ownedNameHashLength]
+	prefix = prefix[:min(len(prefix), maxObjectNameLength-len(suffix)-1)]
+	return strings.TrimRight(prefix, "-.") + "-" + suffix
+}
+
+// summarizeOwned counts the owned entries by sync state into status and
+// returns the Available condition reflecting the counts. Keys whose entry
+// could not be created count as failed.
+func summarizeOwned(status *redisv1alpha1.OwnedEntriesStatus, generation int64,
+	entries []*redisv1alpha1.RedisEntry, conflicts []redisv1alpha1.KeyFailure) metav1.Condition {
+	status.Entries = int32(len(entries))
+	status.Synced, status.Failed, status.Pending = 0, int32(len(conflicts)), 0
+	status.FailedItems = conflicts[:min(len(conflicts), maxFailedKeys)]
+
+	for _, entry := range entries {
+		switch {
+		case entrySynced(entry):
+			status.Synced++
+		case entryFailed(entry):
+			status.Failed++
+			if len(status.FailedItems) < maxFailedKeys {
+				status.FailedItems = append(status.FailedItems, redisv1alpha1.KeyFailure{
+					Key:   entry.Spec.Key,
+					Error: entryError(entry),
+				})
+			}
+		default:
+			status.Pending++
+		}
+	}
+	if len(status.FailedItems) == 0 {
+		status.FailedItems = nil
+	}
+
+	total := len(entries) + len(conflicts)
+	condition := metav1.Condition{
+		Type:               typeAvailable,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             reasonSuccess,
+		Message:            fmt.Sprintf("All %d entries are synced", status.Synced),
+	}
+	switch {
+	case status.Failed > 0:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = reasonEntriesFailed
+		condition.Message = fmt.Sprintf("%d of %d keys failed to sync", status.Failed, total)
+	case status.Pending > 0:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = reasonEntriesPending
+		condition.Message = fmt.Sprintf("%d of %d keys are waiting to be synced", status.Pending, total)
+	}
+	return condition
+}
+
+// entrySynced reports whether the entry's current generation was written to
+// Redis by its last sync.
+func entrySynced(entry *redisv1alpha1.RedisEntry) bool {
+	cond := meta.FindStatusCondition(entry.Status.Conditions, typeAvailable)
+	return cond != nil && cond.Status == metav1.ConditionTrue &&
+		cond.ObservedGeneration == entry.Generation && !entryFailed(entry)
+}
+
+// entryFailed reports whether the entry's last sync failed. A successful
+// sync removes the Error condition.
+func entryFailed(entry *redisv1alpha1.RedisEntry) bool {
+	return meta.IsStatusConditionTrue(entry.Status.Conditions, typeError)
+}
+
+// entryError returns the error of a failed entry.
+func entryError(entry *redisv1alpha1.RedisEntry) string {
+	return meta.FindStatusCondition(entry.Status.Conditions, typeError).Message
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	redisv9 "github.com/redis/go-redis/v9"
+)
+
+const (
+	// permissionProbeKey is the key used when asking the server whether a
+	// command would be allowed. Users restricted by key patterns may still be
+	// refused on other keys.
+	permissionProbeKey = "redis-ctrl:permission-probe"
+
+	// permissionRecheckInterval limits how often a failed check is repeated
+	permissionRecheckInterval = 30 * time.Second
+)
+
+// requiredCommands are the commands the controller issues, with probe
+// arguments for ACL DRYRUN.
+var requiredCommands = [][]interface{}{
+	{"SET", permissionProbeKey, "probe"},
+	{"GET", permissionProbeKey},
+	{"MGET", permissionProbeKey},
+	{"MSET", permissionProbeKey, "probe"},
+	{"DEL", permissionProbeKey},
+	{"EXPIRE", permissionProbeKey, "1"},
+	{"SCAN", "0"},
+	{"MULTI"},
+	{"EXEC"},
+}
+
+// checkPermissions asks the server which required commands the connected
+// user may not run. It returns the user name and the missing commands, or an
+// error when the server cannot answer (no ACL support, or the user may not
+// run ACL DRYRUN), in which case permissions are unknown.
+func checkPermissions(ctx context.Context, redisClient redisv9.UniversalClient, server *ServerInfo) (string, []string, error) {
+	if err := server.Require(CapabilityACLDryRun); err != nil {
+		return "", nil, err
+	}
+
+	user, err := redisClient.Do(ctx, "ACL", "WHOAMI").Text()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to determine Redis user: %w", err)
+	}
+
+	var missing []string
+	for _, command := range requiredCommands {
+		args := append([]interface{}{"ACL", "DRYRUN", user}, command...)
+		reply, err := redisClient.Do(ctx, args...).Text()
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to check permission for %s: %w", command[0], err)
+		}
+		if reply != "OK" {
+			missing = append(missing, command[0].(string))
+		}
+	}
+	return user, missing, nil
+}
+
+// permissionState remembers the outcome of the last permission check. Until
+// a check succeeds the permissions are unknown and writes are not blocked.
+type permissionState struct {
+	mu        sync.Mutex
+	checked   bool
+	user      string
+	missing   []string
+	checkedAt time.Time
+}
+
+// check runs a permission check and records its result. Errors leave the
+// state unknown.
+func (p *permissionState) check(ctx context.Context, redisClient redisv9.UniversalClient, server *ServerInfo) error {
+	user, missing, err := checkPermissions(ctx, redisClient, server)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.checkedAt = time.Now()
+	if err != nil {
+		p.checked = false
+		return err
+	}
+	p.checked, p.user, p.missing = true, user, missing
+	return nil
+}
+
+// insufficient returns a condition message when the last check found missing
+// commands. A failed check is repeated at most every permissionRecheckInterval
+// so that fixing the ACL is picked up without a restart.
+func (p *permissionState) insufficient(ctx context.Context, redisClient redisv9.UniversalClient, server *ServerInfo) string {
+	p.mu.Lock()
+	stale := p.checked && len(p.missing) > 0 && time.Since(p.checkedAt) > permissionRecheckInterval
+	p.mu.Unlock()
+
+	if stale {
+		_ = p.check(ctx, redisClient, server)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.checked || len(p.missing) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Redis user %q is not allowed to run: %s", p.user, strings.Join(p.missing, ", "))
+}
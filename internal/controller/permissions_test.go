@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	redismock "github.com/go-redis/redismock/v9"
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = ginkgo.Describe("Redis Permission Check", func() {
+	server := &ServerInfo{Version: "7.2.4", major: 7, minor: 2}
+
+	ginkgo.It("should report commands the user may not run", func() {
+		mockRedis, mock := redismock.NewClientMock()
+		mock.ExpectDo("ACL", "WHOAMI").SetVal("ctrl")
+		for _, command := range requiredCommands {
+			reply := "OK"
+			if command[0] == "SCAN" {
+				reply = "User ctrl has no permissions to run the 'scan' command"
+			}
+			args := append([]interface{}{"ACL", "DRYRUN", "ctrl"}, command...)
+			mock.ExpectDo(args...).SetVal(reply)
+		}
+
+		user, missing, err := checkPermissions(context.Background(), mockRedis, server)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(user).To(gomega.Equal("ctrl"))
+		gomega.Expect(missing).To(gomega.ConsistOf("SCAN"))
+		gomega.Expect(mock.ExpectationsWereMet()).To(gomega.Succeed())
+	})
+
+	ginkgo.It("should skip the check on servers without ACL DRYRUN", func() {
+		mockRedis, mock := redismock.NewClientMock()
+		_, _, err := checkPermissions(context.Background(), mockRedis, &ServerInfo{Version: "6.2.0", major: 6, minor: 2})
+		gomega.Expect(err).To(gomega.HaveOccurred())
+		gomega.Expect(mock.ExpectationsWereMet()).To(gomega.Succeed())
+	})
+
+	ginkgo.It("should set an InsufficientPermissions condition instead of writing", func() {
+		ctx := context.Background()
+		s := runtime.NewScheme()
+		gomega.Expect(redisv1alpha1.AddToScheme(s)).To(gomega.Succeed())
+		mockRedis, mock := redismock.NewClientMock()
+		r := &RedisEntryReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(s).
+				WithStatusSubresource(&redisv1alpha1.RedisEntry{}).
+				Build(),
+			Scheme:      s,
+			RedisClient: mockRedis,
+			Server:      server,
+		}
+		r.permissions.checked = true
+		r.permissions.user = "ctrl"
+		r.permissions.missing = []string{"SET"}
+		r.permissions.checkedAt = time.Now()
+
+		entry := &redisv1alpha1.RedisEntry{
+			ObjectMeta: metav1.ObjectMeta{Name: "acl-entry", Namespace: "default"},
+			Spec:       redisv1alpha1.RedisEntrySpec{Key: "acl-key", Value: "acl-value"},
+		}
+		gomega.Expect(r.Create(ctx, entry)).To(gomega.Succeed())
+
+		name := types.NamespacedName{Name: "acl-entry", Namespace: "default"}
+		result, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(result.RequeueAfter).To(gomega.Equal(permissionRecheckInterval))
+
+		updated := &redisv1alpha1.RedisEntry{}
+		gomega.Expect(r.Get(ctx, name, updated)).To(gomega.Succeed())
+		gomega.Expect(updated.Status.Conditions).To(gomega.HaveLen(1))
+		gomega.Expect(updated.Status.Conditions[0].Reason).To(gomega.Equal("InsufficientPermissions"))
+		gomega.Expect(updated.Status.Conditions[0].Message).To(gomega.ContainSubstring("SET"))
+		gomega.Expect(mock.ExpectationsWereMet()).To(gomega.Succeed())
+	})
+})
@@ -0,0 +1,138 @@
+//go:build redis
+
+package controller
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	redisv9 "github.com/redis/go-redis/v9"
+	tcredis "github.com/testcontainers/testcontainers-go/modules/redis"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+// These specs run against a Redis container instead of redismock so that
+// server semantics (TTLs, transactions, replica errors) are exercised for
+// real. They need Docker and are only built with -tags redis.
+var _ = ginkgo.Describe("Real Redis", ginkgo.Ordered, func() {
+	var (
+		ctx         context.Context
+		container   *tcredis.RedisContainer
+		redisClient *redisv9.Client
+		reconciler  *RedisEntryReconciler
+	)
+
+	ginkgo.BeforeAll(func() {
+		ctx = context.Background()
+		var err error
+		container, err = tcredis.Run(ctx, "redis:7.4-alpine")
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		address, err := container.ConnectionString(ctx)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		opts, err := RedisOptionsFromAddress(address)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		opts, conns := trackConns(opts)
+		redisClient = redisv9.NewClient(opts)
+		reconciler = &RedisEntryReconciler{RedisClient: redisClient, conns: conns}
+	})
+
+	ginkgo.AfterAll(func() {
+		if redisClient != nil {
+			gomega.Expect(redisClient.Close()).To(gomega.Succeed())
+		}
+		if container != nil {
+			gomega.Expect(container.Terminate(ctx)).To(gomega.Succeed())
+		}
+	})
+
+	ginkgo.BeforeEach(func() {
+		gomega.Expect(redisClient.FlushAll(ctx).Err()).To(gomega.Succeed())
+	})
+
+	newEntry := func(name string) *redisv1alpha1.RedisEntry {
+		return &redisv1alpha1.RedisEntry{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Generation: 1},
+			Spec: redisv1alpha1.RedisEntrySpec{
+				Key:   name,
+				Value: "value",
+				TTL:   ptr.To[int64](60),
+			},
+			Status: redisv1alpha1.RedisEntryStatus{Conditions: []metav1.Condition{{
+				Type: typeAvailable, Status: metav1.ConditionTrue, ObservedGeneration: 1,
+			}}},
+		}
+	}
+
+	ginkgo.It("should write related keys in one transaction with their TTL", func() {
+		entry := newEntry("tx")
+		entry.Spec.Entries = map[string]string{"tx:a": "a", "tx:b": "b"}
+		entry.Spec.Checksum = redisv1alpha1.ChecksumSHA256
+
+		gomega.Expect(reconciler.writeEntry(ctx, reconciler.store(redisClient, nil), entry, "value", time.Minute)).To(gomega.Succeed())
+		gomega.Expect(entry.Status.WriteMode).To(gomega.Equal(redisv1alpha1.WriteModeTransaction))
+
+		values, err := redisClient.MGet(ctx, "tx", "tx:a", "tx:b", "tx:sha256").Result()
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(values).To(gomega.Equal([]interface{}{"value", "a", "b", valueChecksum("value")}))
+		for _, key := range entryKeys(entry) {
+			ttl, err := redisClient.TTL(ctx, key).Result()
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(ttl).To(gomega.BeNumerically(">", 50*time.Second), key)
+		}
+
+		drifted, err := reconciler.detectDrift(ctx, reconciler.store(redisClient, nil), entry, "value")
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(drifted).To(gomega.BeNil())
+	})
+
+	ginkgo.It("should reassemble chunked values and drop stale chunks", func() {
+		entry := newEntry("blob")
+		entry.Spec.ChunkSizeBytes = ptr.To[int64](1024)
+		large := strings.Repeat("x", 3000)
+
+		gomega.Expect(reconciler.writeEntry(ctx, reconciler.store(redisClient, nil), entry, large, time.Minute)).To(gomega.Succeed())
+		gomega.Expect(entry.Status.Chunks).To(gomega.BeEquivalentTo(3))
+		var assembled string
+		for i := range 3 {
+			chunk, err := redisClient.Get(ctx, chunkKey("blob", i)).Result()
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			assembled += chunk
+		}
+		gomega.Expect(assembled).To(gomega.Equal(large))
+
+		gomega.Expect(reconciler.writeEntry(ctx, reconciler.store(redisClient, nil), entry, "small", time.Minute)).To(gomega.Succeed())
+		gomega.Expect(redisClient.Exists(ctx, chunkKey("blob", 0), chunkKey("blob", 2)).Val()).To(gomega.BeZero())
+		gomega.Expect(redisClient.Get(ctx, "blob").Val()).To(gomega.Equal("small"))
+	})
+
+	ginkgo.It("should delete every key once the deadline passed", func() {
+		entry := newEntry("expiring")
+		entry.Spec.Entries = map[string]string{"expiring:extra": "x"}
+		gomega.Expect(reconciler.writeEntry(ctx, reconciler.store(redisClient, nil), entry, "value", time.Minute)).To(gomega.Succeed())
+
+		gomega.Expect(reconciler.deleteEntry(ctx, reconciler.store(redisClient, nil), entry)).To(gomega.Succeed())
+		gomega.Expect(redisClient.Exists(ctx, entryKeys(entry)...).Val()).To(gomega.BeZero())
+	})
+
+	ginkgo.It("should recognize writes refused by a replica", func() {
+		// Port 1 never answers, so the server stays a read-only replica
+		gomega.Expect(redisClient.Do(ctx, "REPLICAOF", "127.0.0.1", "1").Err()).To(gomega.Succeed())
+		ginkgo.DeferCleanup(func() {
+			gomega.Expect(redisClient.Do(ctx, "REPLICAOF", "NO", "ONE").Err()).To(gomega.Succeed())
+		})
+
+		err := reconciler.writeEntry(ctx, reconciler.store(redisClient, nil), newEntry("replica"), "value", time.Minute)
+		gomega.Expect(err).To(gomega.HaveOccurred())
+		gomega.Expect(isReadOnlyError(err)).To(gomega.BeTrue())
+		gomega.Expect(reconciler.conns.reconnect()).To(gomega.BeTrue())
+
+		// The pool dials again after the reconnect
+		gomega.Expect(redisClient.Ping(ctx).Err()).To(gomega.Succeed())
+	})
+})
@@ -0,0 +1,157 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	redisv9 "github.com/redis/go-redis/v9"
+)
+
+const (
+	// minReconnectInterval keeps concurrent reconciles that all hit a
+	// replica from dropping the fresh connections of the first one
+	minReconnectInterval = time.Second
+
+	// defaultDialTimeout mirrors the go-redis default
+	defaultDialTimeout = 5 * time.Second
+)
+
+// connTracker wraps a client's dialer and remembers the open connections so
+// they can be dropped when the address starts leading to another server, for
+// example after a failover. The pool discards closed connections and dials
+// new ones, which resolves the address again.
+type connTracker struct {
+	dial func(ctx context.Context, network, addr string) (net.Conn, error)
+
+	mu            sync.Mutex
+	conns         map[*trackedConn]struct{}
+	lastReconnect time.Time
+
+	// onRedial, when set, is called when a connection is dialed while none
+	// is open, such as after a reconnect or a restart of the server
+	onRedial func()
+}
+
+// trackConns returns a copy of opts whose connections are tracked.
+func trackConns(opts *redisv9.Options) (*redisv9.Options, *connTracker) {
+	tracked := *opts
+	if tracked.DialTimeout == 0 {
+		tracked.DialTimeout = defaultDialTimeout
+	}
+	t := &connTracker{dial: tracked.Dialer, conns: map[*trackedConn]struct{}{}}
+	if t.dial == nil {
+		t.dial = redisv9.NewDialer(&tracked)
+	}
+	tracked.Dialer = t.dialContext
+	return &tracked, t
+}
+
+// dialContext dials through the wrapped dialer and tracks the connection.
+func (t *connTracker) dialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	conn, err := t.dial(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+	tracked := &trackedConn{Conn: conn, tracker: t}
+	t.mu.Lock()
+	redialed := len(t.conns) == 0
+	t.conns[tracked] = struct{}{}
+	onRedial := t.onRedial
+	t.mu.Unlock()
+	if redialed && onRedial != nil {
+		onRedial()
+	}
+
+	// The pool only health-checks idle connections it can read from directly;
+	// TLS connections closed by reconnect fail on their next use instead
+	if _, ok := conn.(syscall.Conn); ok {
+		return &trackedSyscallConn{trackedConn: tracked}, nil
+	}
+	return tracked, nil
+}
+
+// notifyRedial sets the function called when a connection is dialed while
+// none is open.
+func (t *connTracker) notifyRedial(fn func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onRedial = fn
+}
+
+// reconnect closes every tracked connection, unless that was done within
+// minReconnectInterval. It reports whether connections were closed. A nil
+// tracker does nothing.
+func (t *connTracker) reconnect() bool {
+	if t == nil {
+		return false
+	}
+	t.mu.Lock()
+	if time.Since(t.lastReconnect) < minReconnectInterval {
+		t.mu.Unlock()
+		return false
+	}
+	t.lastReconnect = time.Now()
+	conns := make([]*trackedConn, 0, len(t.conns))
+	for conn := range t.conns {
+		conns = append(conns, conn)
+	}
+	t.mu.Unlock()
+
+	for _, conn := range conns {
+		_ = conn.Close()
+	}
+	return len(conns) > 0
+}
+
+// trackedConn removes itself from its tracker when closed.
+type trackedConn struct {
+	net.Conn
+	tracker *connTracker
+	once    sync.Once
+}
+
+// Close closes the connection and stops tracking it.
+func (c *trackedConn) Close() error {
+	c.once.Do(func() {
+		c.tracker.mu.Lock()
+		delete(c.tracker.conns, c)
+		c.tracker.mu.Unlock()
+	})
+	return c.Conn.Close()
+}
+
+// trackedSyscallConn exposes the socket of a plain TCP or unix connection so
+// the pool can tell that a closed connection is gone before reusing it.
+type trackedSyscallConn struct {
+	*trackedConn
+}
+
+// SyscallConn returns the raw connection of the wrapped socket.
+func (c *trackedSyscallConn) SyscallConn() (syscall.RawConn, error) {
+	return c.Conn.(syscall.Conn).SyscallConn()
+}
+
+// isReadOnlyError reports whether a write was refused by a read-only replica.
+func isReadOnlyError(err error) bool {
+	return strings.Contains(err.Error(), "READONLY")
+}
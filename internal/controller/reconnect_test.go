@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	redisv9 "github.com/redis/go-redis/v9"
+)
+
+var _ = ginkgo.Describe("Connection Tracking", func() {
+	var (
+		listener net.Listener
+		accepted chan net.Conn
+	)
+
+	ginkgo.BeforeEach(func() {
+		var err error
+		listener, err = net.Listen("tcp", "127.0.0.1:0")
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		accepted = make(chan net.Conn, 10)
+		go func(listener net.Listener, accepted chan<- net.Conn) {
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				accepted <- conn
+			}
+		}(listener, accepted)
+	})
+
+	ginkgo.AfterEach(func() {
+		gomega.Expect(listener.Close()).To(gomega.Succeed())
+	})
+
+	ginkgo.It("should close tracked connections on reconnect", func() {
+		opts, tracker := trackConns(&redisv9.Options{Addr: listener.Addr().String()})
+		gomega.Expect(opts.DialTimeout).To(gomega.Equal(defaultDialTimeout))
+
+		conn, err := opts.Dialer(context.Background(), "tcp", opts.Addr)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		server := <-accepted
+
+		gomega.Expect(tracker.reconnect()).To(gomega.BeTrue())
+		gomega.Expect(tracker.conns).To(gomega.BeEmpty())
+		_, err = conn.Write([]byte("PING\r\n"))
+		gomega.Expect(err).To(gomega.MatchError(net.ErrClosed))
+
+		// Right after a reconnect further requests are ignored
+		gomega.Expect(tracker.reconnect()).To(gomega.BeFalse())
+		gomega.Expect(server.Close()).To(gomega.Succeed())
+	})
+
+	ginkgo.It("should notice connections dialed after all were dropped", func() {
+		opts, tracker := trackConns(&redisv9.Options{Addr: listener.Addr().String()})
+		redials := 0
+		tracker.notifyRedial(func() { redials++ })
+
+		first, err := opts.Dialer(context.Background(), "tcp", opts.Addr)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		second, err := opts.Dialer(context.Background(), "tcp", opts.Addr)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(redials).To(gomega.Equal(1))
+
+		gomega.Expect(tracker.reconnect()).To(gomega.BeTrue())
+		third, err := opts.Dialer(context.Background(), "tcp", opts.Addr)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(redials).To(gomega.Equal(2))
+		for _, conn := range []net.Conn{first, second, third} {
+			_ = conn.Close()
+		}
+	})
+
+	ginkgo.It("should stop tracking connections closed by the pool", func() {
+		opts, tracker := trackConns(&redisv9.Options{Addr: listener.Addr().String()})
+		conn, err := opts.Dialer(context.Background(), "tcp", opts.Addr)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(conn.Close()).To(gomega.Succeed())
+		gomega.Expect(tracker.conns).To(gomega.BeEmpty())
+		tracker.lastReconnect = time.Time{}
+		gomega.Expect(tracker.reconnect()).To(gomega.BeFalse())
+	})
+
+	ginkgo.It("should recognize read-only replica errors", func() {
+		gomega.Expect(isReadOnlyError(errors.New("READONLY You can't write against a read only replica."))).To(gomega.BeTrue())
+		gomega.Expect(isReadOnlyError(errors.New("ERR wrong number of arguments"))).To(gomega.BeFalse())
+	})
+})
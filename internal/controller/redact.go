@@ -0,0 +1,60 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// ValueLogMode controls how Redis values appear in log output.
+type ValueLogMode string
+
+const (
+	// ValueLogNever replaces values with a placeholder
+	ValueLogNever ValueLogMode = "never"
+	// ValueLogHashed logs a short SHA-256 prefix, enough to tell values apart
+	ValueLogHashed ValueLogMode = "hashed"
+	// ValueLogAlways logs values verbatim; intended for development only
+	ValueLogAlways ValueLogMode = "always"
+
+	redactedValue = "<redacted>"
+)
+
+// ParseValueLogMode validates a --log-values setting.
+func ParseValueLogMode(mode string) (ValueLogMode, error) {
+	switch m := ValueLogMode(mode); m {
+	case ValueLogNever, ValueLogHashed, ValueLogAlways:
+		return m, nil
+	}
+	return "", fmt.Errorf("unknown value log mode %q, expected never, hashed or always", mode)
+}
+
+// redact renders a value for a log line according to the mode. The zero
+// mode behaves like ValueLogNever.
+func (m ValueLogMode) redact(value string) string {
+	switch m {
+	case ValueLogAlways:
+		return value
+	case ValueLogHashed:
+		sum := sha256.Sum256([]byte(value))
+		return "sha256:" + hex.EncodeToString(sum[:6])
+	default:
+		return redactedValue
+	}
+}
@@ -0,0 +1,32 @@
+package controller
+
+import (
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+)
+
+var _ = ginkgo.Describe("Value Redaction", func() {
+	ginkgo.It("should hide values unless configured otherwise", func() {
+		var unset ValueLogMode
+		gomega.Expect(unset.redact("hunter2")).To(gomega.Equal("<redacted>"))
+		gomega.Expect(ValueLogNever.redact("hunter2")).To(gomega.Equal("<redacted>"))
+		gomega.Expect(ValueLogAlways.redact("hunter2")).To(gomega.Equal("hunter2"))
+	})
+
+	ginkgo.It("should log a stable short hash in hashed mode", func() {
+		hashed := ValueLogHashed.redact("hunter2")
+		gomega.Expect(hashed).To(gomega.MatchRegexp(`^sha256:[0-9a-f]{12}$`))
+		gomega.Expect(hashed).NotTo(gomega.ContainSubstring("hunter2"))
+		gomega.Expect(ValueLogHashed.redact("hunter2")).To(gomega.Equal(hashed))
+		gomega.Expect(ValueLogHashed.redact("hunter3")).NotTo(gomega.Equal(hashed))
+	})
+
+	ginkgo.It("should reject unknown modes", func() {
+		mode, err := ParseValueLogMode("hashed")
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(mode).To(gomega.Equal(ValueLogHashed))
+
+		_, err = ParseValueLogMode("sometimes")
+		gomega.Expect(err).To(gomega.HaveOccurred())
+	})
+})
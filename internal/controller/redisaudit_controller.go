@@ -0,0 +1,384 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	redisv9 "github.com/redis/go-redis/v9"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// typeComplete marks an audit whose report is up to date with its spec
+	typeComplete = "Complete"
+
+	// reasonScanUnsupported is used when SCAN is unavailable, e.g. through a proxy
+	reasonScanUnsupported = "ScanUnsupported"
+
+	// Audit defaults, mirroring the CRD defaults
+	defaultAuditPattern        = "*"
+	defaultAuditMaxKeys        = 10000
+	defaultAuditOversizedBytes = 1 << 20
+
+	// auditScanCount is the COUNT hint passed to each SCAN call
+	auditScanCount = 100
+
+	// maxReportedKeys caps each key list in the report to keep the status small
+	maxReportedKeys = 100
+)
+
+// RedisAuditReconciler reconciles a RedisAudit object by scanning the
+// keyspace and recording a report in its status.
+type RedisAuditReconciler struct {
+	client.Client
+	Scheme      *runtime.Scheme
+	RedisClient redisv9.UniversalClient
+
+	// ProxyMode mirrors RedisEntryReconciler.ProxyMode; proxies don't
+	// support SCAN, so audits are refused.
+	ProxyMode bool
+
+	// APIReader, when set, lists RedisEntries from the API server in pages
+	// while collecting managed keys.
+	APIReader client.Reader
+
+	// Entries compares RedisEntries with Redis for audits with
+	// spec.compareEntries set.
+	Entries *RedisEntryReconciler
+}
+
+// +kubebuilder:rbac:groups=redis.aaspcodes.github.io,resources=redisaudits,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=redis.aaspcodes.github.io,resources=redisaudits/status,verbs=get;update;patch
+
+// Reconcile runs an audit once per generation.
+func (r *RedisAuditReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	audit := &redisv1alpha1.RedisAudit{}
+	if err := r.Get(ctx, req.NamespacedName, audit); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Info("RedisAudit resource not found. Ignoring since object must be deleted")
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Failed to get RedisAudit")
+		return ctrl.Result{}, err
+	}
+	pruneConditions(&audit.Status.Conditions, auditConditionTypes)
+
+	// The report for this generation has already been produced
+	if cond := meta.FindStatusCondition(audit.Status.Conditions, typeComplete); cond != nil &&
+		cond.Status == metav1.ConditionTrue && cond.ObservedGeneration == audit.Generation {
+		return ctrl.Result{}, nil
+	}
+
+	if r.RedisClient == nil {
+		log.Error(nil, "Redis client not initialized")
+		return r.fail(ctx, audit, "RedisClientNotInitialized", "Redis client is not initialized", true)
+	}
+	if r.ProxyMode {
+		return r.fail(ctx, audit, reasonScanUnsupported, "Audits need SCAN, which Redis proxies do not support", false)
+	}
+
+	redisClient := r.RedisClient
+	if ref := audit.Spec.ConnectionRef; ref != nil {
+		if r.Entries == nil {
+			return r.fail(ctx, audit, reasonConnectionError, "RedisConnections are not set up", false)
+		}
+		conn, err := r.Entries.connectionClient(ctx, types.NamespacedName{Namespace: audit.Namespace, Name: ref.Name}, false)
+		if err != nil {
+			log.Error(err, "Failed to get Redis client for RedisConnection")
+			return r.fail(ctx, audit, reasonConnectionError, err.Error(), true)
+		}
+		defer conn.release()
+		redisClient = conn.client
+	}
+	if isCluster(redisClient) {
+		return r.fail(ctx, audit, reasonScanUnsupported, "Audits of a Redis Cluster are not supported, since SCAN only covers one node", false)
+	}
+
+	managed, err := r.managedKeys(ctx, audit)
+	if err != nil {
+		log.Error(err, "Failed to list RedisEntries")
+		return ctrl.Result{}, err
+	}
+
+	report, err := r.scan(ctx, redisClient, audit.Spec, managed)
+	if err != nil {
+		log.Error(err, "Failed to scan Redis keyspace")
+		return r.fail(ctx, audit, reasonRedisError, err.Error(), true)
+	}
+
+	if audit.Spec.CompareEntries {
+		if r.Entries == nil {
+			return r.fail(ctx, audit, "EntryComparisonUnavailable", "Entry comparison is not set up", false)
+		}
+		if err := r.compareEntries(ctx, audit, report); err != nil {
+			log.Error(err, "Failed to compare RedisEntries")
+			return ctrl.Result{}, err
+		}
+	}
+
+	now := metav1.Now()
+	report.Conditions = audit.Status.Conditions
+	report.CompletionTime = &now
+	audit.Status = *report
+	meta.RemoveStatusCondition(&audit.Status.Conditions, typeError)
+	meta.SetStatusCondition(&audit.Status.Conditions, metav1.Condition{
+		Type:               typeComplete,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: audit.Generation,
+		Reason:             reasonSuccess,
+		Message:            fmt.Sprintf("Scanned %d keys", report.ScannedKeys),
+	})
+	if err := r.Status().Update(ctx, audit); err != nil {
+		log.Error(err, "Failed to update RedisAudit status")
+		return ctrl.Result{}, err
+	}
+	log.Info("Completed Redis audit", "scanned", report.ScannedKeys, "unmanaged", report.UnmanagedKeys)
+	return ctrl.Result{}, nil
+}
+
+// fail records an error condition on the audit. Transient failures are
+// retried after redisErrorRetryDelay.
+func (r *RedisAuditReconciler) fail(ctx context.Context, audit *redisv1alpha1.RedisAudit, reason, message string, retry bool) (ctrl.Result, error) {
+	meta.SetStatusCondition(&audit.Status.Conditions, metav1.Condition{
+		Type:               typeError,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: audit.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+	if err := r.Status().Update(ctx, audit); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to update RedisAudit status")
+		return ctrl.Result{}, err
+	}
+	if !retry {
+		return ctrl.Result{}, nil
+	}
+	return ctrl.Result{RequeueAfter: redisErrorRetryDelay}, nil
+}
+
+// compareEntries adds the comparison of every managed RedisEntry in the
+// audit's namespace that writes to the audited server to the report.
+func (r *RedisAuditReconciler) compareEntries(ctx context.Context, audit *redisv1alpha1.RedisAudit,
+	report *redisv1alpha1.RedisAuditStatus) error {
+	match := func(entry *redisv1alpha1.RedisEntry) bool { return onAuditedServer(audit, entry) }
+	return r.Entries.diffEntries(ctx, match, func(diff EntryDiff) error {
+		key, state := diff.firstDifference()
+		if state == DiffInSync {
+			report.EntriesInSync++
+			return nil
+		}
+		report.EntriesDrifted++
+		if len(report.DriftedEntries) < maxReportedKeys {
+			report.DriftedEntries = append(report.DriftedEntries, redisv1alpha1.DriftedEntry{
+				Namespace: diff.Namespace,
+				Name:      diff.Name,
+				Key:       key,
+				State:     state,
+			})
+		}
+		return nil
+	}, client.InNamespace(audit.Namespace))
+}
+
+// managedKeys returns every key declared by a RedisEntry written to the
+// audited server. Entries without a connectionRef share the controller's
+// own server across namespaces; a RedisConnection is only referenced from its
+// own namespace.
+func (r *RedisAuditReconciler) managedKeys(ctx context.Context, audit *redisv1alpha1.RedisAudit) (map[string]bool, error) {
+	var opts []client.ListOption
+	if audit.Spec.ConnectionRef != nil {
+		opts = append(opts, client.InNamespace(audit.Namespace))
+	}
+	keys := map[string]bool{}
+	err := forEachEntry(ctx, r.Client, r.APIReader, func(entry *redisv1alpha1.RedisEntry) error {
+		if !onAuditedServer(audit, entry) {
+			return nil
+		}
+		for _, key := range entryKeys(entry) {
+			keys[key] = true
+		}
+		return nil
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// onAuditedServer reports whether an entry is written to the server an audit
+// scans.
+func onAuditedServer(audit *redisv1alpha1.RedisAudit, entry *redisv1alpha1.RedisEntry) bool {
+	ref := audit.Spec.ConnectionRef
+	if ref == nil {
+		return entry.Spec.ConnectionRef == nil
+	}
+	return entry.Namespace == audit.Namespace && entry.Spec.ConnectionRef != nil &&
+		entry.Spec.ConnectionRef.Name == ref.Name
+}
+
+// scan walks the keys matching the audit pattern and classifies them.
+func (r *RedisAuditReconciler) scan(ctx context.Context, redisClient redisv9.UniversalClient,
+	spec redisv1alpha1.RedisAuditSpec, managed map[string]bool) (*redisv1alpha1.RedisAuditStatus, error) {
+	pattern := spec.Pattern
+	if pattern == "" {
+		pattern = defaultAuditPattern
+	}
+	maxKeys := spec.MaxKeys
+	if maxKeys <= 0 {
+		maxKeys = defaultAuditMaxKeys
+	}
+	oversized := spec.OversizedBytes
+	if oversized <= 0 {
+		oversized = defaultAuditOversizedBytes
+	}
+
+	report := &redisv1alpha1.RedisAuditStatus{}
+	// SCAN may return a key more than once
+	seen := map[string]bool{}
+	var cursor uint64
+	for {
+		keys, next, err := redisClient.Scan(ctx, cursor, pattern, auditScanCount).Result()
+		if err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+
+		var batch []string
+		for _, key := range keys {
+			if seen[key] {
+				continue
+			}
+			if int64(len(seen)) >= maxKeys {
+				report.Truncated = true
+				break
+			}
+			seen[key] = true
+			batch = append(batch, key)
+		}
+		if err := r.inspectKeys(ctx, redisClient, batch, managed, oversized, spec.DescribeUnmanaged, report); err != nil {
+			return nil, err
+		}
+
+		cursor = next
+		if cursor == 0 || report.Truncated {
+			break
+		}
+	}
+
+	sort.Slice(report.OversizedKeys, func(i, j int) bool {
+		return report.OversizedKeys[i].Bytes > report.OversizedKeys[j].Bytes
+	})
+	if len(report.OversizedKeys) > maxReportedKeys {
+		report.OversizedKeys = report.OversizedKeys[:maxReportedKeys]
+	}
+	return report, nil
+}
+
+// inspectKeys reads the TTL and memory usage of a batch of keys in one
+// pipeline and adds them to the report. When describe is set, the type of
+// unmanaged keys is read as well. Keys that expired since the scan are
+// skipped.
+func (r *RedisAuditReconciler) inspectKeys(ctx context.Context, redisClient redisv9.UniversalClient,
+	keys []string, managed map[string]bool,
+	oversized int64, describe bool, report *redisv1alpha1.RedisAuditStatus) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	ttls := make([]*redisv9.DurationCmd, len(keys))
+	sizes := make([]*redisv9.IntCmd, len(keys))
+	keyTypes := make([]*redisv9.StatusCmd, len(keys))
+	_, err := redisClient.Pipelined(ctx, func(pipe redisv9.Pipeliner) error {
+		for i, key := range keys {
+			ttls[i] = pipe.TTL(ctx, key)
+			sizes[i] = pipe.MemoryUsage(ctx, key)
+			if describe && !managed[key] {
+				keyTypes[i] = pipe.Type(ctx, key)
+			}
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, redisv9.Nil) {
+		return fmt.Errorf("failed to inspect keys: %w", err)
+	}
+
+	for i, key := range keys {
+		// TTL is -2 for keys that no longer exist and -1 for keys without expiry
+		ttl := ttls[i].Val()
+		if ttl == -2 {
+			continue
+		}
+		report.ScannedKeys++
+
+		if managed[key] {
+			report.ManagedKeys++
+		} else {
+			report.UnmanagedKeys++
+			if len(report.UnmanagedKeySamples) < maxReportedKeys {
+				report.UnmanagedKeySamples = append(report.UnmanagedKeySamples, key)
+				if keyTypes[i] != nil {
+					report.UnmanagedKeyDetails = append(report.UnmanagedKeyDetails, describeKey(key, keyTypes[i].Val(), ttl, sizes[i].Val()))
+				}
+			}
+		}
+
+		if ttl == time.Duration(-1) {
+			report.KeysWithoutTTL++
+			if len(report.KeyWithoutTTLSamples) < maxReportedKeys {
+				report.KeyWithoutTTLSamples = append(report.KeyWithoutTTLSamples, key)
+			}
+		}
+
+		if bytes := sizes[i].Val(); bytes > oversized {
+			report.OversizedKeys = append(report.OversizedKeys, redisv1alpha1.AuditedKey{Key: key, Bytes: bytes})
+		}
+	}
+	return nil
+}
+
+// describeKey builds the report entry of an unmanaged key.
+func describeKey(key, keyType string, ttl time.Duration, bytes int64) redisv1alpha1.UnmanagedKey {
+	described := redisv1alpha1.UnmanagedKey{Key: key, Type: keyType, Bytes: bytes}
+	if ttl >= 0 {
+		seconds := int64(ttl / time.Second)
+		described.TTLSeconds = &seconds
+	}
+	return described
+}
+
+// SetupWithManager sets up the controller with the Manager. RedisClient must
+// already be connected, typically by sharing the RedisEntry controller's client.
+func (r *RedisAuditReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&redisv1alpha1.RedisAudit{}).
+		Named("redisaudit").
+		Complete(r)
+}
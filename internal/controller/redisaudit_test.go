@@ -0,0 +1,139 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	redismock "github.com/go-redis/redismock/v9"
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	redisv9 "github.com/redis/go-redis/v9"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = ginkgo.Describe("RedisAudit Controller", func() {
+	var (
+		ctx        context.Context
+		mockRedis  *redisv9.Client
+		mock       redismock.ClientMock
+		reconciler *RedisAuditReconciler
+		name       types.NamespacedName
+	)
+
+	ginkgo.BeforeEach(func() {
+		ctx = context.Background()
+		s := runtime.NewScheme()
+		gomega.Expect(redisv1alpha1.AddToScheme(s)).To(gomega.Succeed())
+
+		entry := &redisv1alpha1.RedisEntry{
+			ObjectMeta: metav1.ObjectMeta{Name: "managed", Namespace: "other"},
+			Spec: redisv1alpha1.RedisEntrySpec{
+				Key:     "app:config",
+				Value:   "v",
+				Entries: map[string]string{"app:flags": "f"},
+			},
+		}
+		// Keys written to another server are not managed on the audited one
+		remote := &redisv1alpha1.RedisEntry{
+			ObjectMeta: metav1.ObjectMeta{Name: "remote", Namespace: "default"},
+			Spec: redisv1alpha1.RedisEntrySpec{
+				Key:           "app:orphan",
+				Value:         "v",
+				ConnectionRef: &corev1.LocalObjectReference{Name: "cache"},
+			},
+		}
+		audit := &redisv1alpha1.RedisAudit{
+			ObjectMeta: metav1.ObjectMeta{Name: "audit", Namespace: "default", Generation: 1},
+			Spec:       redisv1alpha1.RedisAuditSpec{Pattern: "app:*", MaxKeys: 10, OversizedBytes: 1000},
+		}
+		name = types.NamespacedName{Name: "audit", Namespace: "default"}
+
+		mockRedis, mock = redismock.NewClientMock()
+		reconciler = &RedisAuditReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(s).
+				WithObjects(entry, remote, audit).
+				WithStatusSubresource(&redisv1alpha1.RedisAudit{}).
+				Build(),
+			Scheme:      s,
+			RedisClient: mockRedis,
+		}
+	})
+
+	ginkgo.AfterEach(func() {
+		gomega.Expect(mock.ExpectationsWereMet()).To(gomega.Succeed())
+	})
+
+	ginkgo.It("should classify scanned keys", func() {
+		mock.ExpectScan(0, "app:*", auditScanCount).SetVal([]string{"app:config", "app:orphan"}, 7)
+		mock.ExpectTTL("app:config").SetVal(time.Minute)
+		mock.ExpectMemoryUsage("app:config").SetVal(64)
+		mock.ExpectTTL("app:orphan").SetVal(time.Duration(-1))
+		mock.ExpectMemoryUsage("app:orphan").SetVal(4096)
+		// The repeated key must only be counted once
+		mock.ExpectScan(7, "app:*", auditScanCount).SetVal([]string{"app:orphan", "app:flags"}, 0)
+		mock.ExpectTTL("app:flags").SetVal(time.Duration(-1))
+		mock.ExpectMemoryUsage("app:flags").SetVal(64)
+
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		audit := &redisv1alpha1.RedisAudit{}
+		gomega.Expect(reconciler.Get(ctx, name, audit)).To(gomega.Succeed())
+		gomega.Expect(audit.Status.ScannedKeys).To(gomega.Equal(int64(3)))
+		gomega.Expect(audit.Status.ManagedKeys).To(gomega.Equal(int64(2)))
+		gomega.Expect(audit.Status.UnmanagedKeySamples).To(gomega.ConsistOf("app:orphan"))
+		gomega.Expect(audit.Status.KeyWithoutTTLSamples).To(gomega.ConsistOf("app:orphan", "app:flags"))
+		gomega.Expect(audit.Status.OversizedKeys).To(gomega.ConsistOf(
+			redisv1alpha1.AuditedKey{Key: "app:orphan", Bytes: 4096}))
+		gomega.Expect(audit.Status.Truncated).To(gomega.BeFalse())
+		gomega.Expect(meta.IsStatusConditionTrue(audit.Status.Conditions, typeComplete)).To(gomega.BeTrue())
+
+		// A completed audit is not run again for the same generation
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+	})
+
+	ginkgo.It("should describe unmanaged keys when asked", func() {
+		audit := &redisv1alpha1.RedisAudit{}
+		gomega.Expect(reconciler.Get(ctx, name, audit)).To(gomega.Succeed())
+		audit.Spec.DescribeUnmanaged = true
+		gomega.Expect(reconciler.Update(ctx, audit)).To(gomega.Succeed())
+
+		mock.ExpectScan(0, "app:*", auditScanCount).SetVal([]string{"app:config", "app:orphan"}, 0)
+		mock.ExpectTTL("app:config").SetVal(time.Minute)
+		mock.ExpectMemoryUsage("app:config").SetVal(64)
+		mock.ExpectTTL("app:orphan").SetVal(90 * time.Second)
+		mock.ExpectMemoryUsage("app:orphan").SetVal(4096)
+		mock.ExpectType("app:orphan").SetVal("hash")
+
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		gomega.Expect(reconciler.Get(ctx, name, audit)).To(gomega.Succeed())
+		gomega.Expect(audit.Status.UnmanagedKeyDetails).To(gomega.ConsistOf(redisv1alpha1.UnmanagedKey{
+			Key: "app:orphan", Type: "hash", TTLSeconds: ptr.To[int64](90), Bytes: 4096,
+		}))
+	})
+
+	ginkgo.It("should refuse to scan in proxy mode", func() {
+		reconciler.ProxyMode = true
+
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		audit := &redisv1alpha1.RedisAudit{}
+		gomega.Expect(reconciler.Get(ctx, name, audit)).To(gomega.Succeed())
+		cond := meta.FindStatusCondition(audit.Status.Conditions, typeError)
+		gomega.Expect(cond).NotTo(gomega.BeNil())
+		gomega.Expect(cond.Reason).To(gomega.Equal(reasonScanUnsupported))
+	})
+})
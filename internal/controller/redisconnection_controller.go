@@ -0,0 +1,416 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	redisv9 "github.com/redis/go-redis/v9"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+const (
+	// reasonConnected is used when the server of a RedisConnection answered
+	reasonConnected = "Connected"
+
+	// reasonConnectionError is used when a RedisConnection cannot be
+	// resolved or its server does not answer
+	reasonConnectionError = "ConnectionError"
+
+	// connectionCheckInterval is how often a RedisConnection's server is
+	// pinged, which also picks up rotated credentials and CA certificates
+	connectionCheckInterval = time.Minute
+)
+
+// connectionClient is the Redis client of a RedisConnection, along with the
+// versions of the connection and Secrets it was built from.
+type connectionClient struct {
+	uid        types.UID
+	generation int64
+	version    string
+	client     redisv9.UniversalClient
+
+	// conns tracks the client's connections so a failover of this server
+	// drops them without touching other clients
+	conns *connTracker
+
+	// server is detected on first use, since servers of connections may run
+	// other versions or engines than the controller's own
+	server *serverState
+
+	// users counts the syncs holding the client; a retired client is closed
+	// once the last of them releases it
+	users   int
+	retired bool
+}
+
+// connectionClients caches one Redis client per RedisConnection. The zero
+// value is ready to use.
+type connectionClients struct {
+	mu      sync.Mutex
+	clients map[types.NamespacedName]*connectionClient
+
+	// onClose, when set, is called with plain clients that are replaced or
+	// dropped
+	onClose func(*redisv9.Client)
+}
+
+// lookup acquires the cached client built from the current generation of the
+// connection. Changes to its Secrets are picked up by refreshes only, so
+// entries don't read Secrets on every sync.
+func (c *connectionClients) lookup(conn *redisv1alpha1.RedisConnection) (*connectionClient, func(), bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, ok := c.clients[client.ObjectKeyFromObject(conn)]
+	if !ok || cached.uid != conn.UID || cached.generation != conn.Generation {
+		return nil, nil, false
+	}
+	return cached, c.acquireLocked(cached), true
+}
+
+// put acquires the cached client when it was built from the same version, and
+// otherwise replaces it with a client for opts.
+func (c *connectionClients) put(conn *redisv1alpha1.RedisConnection, version string,
+	opts *redisv9.Options) (*connectionClient, func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	name := client.ObjectKeyFromObject(conn)
+	if cached, ok := c.clients[name]; ok {
+		if cached.version == version {
+			return cached, c.acquireLocked(cached)
+		}
+		c.retireLocked(cached)
+	}
+	opts, conns := trackConns(opts)
+	server := &serverState{}
+	conns.notifyRedial(server.invalidate)
+	var redisClient redisv9.UniversalClient
+	if conn.Spec.Cluster {
+		redisClient = redisv9.NewClusterClient(clusterOptions(opts))
+	} else {
+		redisClient = redisv9.NewClient(opts)
+	}
+	cached := &connectionClient{
+		uid:        conn.UID,
+		generation: conn.Generation,
+		version:    version,
+		client:     redisClient,
+		conns:      conns,
+		server:     server,
+	}
+	if c.clients == nil {
+		c.clients = map[types.NamespacedName]*connectionClient{}
+	}
+	c.clients[name] = cached
+	return cached, c.acquireLocked(cached)
+}
+
+// forget drops the client of a deleted connection, closing it once it is no
+// longer in use.
+func (c *connectionClients) forget(name types.NamespacedName) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cached, ok := c.clients[name]; ok {
+		c.retireLocked(cached)
+		delete(c.clients, name)
+	}
+}
+
+// acquireLocked hands out the client until the returned function is called.
+func (c *connectionClients) acquireLocked(cached *connectionClient) func() {
+	cached.users++
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			cached.users--
+			if cached.retired && cached.users == 0 {
+				c.closeLocked(cached.client)
+			}
+		})
+	}
+}
+
+// retireLocked closes a replaced client, or leaves that to its last user.
+func (c *connectionClients) retireLocked(cached *connectionClient) {
+	cached.retired = true
+	if cached.users == 0 {
+		c.closeLocked(cached.client)
+	}
+}
+
+func (c *connectionClients) closeLocked(redisClient redisv9.UniversalClient) {
+	if plain, ok := redisClient.(*redisv9.Client); ok && c.onClose != nil {
+		c.onClose(plain)
+	}
+	_ = redisClient.Close()
+}
+
+// connectionOptions builds the client options of a connection, reading its
+// Secrets. The version identifies the connection, its generation and the
+// Secrets they came from; status writes leave it unchanged.
+func connectionOptions(ctx context.Context, reader client.Reader,
+	conn *redisv1alpha1.RedisConnection) (*redisv9.Options, string, error) {
+	opts := &redisv9.Options{Addr: conn.Spec.Address, DB: int(conn.Spec.DB)}
+	version := string(conn.UID) + "/" + strconv.FormatInt(conn.Generation, 10)
+
+	if ref := conn.Spec.CredentialsSecretRef; ref != nil {
+		secret := &corev1.Secret{}
+		if err := reader.Get(ctx, types.NamespacedName{Namespace: conn.Namespace, Name: ref.Name}, secret); err != nil {
+			return nil, "", fmt.Errorf("failed to read credentials secret %q: %w", ref.Name, err)
+		}
+		password, ok := secret.Data[credentialsPasswordKey]
+		if !ok {
+			return nil, "", fmt.Errorf("secret %q has no %q key", ref.Name, credentialsPasswordKey)
+		}
+		opts.Username = string(secret.Data[credentialsUsernameKey])
+		opts.Password = string(password)
+		version += "/" + secret.ResourceVersion
+	}
+
+	if spec := conn.Spec.TLS; spec != nil {
+		host, _, err := net.SplitHostPort(conn.Spec.Address)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid address: %w", err)
+		}
+		opts.TLSConfig = &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			ServerName: host,
+			// Opt-in, documented as meant for testing
+			InsecureSkipVerify: spec.InsecureSkipVerify, //nolint:gosec
+		}
+		if spec.ServerName != "" {
+			opts.TLSConfig.ServerName = spec.ServerName
+		}
+		if ref := spec.CASecretRef; ref != nil {
+			secret := &corev1.Secret{}
+			if err := reader.Get(ctx, types.NamespacedName{Namespace: conn.Namespace, Name: ref.Name}, secret); err != nil {
+				return nil, "", fmt.Errorf("failed to read CA secret %q: %w", ref.Name, err)
+			}
+			roots := x509.NewCertPool()
+			if !roots.AppendCertsFromPEM(secret.Data[ref.Key]) {
+				return nil, "", fmt.Errorf("secret %q has no PEM certificates in key %q", ref.Name, ref.Key)
+			}
+			opts.TLSConfig.RootCAs = roots
+			version += "/" + secret.ResourceVersion
+		}
+	}
+	return opts, version, nil
+}
+
+// connectionClient returns the client of the named RedisConnection. The
+// cached client is reused while the connection's spec is unchanged; refresh
+// reads its Secrets again and replaces the client when they changed. The
+// caller releases the client once done with it.
+func (r *RedisEntryReconciler) connectionClient(ctx context.Context, name types.NamespacedName,
+	refresh bool) (*entryClient, error) {
+	conn := &redisv1alpha1.RedisConnection{}
+	if err := r.Get(ctx, name, conn); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("RedisConnection %q not found", name.Name)
+		}
+		return nil, fmt.Errorf("failed to get RedisConnection %q: %w", name.Name, err)
+	}
+	var cached *connectionClient
+	var release func()
+	ok := false
+	if !refresh {
+		cached, release, ok = r.connections.lookup(conn)
+	}
+	if !ok {
+		opts, version, err := connectionOptions(ctx, r.sourceReader(), conn)
+		if err != nil {
+			return nil, fmt.Errorf("RedisConnection %q: %w", name.Name, err)
+		}
+		cached, release = r.connections.put(conn, version, opts)
+	}
+	return &entryClient{
+		client:  cached.client,
+		conns:   cached.conns,
+		server:  cached.server.get(ctx, cached.client, r.ProxyMode),
+		release: release,
+	}, nil
+}
+
+// entryClient is the Redis client an entry is written with.
+type entryClient struct {
+	client redisv9.UniversalClient
+
+	// shared is set for the controller's own client
+	shared bool
+
+	// conns tracks the connections of the client, if they are tracked
+	conns *connTracker
+
+	// server describes the server behind the client; nil means unknown
+	server *ServerInfo
+
+	// release gives the client back once done with it
+	release func()
+}
+
+// redisClientForEntry returns the Redis client to write an entry with: the
+// client of its RedisConnection when it references one, and the client for
+// its namespace otherwise. The caller releases the client once done with it.
+func (r *RedisEntryReconciler) redisClientForEntry(ctx context.Context,
+	redisEntry *redisv1alpha1.RedisEntry) (*entryClient, error) {
+	if ref := redisEntry.Spec.ConnectionRef; ref != nil {
+		return r.connectionClient(ctx, types.NamespacedName{Namespace: redisEntry.Namespace, Name: ref.Name}, false)
+	}
+	redisClient, shared, err := r.redisClientFor(ctx, redisEntry.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	// Namespace clients share the dialer, and so the tracker, of the
+	// controller's own client
+	return &entryClient{client: redisClient, shared: shared, conns: r.conns, server: r.serverInfo(ctx), release: func() {}}, nil
+}
+
+// entriesForConnection maps a change to a RedisConnection to the entries
+// referencing it.
+func (r *RedisEntryReconciler) entriesForConnection(ctx context.Context, obj client.Object) []ctrl.Request {
+	var requests []ctrl.Request
+	err := forEachEntry(ctx, r.Client, r.APIReader, func(entry *redisv1alpha1.RedisEntry) error {
+		if ref := entry.Spec.ConnectionRef; ref != nil && ref.Name == obj.GetName() && r.managesEntry(entry) {
+			name := client.ObjectKeyFromObject(entry)
+			r.Metrics.enqueued(name, backlogOther)
+			requests = append(requests, ctrl.Request{NamespacedName: name})
+		}
+		return nil
+	}, client.InNamespace(obj.GetNamespace()))
+	if err != nil {
+		return nil
+	}
+	return requests
+}
+
+// resyncConnection resyncs the entries written through a RedisConnection. It
+// does nothing before the health monitor, which feeds the resyncs to the
+// controller, is set up.
+func (r *RedisEntryReconciler) resyncConnection(ctx context.Context, conn *redisv1alpha1.RedisConnection) {
+	if r.monitor == nil {
+		return
+	}
+	r.monitor.resync(ctx, func(entry *redisv1alpha1.RedisEntry) bool {
+		return entry.Spec.ConnectionRef != nil && entry.Spec.ConnectionRef.Name == conn.Name
+	}, client.InNamespace(conn.Namespace))
+}
+
+// RedisConnectionReconciler reconciles a RedisConnection object by keeping
+// its client up to date and reporting whether the server answers.
+type RedisConnectionReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// Entries holds the clients shared with the RedisEntry controller.
+	Entries *RedisEntryReconciler
+
+	// SkipStatus only keeps the clients up to date and leaves reporting the
+	// result of each check to another deployment, such as the stable
+	// controller during a canary rollout.
+	SkipStatus bool
+}
+
+// +kubebuilder:rbac:groups=redis.aaspcodes.github.io,resources=redisconnections,verbs=get;list;watch
+// +kubebuilder:rbac:groups=redis.aaspcodes.github.io,resources=redisconnections/status,verbs=get;update;patch
+
+// Reconcile pings the connection's server and records the result.
+func (r *RedisConnectionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	conn := &redisv1alpha1.RedisConnection{}
+	if err := r.Get(ctx, req.NamespacedName, conn); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Info("RedisConnection resource not found. Ignoring since object must be deleted")
+			r.Entries.connections.forget(req.NamespacedName)
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Failed to get RedisConnection")
+		return ctrl.Result{}, err
+	}
+	pruneConditions(&conn.Status.Conditions, connectionConditionTypes)
+	wasDown := meta.IsStatusConditionTrue(conn.Status.Conditions, typeError)
+
+	now := metav1.NewTime(r.Entries.now())
+	conn.Status.LastChecked = &now
+	redisClient, err := r.Entries.connectionClient(ctx, req.NamespacedName, true)
+	if err == nil {
+		err = redisClient.client.Ping(ctx).Err()
+		redisClient.release()
+	}
+	if err != nil {
+		log.Error(err, "Failed to reach Redis through RedisConnection")
+		meta.RemoveStatusCondition(&conn.Status.Conditions, typeAvailable)
+		setConnectionCondition(conn, typeError, reasonConnectionError, err.Error())
+	} else {
+		meta.RemoveStatusCondition(&conn.Status.Conditions, typeError)
+		setConnectionCondition(conn, typeAvailable, reasonConnected, "Redis answered")
+		if wasDown {
+			// Entries failed while the server was down may be waiting out
+			// long retry delays, or for a change after failed logins
+			log.Info("Redis is reachable again through RedisConnection, resyncing its entries")
+			r.Entries.resyncConnection(ctx, conn)
+		}
+	}
+	if r.SkipStatus {
+		return ctrl.Result{RequeueAfter: connectionCheckInterval}, nil
+	}
+	if err := r.Status().Update(ctx, conn); err != nil {
+		log.Error(err, "Failed to update RedisConnection status")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: connectionCheckInterval}, nil
+}
+
+// setConnectionCondition sets a condition of the connection to True.
+func setConnectionCondition(conn *redisv1alpha1.RedisConnection, conditionType, reason, message string) {
+	meta.SetStatusCondition(&conn.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: conn.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *RedisConnectionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// Status writes don't need another check before the next interval
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&redisv1alpha1.RedisConnection{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Named("redisconnection").
+		Complete(r)
+}
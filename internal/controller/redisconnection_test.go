@@ -0,0 +1,324 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	"github.com/alicebob/miniredis/v2"
+	redismock "github.com/go-redis/redismock/v9"
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	redisv9 "github.com/redis/go-redis/v9"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = ginkgo.Describe("Redis Connections", func() {
+	var (
+		ctx     context.Context
+		server  *miniredis.Miniredis
+		mock    redismock.ClientMock
+		r       *RedisEntryReconciler
+		connRec *RedisConnectionReconciler
+		conn    *redisv1alpha1.RedisConnection
+	)
+
+	entryName := types.NamespacedName{Name: "remote", Namespace: "default"}
+	connName := types.NamespacedName{Name: "cache", Namespace: "default"}
+
+	ginkgo.BeforeEach(func() {
+		ctx = context.Background()
+		server, _ = newMiniRedis()
+		server.RequireUserAuth("app", "s3cret")
+
+		s := runtime.NewScheme()
+		gomega.Expect(clientgoscheme.AddToScheme(s)).To(gomega.Succeed())
+		gomega.Expect(redisv1alpha1.AddToScheme(s)).To(gomega.Succeed())
+		conn = &redisv1alpha1.RedisConnection{
+			ObjectMeta: metav1.ObjectMeta{Name: connName.Name, Namespace: connName.Namespace},
+			Spec: redisv1alpha1.RedisConnectionSpec{
+				Address:              server.Addr(),
+				DB:                   2,
+				CredentialsSecretRef: &corev1.LocalObjectReference{Name: "cache-credentials"},
+			},
+		}
+		mockRedis, m := redismock.NewClientMock()
+		mock = m
+		r = &RedisEntryReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(s).
+				WithObjects(conn,
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{Name: "cache-credentials", Namespace: "default"},
+						Data:       map[string][]byte{"username": []byte("app"), "password": []byte("s3cret")},
+					},
+					&redisv1alpha1.RedisEntry{
+						ObjectMeta: metav1.ObjectMeta{Name: entryName.Name, Namespace: entryName.Namespace},
+						Spec: redisv1alpha1.RedisEntrySpec{
+							Key:           "app:key",
+							Value:         "v",
+							ConnectionRef: &corev1.LocalObjectReference{Name: connName.Name},
+						},
+					}).
+				WithStatusSubresource(&redisv1alpha1.RedisEntry{}, &redisv1alpha1.RedisConnection{},
+					&redisv1alpha1.RedisAudit{}).
+				Build(),
+			Scheme:      s,
+			RedisClient: mockRedis,
+		}
+		connRec = &RedisConnectionReconciler{Client: r.Client, Scheme: s, Entries: r}
+		ginkgo.DeferCleanup(func() {
+			r.connections.forget(connName)
+		})
+	})
+
+	ginkgo.AfterEach(func() {
+		// Nothing goes to the default connection
+		gomega.Expect(mock.ExpectationsWereMet()).To(gomega.Succeed())
+	})
+
+	ginkgo.It("should write entries to the server of their connection", func() {
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: entryName})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		server.Select(2)
+		gomega.Expect(server.Get("app:key")).To(gomega.Equal("v"))
+
+		entry := &redisv1alpha1.RedisEntry{}
+		gomega.Expect(r.Get(ctx, entryName, entry)).To(gomega.Succeed())
+		gomega.Expect(meta.IsStatusConditionTrue(entry.Status.Conditions, typeAvailable)).To(gomega.BeTrue())
+	})
+
+	ginkgo.It("should fail entries whose connection does not exist", func() {
+		gomega.Expect(r.Delete(ctx, conn)).To(gomega.Succeed())
+
+		result, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: entryName})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(result.RequeueAfter).To(gomega.Equal(redisErrorRetryDelay))
+
+		entry := &redisv1alpha1.RedisEntry{}
+		gomega.Expect(r.Get(ctx, entryName, entry)).To(gomega.Succeed())
+		cond := meta.FindStatusCondition(entry.Status.Conditions, typeError)
+		gomega.Expect(cond).NotTo(gomega.BeNil())
+		gomega.Expect(cond.Reason).To(gomega.Equal(reasonConnectionError))
+	})
+
+	ginkgo.It("should report whether the server answers", func() {
+		result, err := connRec.Reconcile(ctx, reconcile.Request{NamespacedName: connName})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(result.RequeueAfter).To(gomega.Equal(connectionCheckInterval))
+		gomega.Expect(r.Get(ctx, connName, conn)).To(gomega.Succeed())
+		gomega.Expect(meta.IsStatusConditionTrue(conn.Status.Conditions, typeAvailable)).To(gomega.BeTrue())
+
+		// Rotated credentials are picked up by the next check
+		secret := &corev1.Secret{}
+		gomega.Expect(r.Get(ctx, types.NamespacedName{Name: "cache-credentials", Namespace: "default"}, secret)).To(gomega.Succeed())
+		secret.Data["password"] = []byte("wrong")
+		gomega.Expect(r.Update(ctx, secret)).To(gomega.Succeed())
+
+		_, err = connRec.Reconcile(ctx, reconcile.Request{NamespacedName: connName})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(r.Get(ctx, connName, conn)).To(gomega.Succeed())
+		gomega.Expect(meta.FindStatusCondition(conn.Status.Conditions, typeAvailable)).To(gomega.BeNil())
+		cond := meta.FindStatusCondition(conn.Status.Conditions, typeError)
+		gomega.Expect(cond).NotTo(gomega.BeNil())
+		gomega.Expect(cond.Message).To(gomega.ContainSubstring("WRONGPASS"))
+	})
+
+	ginkgo.It("should resync the entries of a connection whose server recovered", func() {
+		r.monitor = newHealthMonitor(r.Client, r.RedisClient, time.Second)
+		gomega.Expect(r.Get(ctx, connName, conn)).To(gomega.Succeed())
+		setConnectionCondition(conn, typeError, reasonConnectionError, "connection refused")
+		gomega.Expect(r.Status().Update(ctx, conn)).To(gomega.Succeed())
+
+		done := make(chan error)
+		go func() {
+			_, err := connRec.Reconcile(ctx, reconcile.Request{NamespacedName: connName})
+			done <- err
+		}()
+		var evt event.GenericEvent
+		gomega.Eventually(r.monitor.events).Should(gomega.Receive(&evt))
+		gomega.Expect(evt.Object.GetName()).To(gomega.Equal(entryName.Name))
+		gomega.Eventually(done).Should(gomega.Receive(gomega.BeNil()))
+		gomega.Expect(r.Get(ctx, connName, conn)).To(gomega.Succeed())
+		gomega.Expect(meta.IsStatusConditionTrue(conn.Status.Conditions, typeAvailable)).To(gomega.BeTrue())
+	})
+
+	ginkgo.It("should requeue the entries referencing a connection", func() {
+		gomega.Expect(r.entriesForConnection(ctx, conn)).To(gomega.ConsistOf(reconcile.Request{NamespacedName: entryName}))
+
+		other := &redisv1alpha1.RedisConnection{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"}}
+		gomega.Expect(r.entriesForConnection(ctx, other)).To(gomega.BeEmpty())
+	})
+
+	ginkgo.It("should configure TLS from the connection", func() {
+		conn.Spec.TLS = &redisv1alpha1.ConnectionTLS{
+			CASecretRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "cache-credentials"},
+				Key:                  "ca.crt",
+			},
+		}
+		_, _, err := connectionOptions(ctx, r.Client, conn)
+		gomega.Expect(err).To(gomega.MatchError(gomega.ContainSubstring("no PEM certificates")))
+
+		conn.Spec.TLS.CASecretRef = nil
+		opts, _, err := connectionOptions(ctx, r.Client, conn)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(opts.TLSConfig.ServerName).To(gomega.Equal("127.0.0.1"))
+		gomega.Expect(opts.DB).To(gomega.Equal(2))
+		gomega.Expect(opts.Username).To(gomega.Equal("app"))
+	})
+
+	ginkgo.It("should refuse transactions across connections", func() {
+		local := &redisv1alpha1.RedisEntry{ObjectMeta: metav1.ObjectMeta{Name: "local"}}
+		remote := &redisv1alpha1.RedisEntry{ObjectMeta: metav1.ObjectMeta{Name: "remote"}}
+		remote.Spec.ConnectionRef = &corev1.LocalObjectReference{Name: connName.Name}
+
+		_, ok := sameConnection([]*redisv1alpha1.RedisEntry{local, local})
+		gomega.Expect(ok).To(gomega.BeTrue())
+		name, ok := sameConnection([]*redisv1alpha1.RedisEntry{local, remote})
+		gomega.Expect(ok).To(gomega.BeFalse())
+		gomega.Expect(name).To(gomega.Equal("remote"))
+	})
+
+	ginkgo.It("should connect to a Redis Cluster", func() {
+		conn.Spec.Cluster = true
+		conn.Spec.DB = 0
+		conn.Generation++
+		gomega.Expect(r.Update(ctx, conn)).To(gomega.Succeed())
+
+		redisClient, err := r.connectionClient(ctx, connName, false)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		defer redisClient.release()
+		gomega.Expect(isCluster(redisClient.client)).To(gomega.BeTrue())
+
+		_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: entryName})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(server.Get("app:key")).To(gomega.Equal("v"))
+	})
+
+	ginkgo.It("should audit the server of the connection", func() {
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: entryName})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		server.Select(2)
+		gomega.Expect(server.Set("app:stray", "x")).To(gomega.Succeed())
+		// Entries on the default connection do not manage keys of this server
+		gomega.Expect(r.Create(ctx, &redisv1alpha1.RedisEntry{
+			ObjectMeta: metav1.ObjectMeta{Name: "local", Namespace: "default"},
+			Spec:       redisv1alpha1.RedisEntrySpec{Key: "app:stray", Value: "x"},
+		})).To(gomega.Succeed())
+		auditName := types.NamespacedName{Name: "audit", Namespace: "default"}
+		gomega.Expect(r.Create(ctx, &redisv1alpha1.RedisAudit{
+			ObjectMeta: metav1.ObjectMeta{Name: auditName.Name, Namespace: auditName.Namespace, Generation: 1},
+			Spec: redisv1alpha1.RedisAuditSpec{
+				Pattern:       "app:*",
+				MaxKeys:       10,
+				ConnectionRef: &corev1.LocalObjectReference{Name: connName.Name},
+			},
+		})).To(gomega.Succeed())
+
+		auditRec := &RedisAuditReconciler{Client: r.Client, Scheme: r.Scheme, RedisClient: r.RedisClient, Entries: r}
+		_, err = auditRec.Reconcile(ctx, reconcile.Request{NamespacedName: auditName})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		audit := &redisv1alpha1.RedisAudit{}
+		gomega.Expect(r.Get(ctx, auditName, audit)).To(gomega.Succeed())
+		gomega.Expect(meta.IsStatusConditionTrue(audit.Status.Conditions, typeComplete)).To(gomega.BeTrue())
+		gomega.Expect(audit.Status.ManagedKeys).To(gomega.Equal(int64(1)))
+		gomega.Expect(audit.Status.UnmanagedKeySamples).To(gomega.ConsistOf("app:stray"))
+	})
+
+	ginkgo.It("should keep the client while the connection's spec is unchanged", func() {
+		first, err := r.connectionClient(ctx, connName, false)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		first.release()
+
+		// Status writes by the connection check leave the client alone
+		_, err = connRec.Reconcile(ctx, reconcile.Request{NamespacedName: connName})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		second, err := r.connectionClient(ctx, connName, false)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		second.release()
+		gomega.Expect(second.client).To(gomega.BeIdenticalTo(first.client))
+	})
+
+	ginkgo.It("should track the connections of each connection's client on their own", func() {
+		entry := &redisv1alpha1.RedisEntry{}
+		gomega.Expect(r.Get(ctx, entryName, entry)).To(gomega.Succeed())
+		r.conns = &connTracker{conns: map[*trackedConn]struct{}{}}
+		redisClient, err := r.redisClientForEntry(ctx, entry)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		defer redisClient.release()
+		gomega.Expect(redisClient.conns).NotTo(gomega.BeNil())
+		gomega.Expect(redisClient.conns).NotTo(gomega.BeIdenticalTo(r.conns))
+
+		// A failover of the connection's server leaves the default client alone
+		gomega.Expect(redisClient.client.Ping(ctx).Err()).To(gomega.Succeed())
+		gomega.Expect(redisClient.conns.reconnect()).To(gomega.BeTrue())
+		gomega.Expect(redisClient.client.Ping(ctx).Err()).To(gomega.Succeed())
+	})
+
+	ginkgo.It("should close a replaced client once it is no longer in use", func() {
+		inUse, err := r.connectionClient(ctx, connName, false)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		gomega.Expect(r.Get(ctx, connName, conn)).To(gomega.Succeed())
+		conn.Spec.DB = 3
+		conn.Generation++ // the fake client doesn't track generations
+		gomega.Expect(r.Update(ctx, conn)).To(gomega.Succeed())
+		replacement, err := r.connectionClient(ctx, connName, false)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		defer replacement.release()
+		gomega.Expect(replacement.client).NotTo(gomega.BeIdenticalTo(inUse.client))
+		gomega.Expect(replacement.client.(*redisv9.Client).Options().DB).To(gomega.Equal(3))
+
+		gomega.Expect(inUse.client.Ping(ctx).Err()).To(gomega.Succeed())
+		inUse.release()
+		gomega.Expect(inUse.client.Ping(ctx).Err()).To(gomega.MatchError(gomega.ContainSubstring("closed")))
+	})
+
+	ginkgo.It("should keep the clients up to date without writing status", func() {
+		canary := &RedisConnectionReconciler{Client: r.Client, Scheme: r.Scheme, Entries: r, SkipStatus: true}
+		redisClient, err := r.connectionClient(ctx, connName, false)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		redisClient.release()
+
+		secret := &corev1.Secret{}
+		gomega.Expect(r.Get(ctx, types.NamespacedName{Name: "cache-credentials", Namespace: "default"}, secret)).To(gomega.Succeed())
+		secret.Data["password"] = []byte("rotated")
+		gomega.Expect(r.Update(ctx, secret)).To(gomega.Succeed())
+		result, err := canary.Reconcile(ctx, reconcile.Request{NamespacedName: connName})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(result.RequeueAfter).To(gomega.Equal(connectionCheckInterval))
+		gomega.Expect(redisClient.client.Ping(ctx).Err()).To(gomega.MatchError(gomega.ContainSubstring("closed")))
+		gomega.Expect(r.Get(ctx, connName, conn)).To(gomega.Succeed())
+		gomega.Expect(conn.Status.Conditions).To(gomega.BeEmpty())
+
+		gomega.Expect(r.Delete(ctx, conn)).To(gomega.Succeed())
+		_, err = canary.Reconcile(ctx, reconcile.Request{NamespacedName: connName})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		_, _, cached := r.connections.lookup(conn)
+		gomega.Expect(cached).To(gomega.BeFalse())
+	})
+
+	ginkgo.It("should close the client of a deleted connection", func() {
+		redisClient, err := r.connectionClient(ctx, connName, false)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		redisClient.release()
+		gomega.Expect(r.Delete(ctx, conn)).To(gomega.Succeed())
+
+		_, err = connRec.Reconcile(ctx, reconcile.Request{NamespacedName: connName})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(redisClient.client.Ping(ctx).Err()).To(gomega.MatchError(gomega.ContainSubstring("closed")))
+		_, _, cached := r.connections.lookup(conn)
+		gomega.Expect(cached).To(gomega.BeFalse())
+	})
+})
@@ -19,31 +19,52 @@ package controller
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"sort"
+	"strings"
 	"time"
 
 	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	"github.com/AAspCodes/redis-ctrl/internal/features"
+	"github.com/go-logr/logr"
 	redisv9 "github.com/redis/go-redis/v9"
+	"golang.org/x/time/rate"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	crcontroller "sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 )
 
 const (
-	// Redis connection details - these will be configurable via environment variables
-	redisHost     = "redis-redis-service"
-	redisPort     = "6379"
-	redisPassword = "" // No password for now
-
 	// Condition types
 	typeAvailable = "Available"
 	typeError     = "Error"
+	typeCompleted = "Completed"
+	typeDeleting  = "Deleting"
 
 	// Condition reasons
-	reasonSuccess    = "Success"
-	reasonRedisError = "RedisError"
+	reasonSuccess                 = "Success"
+	reasonRedisError              = "RedisError"
+	reasonReservedKey             = "ReservedKey"
+	reasonInvalidKey              = "InvalidKey"
+	reasonInsufficientPermissions = "InsufficientPermissions"
+	reasonCredentialsError        = "CredentialsError"
+	reasonAuthFailed              = "AuthFailed"
+	reasonValueSourceError        = "ValueSourceError"
+	reasonDeadlineExceeded        = "DeadlineExceeded"
+	reasonKeysDeleted             = "KeysDeleted"
+	reasonKeysRetained            = "KeysRetained"
 
 	// Retry settings
 	redisErrorRetryDelay = 5 * time.Second
@@ -54,11 +75,127 @@ type RedisEntryReconciler struct {
 	client.Client
 	Scheme      *runtime.Scheme
 	RedisClient redisv9.UniversalClient
+
+	// RedisOptions configures the client created by SetupWithManager.
+	// Defaults to DefaultRedisAddress without authentication.
+	RedisOptions *redisv9.Options
+
+	// ReservedKeyPrefixes lists key prefixes the controller refuses to write,
+	// protecting keys owned by Redis itself or by applications.
+	ReservedKeyPrefixes []string
+
+	// KeyPolicy restricts key syntax beyond what the CRD validates.
+	KeyPolicy KeyPolicy
+
+	// Metrics records custom sync metrics; nil disables them.
+	Metrics *Metrics
+
+	// ConnectionName names the Redis connection in the resync endpoint and
+	// should match MetricsOptions.ConnectionName. Empty means "default".
+	ConnectionName string
+
+	// HealthCheckInterval is how often Redis is pinged to detect recovery
+	// after an outage. Each ping's round trip feeds the latency metrics.
+	HealthCheckInterval time.Duration
+
+	// LatencyLatest additionally exports the spikes recorded by the server's
+	// latency monitor (LATENCY LATEST) on every health check. It is ignored
+	// in proxy mode.
+	LatencyLatest bool
+
+	// DNSRefreshInterval is how often the host of the Redis address is
+	// resolved again; connections are dropped when its addresses change.
+	// 0 disables the check.
+	DNSRefreshInterval time.Duration
+
+	// Server describes the connected Redis server and gates optional
+	// commands. It is detected on connect; nil means unknown. Once set up,
+	// serverInfo detects it again after the connections were dropped.
+	Server *ServerInfo
+	server serverState
+
+	// WriteLimiter caps the rate of Redis writes across all entries; nil
+	// means unlimited.
+	WriteLimiter *rate.Limiter
+
+	// ProxyMode restricts the controller to commands that Redis proxies such
+	// as Twemproxy or Envoy forward: no MULTI/EXEC, INFO, ACL or HELLO.
+	ProxyMode bool
+
+	// StatusCoalesceWindow defers status writes that only refresh sync
+	// bookkeeping (attempt count and timestamps) while the entry's status was
+	// written more recently than this; 0 writes every change.
+	StatusCoalesceWindow time.Duration
+
+	// StatusLimiter caps the rate of status writes to the API server across
+	// all entries; nil means unlimited.
+	StatusLimiter *rate.Limiter
+
+	// EntrySelector restricts the controller to RedisEntries whose labels
+	// match; nil manages every entry.
+	EntrySelector labels.Selector
+
+	// NamespaceCredentials makes the controller authenticate with the
+	// username and password from a NamespaceCredentialsSecret in the entry's
+	// namespace, when one exists.
+	NamespaceCredentials bool
+
+	// HTTPClient fetches values for valueFrom.http; nil uses a default client
+	// with a timeout.
+	HTTPClient *http.Client
+
+	// LogValues controls whether values appear in logs; the zero value never
+	// logs them.
+	LogValues ValueLogMode
+
+	// APIReader, when set, is used for sweeps over all entries so they can be
+	// listed from the API server in pages instead of copied out of the cache
+	// at once, and to read Secrets outside SecretCacheOptions.
+	APIReader client.Reader
+
+	// MaxConcurrentReconciles is the number of entries reconciled in parallel;
+	// 0 uses the controller-runtime default of 1.
+	MaxConcurrentReconciles int
+
+	// Backpressure, when set, lowers reconcile concurrency and the status write
+	// rate while the API server throttles the controller.
+	Backpressure *Backpressure
+
+	// MarkerKey, when set, is a key the controller keeps in Redis. If it
+	// disappears, Redis lost its data and all entries are resynced.
+	MarkerKey string
+
+	// Clock supplies the current time for deadlines, status timestamps and
+	// status coalescing; nil uses the system clock. Tests set a fake clock to
+	// step past deadlines and coalescing windows without sleeping.
+	Clock clock.PassiveClock
+
+	failures    failureTracker
+	permissions permissionState
+	statuses    statusCoalescer
+	drain       drainState
+
+	namespaceClients namespaceClients
+	connections      connectionClients
+	timeoutClients   timeoutClients
+	values           valueCache
+
+	// conns tracks the connections of clients created by SetupWithManager
+	conns *connTracker
+
+	// valueSecrets caches the metadata of all Secrets once set up
+	valueSecrets cache.Cache
+
+	// monitor and elected back the resync endpoint once set up
+	monitor *healthMonitor
+	elected <-chan struct{}
 }
 
 // +kubebuilder:rbac:groups=redis.aaspcodes.github.io,resources=redisentries,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=redis.aaspcodes.github.io,resources=redisentries/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=redis.aaspcodes.github.io,resources=redisentries/finalizers,verbs=update
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -69,95 +206,376 @@ type RedisEntryReconciler struct {
 //
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.20.4/pkg/reconcile
-func (r *RedisEntryReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+func (r *RedisEntryReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
 	log := log.FromContext(ctx)
 
+	// Leave new work to the next leader while draining
+	if !r.drain.begin() {
+		return ctrl.Result{RequeueAfter: drainRequeueDelay}, nil
+	}
+	defer r.drain.end()
+
+	// Errors are retried through controller-runtime's rate limiter
+	r.Metrics.dequeued(req.NamespacedName)
+	defer func() {
+		if err != nil {
+			r.Metrics.enqueued(req.NamespacedName, backlogRetry)
+		}
+	}()
+
+	// Hold back while the API server is throttling the controller
+	release, err := r.Backpressure.acquire(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	defer release()
+
 	// Fetch the RedisEntry instance
 	redisEntry := &redisv1alpha1.RedisEntry{}
-	err := r.Get(ctx, req.NamespacedName, redisEntry)
+	err = r.Get(ctx, req.NamespacedName, redisEntry)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			// Request object not found, could have been deleted after reconcile request.
 			// Return and don't requeue
 			log.Info("RedisEntry resource not found. Ignoring since object must be deleted")
+			r.statuses.forget(req.NamespacedName)
+			r.values.forget(req.NamespacedName)
+			r.failures.reset(req.NamespacedName)
+			r.Metrics.forgetEntry(req.NamespacedName)
 			return ctrl.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
 		log.Error(err, "Failed to get RedisEntry")
-		return ctrl.Result{Requeue: true, RequeueAfter: redisErrorRetryDelay}, err
+		return ctrl.Result{}, err
+	}
+
+	// The deletion policy is applied to the keys before the entry goes away
+	if !redisEntry.DeletionTimestamp.IsZero() {
+		return r.finalize(ctx, &entrySync{name: req.NamespacedName, entry: redisEntry, original: redisEntry.Status.DeepCopy()})
+	}
+	if err := r.updateFinalizer(ctx, redisEntry); err != nil {
+		log.Error(err, "Failed to update finalizer of RedisEntry")
+		return ctrl.Result{}, err
+	}
+
+	original := redisEntry.Status.DeepCopy()
+	pruneConditions(&redisEntry.Status.Conditions, entryConditionTypes)
+
+	// A requested refresh only reads the key back into the status
+	if refreshRequested(redisEntry) {
+		return r.refreshStatus(ctx, &entrySync{name: req.NamespacedName, entry: redisEntry, original: original})
+	}
+
+	// Entries listed by a RedisTransaction are only written through it
+	claimed, err := r.inTransaction(ctx, redisEntry)
+	if err != nil {
+		log.Error(err, "Failed to list RedisTransactions")
+		return ctrl.Result{}, err
+	}
+	if claimed {
+		log.V(1).Info("Skipping RedisEntry written by a RedisTransaction")
+		return ctrl.Result{}, nil
+	}
+
+	// Entries past their deadline stay deleted until the spec changes, or
+	// are deleted themselves after ttlSecondsAfterFinished
+	if isCompleted(redisEntry) {
+		return r.cleanUpFinished(ctx, redisEntry)
+	}
+
+	return r.sync(ctx, &entrySync{name: req.NamespacedName, entry: redisEntry, original: original})
+}
+
+// reservedKey returns the first key declared by the entry that is the marker
+// key or starts with one of the reserved prefixes.
+func (r *RedisEntryReconciler) reservedKey(redisEntry *redisv1alpha1.RedisEntry) (string, bool) {
+	for _, key := range entryKeys(redisEntry) {
+		// Overwriting or deleting the marker would hide or fake a data loss
+		if r.MarkerKey != "" && key == r.MarkerKey {
+			return key, true
+		}
+		for _, prefix := range r.ReservedKeyPrefixes {
+			if prefix != "" && strings.HasPrefix(key, prefix) {
+				return key, true
+			}
+		}
+	}
+	return "", false
+}
+
+// waitForWriteBudget blocks until the global write limiter admits one write
+// per key of the entry.
+func (r *RedisEntryReconciler) waitForWriteBudget(ctx context.Context, redisEntry *redisv1alpha1.RedisEntry) error {
+	if r.WriteLimiter == nil {
+		return nil
+	}
+	// WaitN rejects requests larger than the burst, so large entries consume
+	// at most a full burst
+	writes := min(len(entryKeys(redisEntry)), r.WriteLimiter.Burst())
+	return r.WriteLimiter.WaitN(ctx, writes)
+}
+
+// extraKeys returns the keys of spec.entries, sorted so that the command
+// sequence sent to Redis is deterministic.
+func extraKeys(redisEntry *redisv1alpha1.RedisEntry) []string {
+	keys := make([]string, 0, len(redisEntry.Spec.Entries))
+	for key := range redisEntry.Spec.Entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// entryKeys returns every key the entry writes: the main key, the chunk keys
+// of the last write, the keys of spec.entries and the checksum key.
+func entryKeys(redisEntry *redisv1alpha1.RedisEntry) []string {
+	keys := []string{redisEntry.Spec.Key}
+	chunked := redisEntry.Status.ChunkedKey
+	if chunked == "" {
+		chunked = redisEntry.Spec.Key
 	}
+	for i := range int(redisEntry.Status.Chunks) {
+		keys = append(keys, chunkKey(chunked, i))
+	}
+	keys = append(keys, extraKeys(redisEntry)...)
+	if key, ok := checksumKey(redisEntry); ok {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// desiredState returns the keys to write for the resolved value of the main
+// key and their values, in the same order as entryKeys. chunks is the number
+// of chunk keys following the main key.
+func desiredState(redisEntry *redisv1alpha1.RedisEntry, value string) (keys, values []string, chunks int) {
+	keys, values = []string{redisEntry.Spec.Key}, []string{value}
+	if manifest, parts, ok := chunkValue(redisEntry, value); ok {
+		values[0] = manifest
+		for i, part := range parts {
+			keys = append(keys, chunkKey(redisEntry.Spec.Key, i))
+			values = append(values, part)
+		}
+		chunks = len(parts)
+	}
+	for _, key := range extraKeys(redisEntry) {
+		keys = append(keys, key)
+		values = append(values, redisEntry.Spec.Entries[key])
+	}
+	if key, ok := checksumKey(redisEntry); ok {
+		keys = append(keys, key)
+		values = append(values, valueChecksum(value))
+	}
+	return keys, values, chunks
+}
 
-	// Check if Redis client is initialized
-	if r.RedisClient == nil {
-		log.Error(nil, "Redis client not initialized")
-		r.setCondition(redisEntry, typeError, "RedisClientNotInitialized", "Redis client is not initialized")
-		if err := r.Client.Status().Update(ctx, redisEntry); err != nil {
-			log.Error(err, "Failed to update RedisEntry status")
-			return ctrl.Result{}, err
+// isSynced reports whether the current generation of the entry has already
+// been written to Redis successfully.
+func isSynced(redisEntry *redisv1alpha1.RedisEntry) bool {
+	for _, cond := range redisEntry.Status.Conditions {
+		if cond.Type == typeAvailable {
+			return cond.Status == metav1.ConditionTrue && cond.ObservedGeneration == redisEntry.Generation
 		}
-		// Return with requeue to retry after a delay
-		return ctrl.Result{Requeue: true, RequeueAfter: redisErrorRetryDelay}, nil
 	}
+	return false
+}
+
+// drift describes the first key found to differ from its declared value.
+type drift struct {
+	key      string
+	expected string
+	// actual is nil when the key is missing
+	actual *string
+}
 
-	// Set the key-value pair in Redis
-	var ttl time.Duration
-	if redisEntry.Spec.TTL != nil {
-		ttl = time.Duration(*redisEntry.Spec.TTL) * time.Second
+// detectDrift reads the keys of an already synced entry and reports whether
+// Redis holds something other than the declared values. A missing key only
+// counts as drift when the entry has no TTL, since expiry is expected
+// otherwise. With a checksum key, the main key is checked through its
+// checksum and length rather than read in full.
+func (r *RedisEntryReconciler) detectDrift(ctx context.Context, store KVStore,
+	redisEntry *redisv1alpha1.RedisEntry, value string) (*drift, error) {
+	if !features.Enabled(features.DriftDetection) || !isSynced(redisEntry) {
+		return nil, nil
 	}
 
-	err = r.RedisClient.Set(ctx, redisEntry.Spec.Key, redisEntry.Spec.Value, ttl).Err()
+	keys, desired, _ := desiredState(redisEntry, value)
+	_, checksummed := checksumKey(redisEntry)
+	if checksummed {
+		length, err := store.StrLen(ctx, keys[0])
+		if err != nil {
+			return nil, err
+		}
+		// STRLEN reports 0 for missing keys
+		if length != int64(len(desired[0])) && (length != 0 || redisEntry.Spec.TTL == nil) {
+			actual := fmt.Sprintf("<%d bytes>", length)
+			return &drift{key: keys[0], expected: fmt.Sprintf("<%d bytes>", len(desired[0])), actual: &actual}, nil
+		}
+		keys, desired = keys[1:], desired[1:]
+	}
+	actual, err := store.Get(ctx, keys...)
 	if err != nil {
-		log.Error(err, "Failed to set key-value pair in Redis")
-		r.setCondition(redisEntry, typeError, reasonRedisError, err.Error())
-		if err := r.Client.Status().Update(ctx, redisEntry); err != nil {
-			log.Error(err, "Failed to update RedisEntry status")
-			return ctrl.Result{}, err
+		return nil, err
+	}
+
+	for i, key := range keys {
+		current := actual[i]
+		if current == nil {
+			if redisEntry.Spec.TTL == nil {
+				return &drift{key: key, expected: desired[i]}, nil
+			}
+			continue
+		}
+		if *current != desired[i] {
+			return &drift{key: key, expected: desired[i], actual: current}, nil
 		}
-		// Requeue with delay for Redis errors
-		return ctrl.Result{Requeue: true, RequeueAfter: redisErrorRetryDelay}, err
 	}
+	return nil, nil
+}
 
-	// Update the status
-	r.setCondition(redisEntry, typeAvailable, reasonSuccess, "Key-value pair successfully set in Redis")
-	if err := r.Client.Status().Update(ctx, redisEntry); err != nil {
-		log.Error(err, "Failed to update RedisEntry status")
-		return ctrl.Result{Requeue: true, RequeueAfter: redisErrorRetryDelay}, err
+// writeEntry sets the entry's key in Redis. When additional pairs are declared
+// in spec.entries, the value is chunked or a checksum key is kept, all keys
+// are written in a single MULTI/EXEC transaction so readers never observe a
+// partially applied entry. Proxies don't forward transactions, so in proxy
+// mode the same commands are sent as a plain pipeline and the write is not
+// atomic. A Redis Cluster only runs transactions on keys in the same hash
+// slot; keys spread across slots are written with one SET each in a pipeline
+// instead. The path taken is recorded in status.writeMode, and chunk keys
+// left over from a larger earlier value are deleted.
+func (r *RedisEntryReconciler) writeEntry(ctx context.Context, store KVStore,
+	redisEntry *redisv1alpha1.RedisEntry, value string, ttl time.Duration) error {
+	keys, values, chunks := desiredState(redisEntry, value)
+	stale, err := staleChunks(ctx, store, redisEntry, chunks)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 1 && len(stale) == 0 {
+		redisEntry.Status.WriteMode = ""
+		return store.Set(ctx, redisEntry.Spec.Key, value, ttl)
 	}
 
-	return ctrl.Result{}, nil
+	var atomic bool
+	if store.CrossSlot(append(keys, stale...)...) {
+		atomic, err = store.Pipeline(ctx, false, func(pipe KVPipe) {
+			for i, key := range keys {
+				pipe.Set(key, values[i], ttl)
+			}
+			for _, key := range stale {
+				pipe.Del(key)
+			}
+		})
+	} else {
+		atomic, err = store.Pipeline(ctx, true, func(pipe KVPipe) {
+			pipe.Set(keys[0], values[0], ttl)
+			// Chunks are sent one at a time to keep each command small
+			for i := 1; i <= chunks; i++ {
+				pipe.Set(keys[i], values[i], ttl)
+			}
+			if rest := keys[1+chunks:]; len(rest) > 0 {
+				pairs := make([]string, 0, 2*len(rest))
+				for i, key := range rest {
+					pairs = append(pairs, key, values[1+chunks+i])
+				}
+				pipe.MSet(pairs...)
+				// MSET has no expiry option, so apply the TTL to each extra key
+				if ttl > 0 {
+					for _, key := range rest {
+						pipe.Expire(key, ttl)
+					}
+				}
+			}
+			if len(stale) > 0 {
+				pipe.Del(stale...)
+			}
+		})
+	}
+	redisEntry.Status.WriteMode = redisv1alpha1.WriteModePipeline
+	if atomic {
+		redisEntry.Status.WriteMode = redisv1alpha1.WriteModeTransaction
+	}
+	if err != nil {
+		return err
+	}
+	redisEntry.Status.Chunks = int32(chunks)
+	redisEntry.Status.ChunkedKey = ""
+	if chunks > 0 {
+		redisEntry.Status.ChunkedKey = redisEntry.Spec.Key
+	}
+	return nil
 }
 
-// setCondition updates the RedisEntry status conditions
+// now returns the current time from Clock, or from the system clock when
+// none is set.
+func (r *RedisEntryReconciler) now() time.Time {
+	if r.Clock == nil {
+		return time.Now()
+	}
+	return r.Clock.Now()
+}
+
+// setCondition sets a condition of the RedisEntry to True. Reason, message and
+// observed generation are updated in place; LastTransitionTime only changes
+// when the condition's status does, so a changing error message doesn't look
+// like a new transition. Freshness is tracked by status.lastSyncTime instead.
 func (r *RedisEntryReconciler) setCondition(redisEntry *redisv1alpha1.RedisEntry, conditionType string, reason, message string) {
-	condition := metav1.Condition{
+	meta.SetStatusCondition(&redisEntry.Status.Conditions, metav1.Condition{
 		Type:               conditionType,
 		Status:             metav1.ConditionTrue,
-		LastTransitionTime: metav1.Now(),
+		ObservedGeneration: redisEntry.Generation,
+		LastTransitionTime: metav1.NewTime(r.now()),
 		Reason:             reason,
 		Message:            message,
+	})
+}
+
+// inspectServer detects the server version so optional commands can be gated,
+// and verifies up front that the Redis user may run every command we need.
+func (r *RedisEntryReconciler) inspectServer(ctx context.Context, setupLog logr.Logger, addr string) {
+	server, err := detectServer(ctx, r.RedisClient)
+	if err != nil {
+		setupLog.Error(err, "Failed to detect Redis server version, assuming all capabilities")
+	} else {
+		setupLog.Info("Connected to Redis", "address", addr, "engine", server.Engine,
+			"engineVersion", server.EngineVersion, "version", server.Version, "modules", server.Modules)
+		r.Server = server
 	}
 
-	// Find and update existing condition or append new one
-	existingConditions := redisEntry.Status.Conditions
-	for i, cond := range existingConditions {
-		if cond.Type == conditionType {
-			if cond.Status != condition.Status || cond.Reason != condition.Reason || cond.Message != condition.Message {
-				existingConditions[i] = condition
-			}
-			return
-		}
+	if err := r.permissions.check(ctx, r.RedisClient, r.Server); err != nil {
+		setupLog.Info("Skipping Redis permission check", "reason", err.Error())
+	} else if message := r.permissions.insufficient(ctx, r.RedisClient, r.Server); message != "" {
+		setupLog.Error(nil, "Insufficient Redis permissions", "details", message)
+	}
+}
+
+// serverInfo returns the ServerInfo of the default connection, detecting it
+// again if every connection to the server was dropped since, as the server
+// may have been upgraded or failed over meanwhile.
+func (r *RedisEntryReconciler) serverInfo(ctx context.Context) *ServerInfo {
+	if r.conns == nil {
+		return r.Server
 	}
-	redisEntry.Status.Conditions = append(existingConditions, condition)
+	return r.server.get(ctx, r.RedisClient, r.ProxyMode)
+}
+
+// managesEntry reports whether an entry matches EntrySelector.
+func (r *RedisEntryReconciler) managesEntry(obj client.Object) bool {
+	return r.EntrySelector == nil || r.EntrySelector.Matches(labels.Set(obj.GetLabels()))
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *RedisEntryReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	// Initialize Redis client
-	r.RedisClient = redisv9.NewClient(&redisv9.Options{
-		Addr:     redisHost + ":" + redisPort,
-		Password: redisPassword,
-		DB:       0,
-	})
+	opts := r.RedisOptions
+	if opts == nil {
+		opts = &redisv9.Options{Addr: DefaultRedisAddress}
+	}
+	if r.ProxyMode {
+		opts = proxyOptions(opts)
+	}
+	opts, r.conns = trackConns(opts)
+	r.RedisClient = redisv9.NewClient(opts)
+	r.namespaceClients.base = opts
+	r.namespaceClients.onClose = r.timeoutClients.release
+	r.connections.onClose = r.timeoutClients.release
 
 	// Test the connection
 	ctx := context.Background()
@@ -165,8 +583,65 @@ func (r *RedisEntryReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		return fmt.Errorf("failed to connect to Redis: %w", err)
 	}
 
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&redisv1alpha1.RedisEntry{}).
-		Named("redisentry").
-		Complete(r)
+	setupLog := mgr.GetLogger().WithName("redis")
+	if r.ProxyMode {
+		// Proxies answer INFO and ACL for no particular backend, if at all
+		setupLog.Info("Connected to Redis through a proxy, skipping server detection and permission check",
+			"address", opts.Addr)
+	} else {
+		r.inspectServer(ctx, setupLog, opts.Addr)
+	}
+	r.server.info = r.Server
+	r.server.detected.Store(true)
+	r.conns.notifyRedial(r.server.invalidate)
+
+	// Follow the address to new endpoints
+	if watcher := newAddressWatcher(opts.Network, opts.Addr, r.DNSRefreshInterval, r.conns); watcher != nil {
+		if err := mgr.Add(watcher); err != nil {
+			return fmt.Errorf("failed to add Redis address watcher: %w", err)
+		}
+	}
+
+	// Resync all entries as soon as Redis recovers from an outage
+	monitor := newHealthMonitor(mgr.GetClient(), r.RedisClient, r.HealthCheckInterval)
+	monitor.selector = r.EntrySelector
+	monitor.apiReader = r.APIReader
+	monitor.markerKey, monitor.metrics = r.MarkerKey, r.Metrics
+	monitor.latencyLatest = r.LatencyLatest && !r.ProxyMode
+	if err := mgr.Add(monitor); err != nil {
+		return fmt.Errorf("failed to add Redis health monitor: %w", err)
+	}
+	r.monitor, r.elected = monitor, mgr.Elected()
+
+	var forOpts []builder.ForOption
+	if r.EntrySelector != nil {
+		forOpts = append(forOpts, builder.WithPredicates(predicate.NewPredicateFuncs(r.managesEntry)))
+	}
+	if r.Metrics != nil {
+		forOpts = append(forOpts, builder.WithPredicates(r.Metrics.backlogPredicate()))
+	}
+
+	bldr := ctrl.NewControllerManagedBy(mgr).
+		For(&redisv1alpha1.RedisEntry{}, forOpts...).
+		WatchesRawSource(monitor.source()).
+		Watches(&redisv1alpha1.RedisTransaction{}, handler.EnqueueRequestsFromMapFunc(r.entriesForTransaction)).
+		Watches(&redisv1alpha1.RedisConnection{}, handler.EnqueueRequestsFromMapFunc(r.entriesForConnection),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		WithOptions(crcontroller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles})
+	// Resync entries when the Secret or ConfigMap holding their value changes
+	if err := indexValueSources(ctx, mgr.GetFieldIndexer()); err != nil {
+		return err
+	}
+	valueSecrets, err := r.valueSecretSource(mgr)
+	if err != nil {
+		return err
+	}
+	bldr = bldr.WatchesRawSource(valueSecrets).
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.entriesForValueConfigMap),
+			builder.OnlyMetadata, builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}))
+	if r.NamespaceCredentials {
+		// Resync a namespace's entries when its credentials change
+		bldr = bldr.Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.entriesForCredentials))
+	}
+	return bldr.Named("redisentry").Complete(r)
 }
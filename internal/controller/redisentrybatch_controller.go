@@ -0,0 +1,120 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// RedisEntryBatchReconciler reconciles a RedisEntryBatch object by keeping
+// one owned RedisEntry per item and aggregating their status.
+type RedisEntryBatchReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=redis.aaspcodes.github.io,resources=redisentrybatches,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=redis.aaspcodes.github.io,resources=redisentrybatches/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=redis.aaspcodes.github.io,resources=redisentrybatches/finalizers,verbs=update
+
+// Reconcile creates, updates and deletes the batch's entries to match its
+// items, then records their aggregate status.
+func (r *RedisEntryBatchReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	batch := &redisv1alpha1.RedisEntryBatch{}
+	if err := r.Get(ctx, req.NamespacedName, batch); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Info("RedisEntryBatch resource not found. Ignoring since object must be deleted")
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Failed to get RedisEntryBatch")
+		return ctrl.Result{}, err
+	}
+	original := batch.DeepCopy()
+	pruneConditions(&batch.Status.Conditions, batchConditionTypes)
+
+	owned, err := listOwnedEntries(ctx, r.Client, r.Scheme, batch, redisv1alpha1.BatchEntryLabel)
+	if err != nil {
+		log.Error(err, "Failed to list RedisEntries of batch")
+		return ctrl.Result{}, err
+	}
+
+	var entries []*redisv1alpha1.RedisEntry
+	var conflicts []redisv1alpha1.KeyFailure
+	for _, item := range batch.Spec.Items {
+		entry, err := owned.apply(ctx, owned.entry(batch.Name, redisv1alpha1.RedisEntrySpec{
+			Key:   item.Key,
+			Value: item.Value,
+			TTL:   batch.Spec.TTL,
+		}))
+		if errors.Is(err, errNameTaken) {
+			conflicts = append(conflicts, redisv1alpha1.KeyFailure{Key: item.Key, Error: err.Error()})
+			continue
+		}
+		if err != nil {
+			log.Error(err, "Failed to apply RedisEntry of batch", "key", item.Key)
+			return ctrl.Result{}, err
+		}
+		entries = append(entries, entry)
+	}
+
+	deleted, err := owned.prune(ctx)
+	for _, entry := range deleted {
+		log.Info("Deleted RedisEntry of removed batch item", "name", entry.Name, "key", entry.Spec.Key)
+	}
+	if err != nil {
+		log.Error(err, "Failed to delete RedisEntry of removed batch item")
+		return ctrl.Result{}, err
+	}
+
+	summarizeBatch(batch, entries, conflicts)
+	if equality.Semantic.DeepEqual(original.Status, batch.Status) {
+		return ctrl.Result{}, nil
+	}
+	if err := r.Status().Update(ctx, batch); err != nil {
+		log.Error(err, "Failed to update RedisEntryBatch status")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// summarizeBatch records the aggregate status of the batch's entries.
+func summarizeBatch(batch *redisv1alpha1.RedisEntryBatch, entries []*redisv1alpha1.RedisEntry, conflicts []redisv1alpha1.KeyFailure) {
+	batch.Status.ObservedGeneration = batch.Generation
+	meta.SetStatusCondition(&batch.Status.Conditions,
+		summarizeOwned(&batch.Status.OwnedEntriesStatus, batch.Generation, entries, conflicts))
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *RedisEntryBatchReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&redisv1alpha1.RedisEntryBatch{}).
+		Owns(&redisv1alpha1.RedisEntry{}).
+		Named("redisentrybatch").
+		Complete(r)
+}
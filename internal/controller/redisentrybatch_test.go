@@ -0,0 +1,175 @@
+package controller
+
+import (
+	"context"
+	"strings"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = ginkgo.Describe("RedisEntryBatch Controller", func() {
+	var (
+		ctx        context.Context
+		reconciler *RedisEntryBatchReconciler
+		name       types.NamespacedName
+	)
+
+	ginkgo.BeforeEach(func() {
+		ctx = context.Background()
+		s := runtime.NewScheme()
+		gomega.Expect(redisv1alpha1.AddToScheme(s)).To(gomega.Succeed())
+
+		batch := &redisv1alpha1.RedisEntryBatch{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "batch",
+				Namespace:  "default",
+				UID:        "batch-uid",
+				Generation: 1,
+				Labels:     map[string]string{"team": "a"},
+			},
+			Spec: redisv1alpha1.RedisEntryBatchSpec{
+				Items: []redisv1alpha1.BatchItem{
+					{Key: "user:1", Value: "alice"},
+					{Key: "user:2", Value: "bob"},
+				},
+				TTL: ptr.To(int64(60)),
+			},
+		}
+		name = types.NamespacedName{Name: "batch", Namespace: "default"}
+		reconciler = &RedisEntryBatchReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(s).
+				WithObjects(batch).
+				WithStatusSubresource(&redisv1alpha1.RedisEntryBatch{}, &redisv1alpha1.RedisEntry{}).
+				Build(),
+			Scheme: s,
+		}
+	})
+
+	reconcileBatch := func() *redisv1alpha1.RedisEntryBatch {
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		batch := &redisv1alpha1.RedisEntryBatch{}
+		gomega.Expect(reconciler.Get(ctx, name, batch)).To(gomega.Succeed())
+		return batch
+	}
+
+	entryOf := func(key string) *redisv1alpha1.RedisEntry {
+		entry := &redisv1alpha1.RedisEntry{}
+		gomega.Expect(reconciler.Get(ctx, types.NamespacedName{
+			Name: ownedEntryName("batch", key), Namespace: "default",
+		}, entry)).To(gomega.Succeed())
+		return entry
+	}
+
+	ginkgo.It("should create an owned entry per item", func() {
+		batch := reconcileBatch()
+
+		entry := entryOf("user:1")
+		gomega.Expect(entry.Spec.Key).To(gomega.Equal("user:1"))
+		gomega.Expect(entry.Spec.Value).To(gomega.Equal("alice"))
+		gomega.Expect(entry.Spec.TTL).To(gomega.Equal(ptr.To(int64(60))))
+		gomega.Expect(entry.Labels).To(gomega.Equal(map[string]string{
+			"team":                        "a",
+			redisv1alpha1.BatchEntryLabel: "batch-uid",
+		}))
+		gomega.Expect(metav1.IsControlledBy(entry, batch)).To(gomega.BeTrue())
+
+		gomega.Expect(batch.Status.Entries).To(gomega.Equal(int32(2)))
+		gomega.Expect(batch.Status.Pending).To(gomega.Equal(int32(2)))
+		cond := meta.FindStatusCondition(batch.Status.Conditions, typeAvailable)
+		gomega.Expect(cond).NotTo(gomega.BeNil())
+		gomega.Expect(cond.Status).To(gomega.Equal(metav1.ConditionFalse))
+		gomega.Expect(cond.Reason).To(gomega.Equal(reasonEntriesPending))
+	})
+
+	ginkgo.It("should aggregate the status of its entries", func() {
+		reconcileBatch()
+
+		synced := entryOf("user:1")
+		synced.Status.Conditions = []metav1.Condition{{
+			Type: typeAvailable, Status: metav1.ConditionTrue, ObservedGeneration: synced.Generation,
+			Reason: reasonSuccess, LastTransitionTime: metav1.Now(),
+		}}
+		gomega.Expect(reconciler.Status().Update(ctx, synced)).To(gomega.Succeed())
+
+		failed := entryOf("user:2")
+		failed.Status.LastError = "OOM command not allowed"
+		failed.Status.Conditions = []metav1.Condition{{
+			Type: typeError, Status: metav1.ConditionTrue, ObservedGeneration: failed.Generation,
+			Reason: reasonRedisError, Message: "OOM command not allowed", LastTransitionTime: metav1.Now(),
+		}}
+		gomega.Expect(reconciler.Status().Update(ctx, failed)).To(gomega.Succeed())
+
+		batch := reconcileBatch()
+		gomega.Expect(batch.Status.Synced).To(gomega.Equal(int32(1)))
+		gomega.Expect(batch.Status.Failed).To(gomega.Equal(int32(1)))
+		gomega.Expect(batch.Status.Pending).To(gomega.BeZero())
+		gomega.Expect(batch.Status.FailedItems).To(gomega.Equal([]redisv1alpha1.KeyFailure{
+			{Key: "user:2", Error: "OOM command not allowed"},
+		}))
+		cond := meta.FindStatusCondition(batch.Status.Conditions, typeAvailable)
+		gomega.Expect(cond.Reason).To(gomega.Equal(reasonEntriesFailed))
+
+		failed = entryOf("user:2")
+		failed.Status = synced.Status
+		gomega.Expect(reconciler.Status().Update(ctx, failed)).To(gomega.Succeed())
+		batch = reconcileBatch()
+		gomega.Expect(batch.Status.Synced).To(gomega.Equal(int32(2)))
+		gomega.Expect(batch.Status.FailedItems).To(gomega.BeNil())
+		cond = meta.FindStatusCondition(batch.Status.Conditions, typeAvailable)
+		gomega.Expect(cond.Status).To(gomega.Equal(metav1.ConditionTrue))
+	})
+
+	ginkgo.It("should update and delete entries as items change", func() {
+		reconcileBatch()
+
+		batch := &redisv1alpha1.RedisEntryBatch{}
+		gomega.Expect(reconciler.Get(ctx, name, batch)).To(gomega.Succeed())
+		batch.Spec.Items = []redisv1alpha1.BatchItem{{Key: "user:1", Value: "carol"}}
+		gomega.Expect(reconciler.Update(ctx, batch)).To(gomega.Succeed())
+		batch = reconcileBatch()
+
+		gomega.Expect(entryOf("user:1").Spec.Value).To(gomega.Equal("carol"))
+		entries := &redisv1alpha1.RedisEntryList{}
+		gomega.Expect(reconciler.List(ctx, entries, client.InNamespace("default"))).To(gomega.Succeed())
+		gomega.Expect(entries.Items).To(gomega.HaveLen(1))
+		gomega.Expect(batch.Status.Entries).To(gomega.Equal(int32(1)))
+	})
+
+	ginkgo.It("should report items whose entry name is taken", func() {
+		taken := &redisv1alpha1.RedisEntry{
+			ObjectMeta: metav1.ObjectMeta{Name: ownedEntryName("batch", "user:2"), Namespace: "default"},
+			Spec:       redisv1alpha1.RedisEntrySpec{Key: "other", Value: "v"},
+		}
+		gomega.Expect(reconciler.Create(ctx, taken)).To(gomega.Succeed())
+
+		batch := reconcileBatch()
+		gomega.Expect(batch.Status.Entries).To(gomega.Equal(int32(1)))
+		gomega.Expect(batch.Status.Failed).To(gomega.Equal(int32(1)))
+		gomega.Expect(batch.Status.FailedItems).To(gomega.HaveLen(1))
+		gomega.Expect(batch.Status.FailedItems[0].Key).To(gomega.Equal("user:2"))
+
+		// The unowned entry is left alone
+		gomega.Expect(entryOf("user:2").Spec.Key).To(gomega.Equal("other"))
+	})
+
+	ginkgo.It("should derive valid, stable entry names", func() {
+		gomega.Expect(ownedEntryName("batch", "user:1")).To(gomega.Equal(ownedEntryName("batch", "user:1")))
+		gomega.Expect(ownedEntryName("batch", "user:1")).NotTo(gomega.Equal(ownedEntryName("batch", "user:2")))
+
+		long := ownedEntryName(strings.Repeat("a", 250)+".b", "user:1")
+		gomega.Expect(len(long)).To(gomega.BeNumerically("<=", maxObjectNameLength))
+		gomega.Expect(long).To(gomega.HavePrefix(strings.Repeat("a", 242) + "-"))
+	})
+})
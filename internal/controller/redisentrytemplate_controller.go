@@ -0,0 +1,250 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"text/template"
+	"time"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// reasonInvalidTemplate is used when a template fails to parse or render
+	reasonInvalidTemplate = "InvalidTemplate"
+
+	// reasonParametersError is used when parametersFrom cannot be read
+	reasonParametersError = "ParametersError"
+
+	// templateParametersRefresh is how often parameters from a ConfigMap are
+	// read again. ConfigMaps are not watched, so that the controller doesn't
+	// cache every ConfigMap in the cluster.
+	templateParametersRefresh = time.Minute
+)
+
+// RedisEntryTemplateReconciler reconciles a RedisEntryTemplate object by
+// keeping one owned RedisEntry per rendered key and aggregating their status.
+type RedisEntryTemplateReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// APIReader, when set, reads the ConfigMaps of parametersFrom from the
+	// API server; otherwise the client is used.
+	APIReader client.Reader
+}
+
+// +kubebuilder:rbac:groups=redis.aaspcodes.github.io,resources=redisentrytemplates,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=redis.aaspcodes.github.io,resources=redisentrytemplates/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=redis.aaspcodes.github.io,resources=redisentrytemplates/finalizers,verbs=update
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get
+
+// Reconcile renders the template for every parameter set, creates, updates
+// and deletes the template's entries to match, then records their aggregate
+// status.
+func (r *RedisEntryTemplateReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	tmpl := &redisv1alpha1.RedisEntryTemplate{}
+	if err := r.Get(ctx, req.NamespacedName, tmpl); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Info("RedisEntryTemplate resource not found. Ignoring since object must be deleted")
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Failed to get RedisEntryTemplate")
+		return ctrl.Result{}, err
+	}
+	original := tmpl.DeepCopy()
+	pruneConditions(&tmpl.Status.Conditions, templateConditionTypes)
+
+	// Parameters from a ConfigMap are read again periodically
+	var result ctrl.Result
+	if tmpl.Spec.ParametersFrom != nil {
+		result.RequeueAfter = templateParametersRefresh
+	}
+
+	sets, err := r.parameterSets(ctx, tmpl)
+	if err != nil {
+		if !errors.Is(err, errInvalidParameters) && !apierrors.IsNotFound(err) {
+			log.Error(err, "Failed to read template parameters")
+			return ctrl.Result{}, err
+		}
+		return r.fail(ctx, original, tmpl, reasonParametersError, err.Error(), result)
+	}
+	specs, err := renderTemplate(tmpl.Spec, sets)
+	if err != nil {
+		return r.fail(ctx, original, tmpl, reasonInvalidTemplate, err.Error(), result)
+	}
+
+	owned, err := listOwnedEntries(ctx, r.Client, r.Scheme, tmpl, redisv1alpha1.TemplateEntryLabel)
+	if err != nil {
+		log.Error(err, "Failed to list RedisEntries of template")
+		return ctrl.Result{}, err
+	}
+
+	var entries []*redisv1alpha1.RedisEntry
+	var conflicts []redisv1alpha1.KeyFailure
+	for _, spec := range specs {
+		entry, err := owned.apply(ctx, owned.entry(tmpl.Name, spec))
+		if errors.Is(err, errNameTaken) || apierrors.IsInvalid(err) {
+			conflicts = append(conflicts, redisv1alpha1.KeyFailure{Key: spec.Key, Error: err.Error()})
+			continue
+		}
+		if err != nil {
+			log.Error(err, "Failed to apply RedisEntry of template", "key", spec.Key)
+			return ctrl.Result{}, err
+		}
+		entries = append(entries, entry)
+	}
+
+	deleted, err := owned.prune(ctx)
+	for _, entry := range deleted {
+		log.Info("Deleted RedisEntry of removed parameter set", "name", entry.Name, "key", entry.Spec.Key)
+	}
+	if err != nil {
+		log.Error(err, "Failed to delete RedisEntry of removed parameter set")
+		return ctrl.Result{}, err
+	}
+
+	tmpl.Status.ObservedGeneration = tmpl.Generation
+	meta.RemoveStatusCondition(&tmpl.Status.Conditions, typeError)
+	meta.SetStatusCondition(&tmpl.Status.Conditions,
+		summarizeOwned(&tmpl.Status.OwnedEntriesStatus, tmpl.Generation, entries, conflicts))
+	if err := r.updateStatus(ctx, original, tmpl); err != nil {
+		return ctrl.Result{}, err
+	}
+	return result, nil
+}
+
+// fail records an error condition on the template. Its entries are left as
+// they are until the template or its parameters are fixed.
+func (r *RedisEntryTemplateReconciler) fail(ctx context.Context, original, tmpl *redisv1alpha1.RedisEntryTemplate,
+	reason, message string, result ctrl.Result) (ctrl.Result, error) {
+	meta.SetStatusCondition(&tmpl.Status.Conditions, metav1.Condition{
+		Type:               typeError,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: tmpl.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+	if err := r.updateStatus(ctx, original, tmpl); err != nil {
+		return ctrl.Result{}, err
+	}
+	return result, nil
+}
+
+// updateStatus writes the template's status if it changed.
+func (r *RedisEntryTemplateReconciler) updateStatus(ctx context.Context, original, tmpl *redisv1alpha1.RedisEntryTemplate) error {
+	if equality.Semantic.DeepEqual(original.Status, tmpl.Status) {
+		return nil
+	}
+	if err := r.Status().Update(ctx, tmpl); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to update RedisEntryTemplate status")
+		return err
+	}
+	return nil
+}
+
+// errInvalidParameters is returned when parametersFrom does not hold a list
+// of parameter sets.
+var errInvalidParameters = errors.New("invalid parameters")
+
+// parameterSets returns the template's inline parameter sets followed by
+// those read from parametersFrom.
+func (r *RedisEntryTemplateReconciler) parameterSets(ctx context.Context, tmpl *redisv1alpha1.RedisEntryTemplate) ([]map[string]string, error) {
+	sets := tmpl.Spec.Parameters
+	if tmpl.Spec.ParametersFrom == nil || tmpl.Spec.ParametersFrom.ConfigMapKeyRef == nil {
+		return sets, nil
+	}
+	ref := tmpl.Spec.ParametersFrom.ConfigMapKeyRef
+
+	reader := r.APIReader
+	if reader == nil {
+		reader = r.Client
+	}
+	configMap := &corev1.ConfigMap{}
+	if err := reader.Get(ctx, types.NamespacedName{Namespace: tmpl.Namespace, Name: ref.Name}, configMap); err != nil {
+		return nil, err
+	}
+	data, ok := configMap.Data[ref.Key]
+	if !ok {
+		return nil, fmt.Errorf("%w: ConfigMap %s has no key %s", errInvalidParameters, ref.Name, ref.Key)
+	}
+	var fromConfigMap []map[string]string
+	if err := yaml.Unmarshal([]byte(data), &fromConfigMap); err != nil {
+		return nil, fmt.Errorf("%w: key %s of ConfigMap %s must hold a list of parameter sets: %v",
+			errInvalidParameters, ref.Key, ref.Name, err)
+	}
+	return append(append([]map[string]string{}, sets...), fromConfigMap...), nil
+}
+
+// renderTemplate renders the key and value templates for every parameter
+// set. Two sets rendering the same key are an error.
+func renderTemplate(spec redisv1alpha1.RedisEntryTemplateSpec, sets []map[string]string) ([]redisv1alpha1.RedisEntrySpec, error) {
+	keyTemplate, err := template.New("key").Option("missingkey=error").Parse(spec.Key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key template: %w", err)
+	}
+	valueTemplate, err := template.New("value").Option("missingkey=error").Parse(spec.Value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value template: %w", err)
+	}
+
+	specs := make([]redisv1alpha1.RedisEntrySpec, 0, len(sets))
+	rendered := map[string]int{}
+	for i, params := range sets {
+		var key, value bytes.Buffer
+		if err := keyTemplate.Execute(&key, params); err != nil {
+			return nil, fmt.Errorf("failed to render key of parameter set %d: %w", i, err)
+		}
+		if err := valueTemplate.Execute(&value, params); err != nil {
+			return nil, fmt.Errorf("failed to render value of parameter set %d: %w", i, err)
+		}
+		if key.Len() == 0 {
+			return nil, fmt.Errorf("parameter set %d renders an empty key", i)
+		}
+		if first, ok := rendered[key.String()]; ok {
+			return nil, fmt.Errorf("parameter sets %d and %d both render key %q", first, i, key.String())
+		}
+		rendered[key.String()] = i
+		specs = append(specs, redisv1alpha1.RedisEntrySpec{Key: key.String(), Value: value.String(), TTL: spec.TTL})
+	}
+	return specs, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *RedisEntryTemplateReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&redisv1alpha1.RedisEntryTemplate{}).
+		Owns(&redisv1alpha1.RedisEntry{}).
+		Named("redisentrytemplate").
+		Complete(r)
+}
@@ -0,0 +1,139 @@
+package controller
+
+import (
+	"context"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = ginkgo.Describe("RedisEntryTemplate Controller", func() {
+	var (
+		ctx        context.Context
+		reconciler *RedisEntryTemplateReconciler
+		name       types.NamespacedName
+	)
+
+	ginkgo.BeforeEach(func() {
+		ctx = context.Background()
+		s := runtime.NewScheme()
+		gomega.Expect(clientgoscheme.AddToScheme(s)).To(gomega.Succeed())
+		gomega.Expect(redisv1alpha1.AddToScheme(s)).To(gomega.Succeed())
+
+		tmpl := &redisv1alpha1.RedisEntryTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "limits", Namespace: "default", UID: "limits-uid", Generation: 1},
+			Spec: redisv1alpha1.RedisEntryTemplateSpec{
+				Key:   "ratelimit:{{ .region }}",
+				Value: "{{ .limit }}",
+				Parameters: []map[string]string{
+					{"region": "us", "limit": "100"},
+				},
+				ParametersFrom: &redisv1alpha1.ParametersSource{
+					ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "regions"},
+						Key:                  "regions.yaml",
+					},
+				},
+			},
+		}
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "regions", Namespace: "default"},
+			Data: map[string]string{
+				"regions.yaml": "- region: eu\n  limit: \"50\"\n- region: ap\n  limit: \"20\"\n",
+			},
+		}
+		name = types.NamespacedName{Name: "limits", Namespace: "default"}
+		reconciler = &RedisEntryTemplateReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(s).
+				WithObjects(tmpl, configMap).
+				WithStatusSubresource(&redisv1alpha1.RedisEntryTemplate{}).
+				Build(),
+			Scheme: s,
+		}
+	})
+
+	reconcileTemplate := func() (reconcile.Result, *redisv1alpha1.RedisEntryTemplate) {
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		tmpl := &redisv1alpha1.RedisEntryTemplate{}
+		gomega.Expect(reconciler.Get(ctx, name, tmpl)).To(gomega.Succeed())
+		return result, tmpl
+	}
+
+	entryValues := func() map[string]string {
+		entries := &redisv1alpha1.RedisEntryList{}
+		gomega.Expect(reconciler.List(ctx, entries, client.InNamespace("default"))).To(gomega.Succeed())
+		values := map[string]string{}
+		for _, entry := range entries.Items {
+			values[entry.Spec.Key] = entry.Spec.Value
+		}
+		return values
+	}
+
+	ginkgo.It("should expand the template across inline and ConfigMap parameters", func() {
+		result, tmpl := reconcileTemplate()
+		gomega.Expect(result.RequeueAfter).To(gomega.Equal(templateParametersRefresh))
+		gomega.Expect(entryValues()).To(gomega.Equal(map[string]string{
+			"ratelimit:us": "100",
+			"ratelimit:eu": "50",
+			"ratelimit:ap": "20",
+		}))
+		gomega.Expect(tmpl.Status.Entries).To(gomega.Equal(int32(3)))
+		gomega.Expect(tmpl.Status.Pending).To(gomega.Equal(int32(3)))
+	})
+
+	ginkgo.It("should prune entries of removed parameter sets", func() {
+		reconcileTemplate()
+
+		configMap := &corev1.ConfigMap{}
+		gomega.Expect(reconciler.Get(ctx, types.NamespacedName{Name: "regions", Namespace: "default"}, configMap)).To(gomega.Succeed())
+		configMap.Data["regions.yaml"] = "- region: eu\n  limit: \"75\"\n"
+		gomega.Expect(reconciler.Update(ctx, configMap)).To(gomega.Succeed())
+
+		_, tmpl := reconcileTemplate()
+		gomega.Expect(entryValues()).To(gomega.Equal(map[string]string{
+			"ratelimit:us": "100",
+			"ratelimit:eu": "75",
+		}))
+		gomega.Expect(tmpl.Status.Entries).To(gomega.Equal(int32(2)))
+	})
+
+	ginkgo.It("should keep entries and report an error when parameters are missing", func() {
+		reconcileTemplate()
+
+		gomega.Expect(reconciler.Delete(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "regions", Namespace: "default"},
+		})).To(gomega.Succeed())
+		result, tmpl := reconcileTemplate()
+		gomega.Expect(result.RequeueAfter).To(gomega.Equal(templateParametersRefresh))
+		gomega.Expect(entryValues()).To(gomega.HaveLen(3))
+
+		cond := meta.FindStatusCondition(tmpl.Status.Conditions, typeError)
+		gomega.Expect(cond).NotTo(gomega.BeNil())
+		gomega.Expect(cond.Reason).To(gomega.Equal(reasonParametersError))
+	})
+
+	ginkgo.It("should reject templates that fail to render", func() {
+		spec := redisv1alpha1.RedisEntryTemplateSpec{Key: "limit:{{ .region }}"}
+
+		_, err := renderTemplate(spec, []map[string]string{{"zone": "a"}})
+		gomega.Expect(err).To(gomega.MatchError(gomega.ContainSubstring("parameter set 0")))
+
+		_, err = renderTemplate(spec, []map[string]string{{"region": "a"}, {"region": "a"}})
+		gomega.Expect(err).To(gomega.MatchError(gomega.ContainSubstring(`both render key "limit:a"`)))
+
+		_, err = renderTemplate(redisv1alpha1.RedisEntryTemplateSpec{Key: "{{ .region"}, nil)
+		gomega.Expect(err).To(gomega.MatchError(gomega.ContainSubstring("invalid key template")))
+	})
+})
@@ -0,0 +1,533 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	// reasonEntryNotFound is used when a transaction lists a missing entry
+	reasonEntryNotFound = "EntryNotFound"
+
+	// reasonRolledBack is used when a step failed and the written keys were
+	// restored
+	reasonRolledBack = "RolledBack"
+
+	// reasonRollbackFailed is used when restoring the written keys failed
+	reasonRollbackFailed = "RollbackFailed"
+
+	// reasonMixedConnections is used when the entries of a transaction are
+	// written to different RedisConnections
+	reasonMixedConnections = "MixedConnections"
+)
+
+// RedisTransactionReconciler reconciles a RedisTransaction object by writing
+// its entries one after another and restoring the keys already written when
+// a later entry fails.
+type RedisTransactionReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// Entries resolves and writes the entries with the settings of the
+	// RedisEntry controller.
+	Entries *RedisEntryReconciler
+
+	// failures counts consecutive failed attempts per transaction for the
+	// retry policy of the failed entry
+	failures failureTracker
+}
+
+// +kubebuilder:rbac:groups=redis.aaspcodes.github.io,resources=redistransactions,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=redis.aaspcodes.github.io,resources=redistransactions/status,verbs=get;update;patch
+
+// Reconcile applies the transaction whenever it or one of its entries
+// changed since the last successful attempt.
+func (r *RedisTransactionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	if r.Entries != nil {
+		if !r.Entries.drain.begin() {
+			return ctrl.Result{RequeueAfter: drainRequeueDelay}, nil
+		}
+		defer r.Entries.drain.end()
+	}
+
+	tx := &redisv1alpha1.RedisTransaction{}
+	if err := r.Get(ctx, req.NamespacedName, tx); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Info("RedisTransaction resource not found. Ignoring since object must be deleted")
+			r.failures.reset(req.NamespacedName)
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Failed to get RedisTransaction")
+		return ctrl.Result{}, err
+	}
+	pruneConditions(&tx.Status.Conditions, transactionConditionTypes)
+
+	entries := make([]*redisv1alpha1.RedisEntry, len(tx.Spec.Entries))
+	for i, name := range tx.Spec.Entries {
+		entry := &redisv1alpha1.RedisEntry{}
+		err := r.Get(ctx, types.NamespacedName{Namespace: tx.Namespace, Name: name}, entry)
+		// Entries being deleted get their keys deleted, not written
+		if apierrors.IsNotFound(err) || (err == nil && !entry.DeletionTimestamp.IsZero()) {
+			// Creating the entry triggers another attempt
+			return r.fail(ctx, tx, reasonEntryNotFound, fmt.Sprintf("RedisEntry %q not found", name), 0)
+		}
+		if err != nil {
+			log.Error(err, "Failed to get RedisEntry of transaction", "entry", name)
+			return ctrl.Result{}, err
+		}
+		entries[i] = entry
+	}
+
+	if r.Entries == nil || r.Entries.RedisClient == nil {
+		log.Error(nil, "Redis client not initialized")
+		return r.fail(ctx, tx, "RedisClientNotInitialized", "Redis client is not initialized", redisErrorRetryDelay)
+	}
+	// Values are resolved up front, so changes to their Secrets and
+	// ConfigMaps are applied again
+	steps := make([]*transactionStep, len(entries))
+	for i, entry := range entries {
+		step, reason, err := r.prepare(ctx, entry)
+		if err != nil {
+			log.Error(err, "Failed to prepare RedisEntry of transaction", "entry", entry.Name)
+			delay := redisErrorRetryDelay
+			if reason != reasonValueSourceError {
+				// Retrying cannot help until the entry changes
+				delay = 0
+			}
+			return r.fail(ctx, tx, reason, fmt.Sprintf("RedisEntry %q: %v", entry.Name, err), delay)
+		}
+		steps[i] = step
+	}
+	if isApplied(tx, steps) {
+		return ctrl.Result{}, nil
+	}
+
+	// Keys of different servers cannot be rolled back together
+	if name, ok := sameConnection(entries); !ok {
+		return r.fail(ctx, tx, reasonMixedConnections,
+			fmt.Sprintf("RedisEntry %q uses a different RedisConnection than %q", name, entries[0].Name), 0)
+	}
+	redisClient, err := r.Entries.redisClientForEntry(ctx, entries[0])
+	if err != nil {
+		log.Error(err, "Failed to get Redis client for transaction")
+		reason := reasonCredentialsError
+		if entries[0].Spec.ConnectionRef != nil {
+			reason = reasonConnectionError
+		}
+		return r.fail(ctx, tx, reason, err.Error(), redisErrorRetryDelay)
+	}
+	defer redisClient.release()
+
+	// Each step is held to the checks, limits and timeout of its entry
+	if message := r.Entries.insufficientPermissions(ctx, redisClient); message != "" {
+		log.Info("Skipping transaction due to insufficient Redis permissions")
+		return r.fail(ctx, tx, reasonInsufficientPermissions, message, permissionRecheckInterval)
+	}
+	for _, step := range steps {
+		if err := r.Entries.waitForWriteBudget(ctx, step.entry); err != nil {
+			log.Error(err, "Failed waiting for the Redis write rate limit")
+			return ctrl.Result{}, err
+		}
+		step.store = r.Entries.store(r.Entries.withCommandTimeout(redisClient.client, step.entry), redisClient.server)
+	}
+
+	if err := capturePrior(ctx, steps); err != nil {
+		log.Error(err, "Failed to read prior values of transaction keys")
+		return r.fail(ctx, tx, reasonRedisError, err.Error(), redisErrorRetryDelay)
+	}
+
+	now := metav1.NewTime(r.Entries.now())
+	tx.Status.LastAttemptTime = &now
+	failed := applySteps(ctx, steps)
+	tx.Status.Steps = make([]redisv1alpha1.TransactionStep, len(steps))
+	for i, step := range steps {
+		tx.Status.Steps[i] = step.status
+	}
+	if failed < 0 {
+		r.failures.reset(req.NamespacedName)
+		if err := r.recordApplied(ctx, steps, now); err != nil {
+			return ctrl.Result{}, err
+		}
+		meta.RemoveStatusCondition(&tx.Status.Conditions, typeError)
+		r.setCondition(tx, typeAvailable, reasonSuccess, fmt.Sprintf("Applied %d entries", len(steps)))
+		log.Info("Applied RedisTransaction", "entries", len(steps))
+		return ctrl.Result{}, r.updateStatus(ctx, tx)
+	}
+
+	reason := reasonRolledBack
+	message := fmt.Sprintf("RedisEntry %q failed, restored the keys already written: %s",
+		steps[failed].entry.Name, steps[failed].status.Error)
+	if i := slices.IndexFunc(steps, func(s *transactionStep) bool {
+		return s.status.Result == redisv1alpha1.StepRollbackFailed
+	}); i >= 0 {
+		reason = reasonRollbackFailed
+		message = fmt.Sprintf("RedisEntry %q failed and restoring the keys of %q failed too: %s",
+			steps[failed].entry.Name, steps[i].entry.Name, steps[i].status.Error)
+	}
+	log.Info("RedisTransaction failed", "entry", steps[failed].entry.Name, "reason", reason)
+	delay := redisErrorRetryDelay
+	failures := r.failures.inc(req.NamespacedName)
+	if policy := steps[failed].entry.Spec.RetryPolicy; policy != nil {
+		delay = retryDelay(policy, failures)
+	}
+	return r.fail(ctx, tx, reason, message, delay)
+}
+
+// recordApplied records the write in the status of every entry of an applied
+// transaction, as a sync of the entry would.
+func (r *RedisTransactionReconciler) recordApplied(ctx context.Context, steps []*transactionStep, now metav1.Time) error {
+	for _, step := range steps {
+		entry := step.entry
+		original := entry.Status.DeepCopy()
+		entry.Status.LastUpdated = &now
+		entry.Status.LastError = ""
+		entry.Status.FailedKeys = nil
+		// A value read back before is replaced by the one just written
+		if entry.Status.LastRefreshed != nil {
+			entry.Status.CurrentValue = statusValue(entry, step.values[0])
+		}
+		if err := r.Entries.updateStatus(ctx, entry, original); err != nil {
+			log.FromContext(ctx).Error(err, "Failed to update status of RedisEntry of transaction", "entry", entry.Name)
+			return err
+		}
+	}
+	return nil
+}
+
+// transactionStep is the write of one entry of a transaction together with
+// what its keys held before.
+type transactionStep struct {
+	entry  *redisv1alpha1.RedisEntry
+	keys   []string
+	values []string
+	ttl    time.Duration
+
+	// store writes the step with the command timeout of its entry
+	store KVStore
+
+	// prior holds the values before the transaction, nil for missing keys,
+	// and priorTTL their remaining time to live
+	prior    []*string
+	priorTTL []time.Duration
+
+	status redisv1alpha1.TransactionStep
+}
+
+// prepare resolves the keys and values an entry writes. The returned reason
+// classifies errors for the transaction's condition.
+func (r *RedisTransactionReconciler) prepare(ctx context.Context, entry *redisv1alpha1.RedisEntry) (*transactionStep, string, error) {
+	if key, reserved := r.Entries.reservedKey(entry); reserved {
+		return nil, reasonReservedKey, fmt.Errorf("key %q uses a reserved prefix", key)
+	}
+	if err := r.Entries.invalidKey(entry); err != nil {
+		return nil, reasonInvalidKey, err
+	}
+	if entry.Spec.ChunkSizeBytes != nil {
+		// Chunk keys are tracked in the entry's status, which transactions
+		// don't maintain
+		return nil, reasonInvalidKey, errors.New("chunked entries cannot be written by a transaction")
+	}
+	value, err := r.Entries.resolveValue(ctx, entry)
+	if err != nil {
+		return nil, reasonValueSourceError, err
+	}
+	step := &transactionStep{
+		entry: entry,
+		status: redisv1alpha1.TransactionStep{
+			Entry:      entry.Name,
+			Generation: entry.Generation,
+			ValueHash:  valueChecksum(value),
+		},
+	}
+	step.keys, step.values, _ = desiredState(entry, value)
+	if entry.Spec.TTL != nil {
+		step.ttl = time.Duration(*entry.Spec.TTL) * time.Second
+	}
+	return step, "", nil
+}
+
+// capturePrior reads the values and TTLs every step's keys hold before the
+// transaction writes them.
+func capturePrior(ctx context.Context, steps []*transactionStep) error {
+	for _, step := range steps {
+		values, err := step.store.Get(ctx, step.keys...)
+		if err != nil {
+			return err
+		}
+		ttls, err := step.store.TTL(ctx, step.keys...)
+		if err != nil {
+			return err
+		}
+		step.prior, step.priorTTL = values, ttls
+	}
+	return nil
+}
+
+// applySteps writes the steps in order. When one fails, it and every step
+// before it are restored, the last written first. It returns the index of
+// the failed step, or -1 when all were applied.
+func applySteps(ctx context.Context, steps []*transactionStep) int {
+	for i, step := range steps {
+		_, err := step.store.Pipeline(ctx, !step.store.CrossSlot(step.keys...), func(pipe KVPipe) {
+			for j, key := range step.keys {
+				pipe.Set(key, step.values[j], step.ttl)
+			}
+		})
+		if err == nil {
+			step.status.Result = redisv1alpha1.StepApplied
+			continue
+		}
+
+		step.status.Result = redisv1alpha1.StepFailed
+		step.status.Error = err.Error()
+		for _, skipped := range steps[i+1:] {
+			skipped.status.Result = redisv1alpha1.StepSkipped
+		}
+		// The failed step may have written some of its keys
+		for j := i; j >= 0; j-- {
+			rollback(ctx, steps[j])
+		}
+		return i
+	}
+	return -1
+}
+
+// rollback restores the keys of a step to their prior values and TTLs,
+// deleting those that did not exist.
+func rollback(ctx context.Context, step *transactionStep) {
+	_, err := step.store.Pipeline(ctx, !step.store.CrossSlot(step.keys...), func(pipe KVPipe) {
+		for i, key := range step.keys {
+			if step.prior[i] == nil {
+				pipe.Del(key)
+				continue
+			}
+			pipe.Set(key, *step.prior[i], max(step.priorTTL[i], 0))
+		}
+	})
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to roll back RedisEntry of transaction", "entry", step.entry.Name)
+		step.status.Result = redisv1alpha1.StepRollbackFailed
+		step.status.Error = errors.Join(stepError(step), err).Error()
+		return
+	}
+	if step.status.Result == redisv1alpha1.StepApplied {
+		step.status.Result = redisv1alpha1.StepRolledBack
+	}
+}
+
+// stepError returns the error a step failed with, if any.
+func stepError(step *transactionStep) error {
+	if step.status.Error == "" {
+		return nil
+	}
+	return errors.New(step.status.Error)
+}
+
+// sameConnection reports whether all entries use the same RedisConnection,
+// and otherwise returns the first entry that does not.
+func sameConnection(entries []*redisv1alpha1.RedisEntry) (string, bool) {
+	connection := func(entry *redisv1alpha1.RedisEntry) string {
+		if entry.Spec.ConnectionRef == nil {
+			return ""
+		}
+		return entry.Spec.ConnectionRef.Name
+	}
+	for _, entry := range entries[1:] {
+		if connection(entry) != connection(entries[0]) {
+			return entry.Name, false
+		}
+	}
+	return "", true
+}
+
+// isApplied reports whether the last attempt applied the current generation
+// of the transaction and of each of its entries, with the values they
+// resolve to now.
+func isApplied(tx *redisv1alpha1.RedisTransaction, steps []*transactionStep) bool {
+	cond := meta.FindStatusCondition(tx.Status.Conditions, typeAvailable)
+	if cond == nil || cond.Status != metav1.ConditionTrue || cond.ObservedGeneration != tx.Generation ||
+		len(tx.Status.Steps) != len(steps) {
+		return false
+	}
+	for i, applied := range tx.Status.Steps {
+		step := steps[i].status
+		if applied.Entry != step.Entry || applied.Generation != step.Generation ||
+			applied.ValueHash != step.ValueHash || applied.Result != redisv1alpha1.StepApplied {
+			return false
+		}
+	}
+	return true
+}
+
+// setCondition sets a condition of the transaction to True.
+func (r *RedisTransactionReconciler) setCondition(tx *redisv1alpha1.RedisTransaction, conditionType, reason, message string) {
+	now := time.Now()
+	if r.Entries != nil {
+		now = r.Entries.now()
+	}
+	meta.SetStatusCondition(&tx.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: tx.Generation,
+		Reason:             reason,
+		Message:            message,
+		LastTransitionTime: metav1.NewTime(now),
+	})
+}
+
+// fail records an Error condition on the transaction. A zero requeueAfter
+// waits for the transaction or its entries to change.
+func (r *RedisTransactionReconciler) fail(ctx context.Context, tx *redisv1alpha1.RedisTransaction,
+	reason, message string, requeueAfter time.Duration) (ctrl.Result, error) {
+	meta.RemoveStatusCondition(&tx.Status.Conditions, typeAvailable)
+	r.setCondition(tx, typeError, reason, message)
+	if err := r.updateStatus(ctx, tx); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// updateStatus writes the transaction's status.
+func (r *RedisTransactionReconciler) updateStatus(ctx context.Context, tx *redisv1alpha1.RedisTransaction) error {
+	if err := r.Status().Update(ctx, tx); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to update RedisTransaction status")
+		return err
+	}
+	return nil
+}
+
+// transactionsFor returns the transactions listing an entry.
+func transactionsFor(ctx context.Context, c client.Reader, entry client.Object) ([]redisv1alpha1.RedisTransaction, error) {
+	transactions := &redisv1alpha1.RedisTransactionList{}
+	if err := c.List(ctx, transactions, client.InNamespace(entry.GetNamespace())); err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(transactions.Items, func(tx redisv1alpha1.RedisTransaction) bool {
+		return !slices.Contains(tx.Spec.Entries, entry.GetName())
+	}), nil
+}
+
+// transactionsForEntry maps a change to a RedisEntry to the transactions
+// listing it.
+func (r *RedisTransactionReconciler) transactionsForEntry(ctx context.Context, obj client.Object) []ctrl.Request {
+	transactions, err := transactionsFor(ctx, r.Client, obj)
+	if err != nil {
+		return nil
+	}
+	requests := make([]ctrl.Request, len(transactions))
+	for i := range transactions {
+		requests[i] = ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&transactions[i])}
+	}
+	return requests
+}
+
+// inTransaction reports whether a RedisTransaction lists the entry, in which
+// case only the transaction writes it.
+func (r *RedisEntryReconciler) inTransaction(ctx context.Context, redisEntry *redisv1alpha1.RedisEntry) (bool, error) {
+	transactions, err := transactionsFor(ctx, r.Client, redisEntry)
+	return len(transactions) > 0, err
+}
+
+// entriesForTransaction maps a change to a RedisTransaction to its entries,
+// so they are written by the RedisEntry controller again once the
+// transaction no longer lists them.
+func (r *RedisEntryReconciler) entriesForTransaction(_ context.Context, obj client.Object) []ctrl.Request {
+	tx, ok := obj.(*redisv1alpha1.RedisTransaction)
+	if !ok {
+		return nil
+	}
+	requests := make([]ctrl.Request, len(tx.Spec.Entries))
+	for i, name := range tx.Spec.Entries {
+		requests[i] = ctrl.Request{NamespacedName: types.NamespacedName{Namespace: tx.Namespace, Name: name}}
+		r.Metrics.enqueued(requests[i].NamespacedName, backlogOther)
+	}
+	return requests
+}
+
+// transactionsForValueSecret maps a change to a Secret to the transactions
+// listing an entry that reads its value from it.
+func (r *RedisTransactionReconciler) transactionsForValueSecret(ctx context.Context,
+	secret *metav1.PartialObjectMetadata) []ctrl.Request {
+	return r.transactionsReading(ctx, secret, valueSecretField)
+}
+
+// transactionsForValueConfigMap maps a change to a ConfigMap to the
+// transactions listing an entry that reads its value from it.
+func (r *RedisTransactionReconciler) transactionsForValueConfigMap(ctx context.Context, configMap client.Object) []ctrl.Request {
+	return r.transactionsReading(ctx, configMap, valueConfigMapField)
+}
+
+// transactionsReading returns the transactions listing an entry whose value
+// source, as indexed by field, is obj. The RedisEntry controller skips these
+// entries, so the transaction has to pick up the change.
+func (r *RedisTransactionReconciler) transactionsReading(ctx context.Context, obj client.Object, field string) []ctrl.Request {
+	entries := &redisv1alpha1.RedisEntryList{}
+	if err := r.List(ctx, entries, client.InNamespace(obj.GetNamespace()),
+		client.MatchingFields{field: obj.GetName()}); err != nil {
+		return nil
+	}
+	var requests []ctrl.Request
+	for i := range entries.Items {
+		for _, request := range r.transactionsForEntry(ctx, &entries.Items[i]) {
+			if !slices.Contains(requests, request) {
+				requests = append(requests, request)
+			}
+		}
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager. It relies on the
+// value source indexes and Secret metadata cache set up by the RedisEntry
+// controller.
+func (r *RedisTransactionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	bldr := ctrl.NewControllerManagedBy(mgr).
+		For(&redisv1alpha1.RedisTransaction{}).
+		Watches(&redisv1alpha1.RedisEntry{}, handler.EnqueueRequestsFromMapFunc(r.transactionsForEntry)).
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.transactionsForValueConfigMap),
+			builder.OnlyMetadata, builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}))
+	if r.Entries != nil && r.Entries.valueSecrets != nil {
+		secret := &metav1.PartialObjectMetadata{}
+		secret.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Secret"))
+		bldr = bldr.WatchesRawSource(source.Kind(r.Entries.valueSecrets, secret,
+			handler.TypedEnqueueRequestsFromMapFunc(r.transactionsForValueSecret),
+			predicate.TypedResourceVersionChangedPredicate[*metav1.PartialObjectMetadata]{}))
+	}
+	return bldr.Named("redistransaction").Complete(r)
+}
@@ -0,0 +1,265 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	redismock "github.com/go-redis/redismock/v9"
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clocktesting "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = ginkgo.Describe("RedisTransaction Controller", func() {
+	var (
+		ctx        context.Context
+		mock       redismock.ClientMock
+		clock      *clocktesting.FakePassiveClock
+		entries    *RedisEntryReconciler
+		reconciler *RedisTransactionReconciler
+		name       types.NamespacedName
+	)
+
+	ginkgo.BeforeEach(func() {
+		ctx = context.Background()
+		s := runtime.NewScheme()
+		gomega.Expect(redisv1alpha1.AddToScheme(s)).To(gomega.Succeed())
+		gomega.Expect(corev1.AddToScheme(s)).To(gomega.Succeed())
+
+		tx := &redisv1alpha1.RedisTransaction{
+			ObjectMeta: metav1.ObjectMeta{Name: "rollout", Namespace: "default", Generation: 1},
+			Spec:       redisv1alpha1.RedisTransactionSpec{Entries: []string{"first", "second"}},
+		}
+		first := &redisv1alpha1.RedisEntry{
+			ObjectMeta: metav1.ObjectMeta{Name: "first", Namespace: "default", Generation: 1},
+			Spec:       redisv1alpha1.RedisEntrySpec{Key: "a", Value: "1"},
+		}
+		second := &redisv1alpha1.RedisEntry{
+			ObjectMeta: metav1.ObjectMeta{Name: "second", Namespace: "default", Generation: 1},
+			Spec: redisv1alpha1.RedisEntrySpec{
+				Key: "b",
+				ValueFrom: &redisv1alpha1.ValueSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "pricing"},
+					Key:                  "b",
+				}},
+			},
+		}
+		pricing := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "pricing", Namespace: "default"},
+			Data:       map[string]string{"b": "2"},
+		}
+		name = types.NamespacedName{Name: "rollout", Namespace: "default"}
+
+		mockRedis, m := redismock.NewClientMock()
+		mock = m
+		c := fake.NewClientBuilder().
+			WithScheme(s).
+			WithObjects(tx, first, second, pricing).
+			WithIndex(&redisv1alpha1.RedisEntry{}, valueConfigMapField, entryIndexer(valueConfigMap)).
+			WithStatusSubresource(&redisv1alpha1.RedisTransaction{}, &redisv1alpha1.RedisEntry{}).
+			Build()
+		clock = clocktesting.NewFakePassiveClock(time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC))
+		entries = &RedisEntryReconciler{Client: c, Scheme: s, RedisClient: mockRedis, Clock: clock}
+		reconciler = &RedisTransactionReconciler{Client: c, Scheme: s, Entries: entries}
+	})
+
+	ginkgo.AfterEach(func() {
+		gomega.Expect(mock.ExpectationsWereMet()).To(gomega.Succeed())
+	})
+
+	expectPrior := func() {
+		mock.ExpectMGet("a").SetVal([]interface{}{"old"})
+		mock.ExpectPTTL("a").SetVal(time.Duration(-1))
+		mock.ExpectMGet("b").SetVal([]interface{}{nil})
+		mock.ExpectPTTL("b").SetVal(time.Duration(-2))
+	}
+
+	reconcileTransaction := func() (reconcile.Result, *redisv1alpha1.RedisTransaction) {
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		tx := &redisv1alpha1.RedisTransaction{}
+		gomega.Expect(reconciler.Get(ctx, name, tx)).To(gomega.Succeed())
+		return result, tx
+	}
+
+	ginkgo.It("should apply every entry once per generation", func() {
+		expectPrior()
+		mock.ExpectTxPipeline()
+		mock.ExpectSet("a", "1", 0).SetVal("OK")
+		mock.ExpectTxPipelineExec()
+		mock.ExpectTxPipeline()
+		mock.ExpectSet("b", "2", 0).SetVal("OK")
+		mock.ExpectTxPipelineExec()
+
+		result, tx := reconcileTransaction()
+		gomega.Expect(result).To(gomega.Equal(reconcile.Result{}))
+		gomega.Expect(meta.IsStatusConditionTrue(tx.Status.Conditions, typeAvailable)).To(gomega.BeTrue())
+		gomega.Expect(tx.Status.Steps).To(gomega.Equal([]redisv1alpha1.TransactionStep{
+			{Entry: "first", Generation: 1, ValueHash: valueChecksum("1"), Result: redisv1alpha1.StepApplied},
+			{Entry: "second", Generation: 1, ValueHash: valueChecksum("2"), Result: redisv1alpha1.StepApplied},
+		}))
+		gomega.Expect(tx.Status.LastAttemptTime.Time).To(gomega.BeTemporally("==", clock.Now()))
+
+		// The entries record the write like one of their own syncs
+		first := &redisv1alpha1.RedisEntry{}
+		gomega.Expect(reconciler.Get(ctx, types.NamespacedName{Name: "first", Namespace: "default"}, first)).To(gomega.Succeed())
+		gomega.Expect(first.Status.LastUpdated.Time).To(gomega.BeTemporally("==", clock.Now()))
+
+		// Nothing changed, so nothing is written again
+		reconcileTransaction()
+	})
+
+	ginkgo.It("should replace a value read back before with the one applied", func() {
+		second := &redisv1alpha1.RedisEntry{}
+		secondName := types.NamespacedName{Name: "second", Namespace: "default"}
+		gomega.Expect(reconciler.Get(ctx, secondName, second)).To(gomega.Succeed())
+		refreshed := metav1.NewTime(clock.Now().Add(-time.Hour))
+		second.Status.CurrentValue = "old"
+		second.Status.LastRefreshed = &refreshed
+		gomega.Expect(reconciler.Status().Update(ctx, second)).To(gomega.Succeed())
+
+		expectPrior()
+		mock.ExpectTxPipeline()
+		mock.ExpectSet("a", "1", 0).SetVal("OK")
+		mock.ExpectTxPipelineExec()
+		mock.ExpectTxPipeline()
+		mock.ExpectSet("b", "2", 0).SetVal("OK")
+		mock.ExpectTxPipelineExec()
+		reconcileTransaction()
+
+		gomega.Expect(reconciler.Get(ctx, secondName, second)).To(gomega.Succeed())
+		gomega.Expect(second.Status.CurrentValue).To(gomega.Equal("2"))
+		gomega.Expect(second.Status.LastUpdated.Time).To(gomega.BeTemporally("==", clock.Now()))
+	})
+
+	ginkgo.It("should retry with the retry policy of the failed entry", func() {
+		second := &redisv1alpha1.RedisEntry{}
+		gomega.Expect(reconciler.Get(ctx, types.NamespacedName{Name: "second", Namespace: "default"}, second)).To(gomega.Succeed())
+		second.Spec.RetryPolicy = &redisv1alpha1.RetryPolicy{InitialDelay: &metav1.Duration{Duration: time.Second}}
+		gomega.Expect(reconciler.Update(ctx, second)).To(gomega.Succeed())
+
+		for _, delay := range []time.Duration{time.Second, 2 * time.Second} {
+			expectPrior()
+			mock.ExpectTxPipeline()
+			mock.ExpectSet("a", "1", 0).SetVal("OK")
+			mock.ExpectTxPipelineExec()
+			mock.ExpectTxPipeline()
+			mock.ExpectSet("b", "2", 0).SetErr(errors.New("OOM command not allowed when used memory > 'maxmemory'"))
+			mock.ExpectTxPipeline()
+			mock.ExpectDel("b").SetVal(0)
+			mock.ExpectTxPipelineExec()
+			mock.ExpectTxPipeline()
+			mock.ExpectSet("a", "old", 0).SetVal("OK")
+			mock.ExpectTxPipelineExec()
+
+			result, _ := reconcileTransaction()
+			gomega.Expect(result.RequeueAfter).To(gomega.Equal(delay))
+		}
+	})
+
+	ginkgo.It("should not write when the Redis user lacks permissions", func() {
+		entries.Server = &ServerInfo{Version: "7.2.0", major: 7, minor: 2}
+		entries.permissions.checked = true
+		entries.permissions.user = "ctrl"
+		entries.permissions.missing = []string{"SET"}
+		entries.permissions.checkedAt = time.Now()
+
+		result, tx := reconcileTransaction()
+		gomega.Expect(result.RequeueAfter).To(gomega.Equal(permissionRecheckInterval))
+		cond := meta.FindStatusCondition(tx.Status.Conditions, typeError)
+		gomega.Expect(cond).NotTo(gomega.BeNil())
+		gomega.Expect(cond.Reason).To(gomega.Equal(reasonInsufficientPermissions))
+	})
+
+	ginkgo.It("should apply the transaction again when a value source changes", func() {
+		expectPrior()
+		mock.ExpectTxPipeline()
+		mock.ExpectSet("a", "1", 0).SetVal("OK")
+		mock.ExpectTxPipelineExec()
+		mock.ExpectTxPipeline()
+		mock.ExpectSet("b", "2", 0).SetVal("OK")
+		mock.ExpectTxPipelineExec()
+		reconcileTransaction()
+
+		pricing := &corev1.ConfigMap{}
+		gomega.Expect(reconciler.Get(ctx, types.NamespacedName{Name: "pricing", Namespace: "default"}, pricing)).To(gomega.Succeed())
+		pricing.Data["b"] = "3"
+		gomega.Expect(reconciler.Update(ctx, pricing)).To(gomega.Succeed())
+		gomega.Expect(reconciler.transactionsForValueConfigMap(ctx, pricing)).To(gomega.ConsistOf(reconcile.Request{NamespacedName: name}))
+
+		mock.ExpectMGet("a").SetVal([]interface{}{"1"})
+		mock.ExpectPTTL("a").SetVal(time.Duration(-1))
+		mock.ExpectMGet("b").SetVal([]interface{}{"2"})
+		mock.ExpectPTTL("b").SetVal(time.Duration(-1))
+		mock.ExpectTxPipeline()
+		mock.ExpectSet("a", "1", 0).SetVal("OK")
+		mock.ExpectTxPipelineExec()
+		mock.ExpectTxPipeline()
+		mock.ExpectSet("b", "3", 0).SetVal("OK")
+		mock.ExpectTxPipelineExec()
+		_, tx := reconcileTransaction()
+		gomega.Expect(tx.Status.Steps[1].ValueHash).To(gomega.Equal(valueChecksum("3")))
+	})
+
+	ginkgo.It("should restore the keys already written when an entry fails", func() {
+		expectPrior()
+		mock.ExpectTxPipeline()
+		mock.ExpectSet("a", "1", 0).SetVal("OK")
+		mock.ExpectTxPipelineExec()
+		mock.ExpectTxPipeline()
+		mock.ExpectSet("b", "2", 0).SetErr(errors.New("OOM command not allowed when used memory > 'maxmemory'"))
+		mock.ExpectTxPipeline()
+		mock.ExpectDel("b").SetVal(0)
+		mock.ExpectTxPipelineExec()
+		mock.ExpectTxPipeline()
+		mock.ExpectSet("a", "old", 0).SetVal("OK")
+		mock.ExpectTxPipelineExec()
+
+		result, tx := reconcileTransaction()
+		gomega.Expect(result.RequeueAfter).To(gomega.Equal(redisErrorRetryDelay))
+		cond := meta.FindStatusCondition(tx.Status.Conditions, typeError)
+		gomega.Expect(cond).NotTo(gomega.BeNil())
+		gomega.Expect(cond.Reason).To(gomega.Equal(reasonRolledBack))
+		gomega.Expect(tx.Status.Steps[0].Result).To(gomega.Equal(redisv1alpha1.StepRolledBack))
+		gomega.Expect(tx.Status.Steps[1].Result).To(gomega.Equal(redisv1alpha1.StepFailed))
+		gomega.Expect(tx.Status.Steps[1].Error).To(gomega.ContainSubstring("OOM"))
+	})
+
+	ginkgo.It("should wait for missing entries", func() {
+		tx := &redisv1alpha1.RedisTransaction{}
+		gomega.Expect(reconciler.Get(ctx, name, tx)).To(gomega.Succeed())
+		tx.Spec.Entries = append(tx.Spec.Entries, "third")
+		gomega.Expect(reconciler.Update(ctx, tx)).To(gomega.Succeed())
+
+		result, tx := reconcileTransaction()
+		gomega.Expect(result).To(gomega.Equal(reconcile.Result{}))
+		cond := meta.FindStatusCondition(tx.Status.Conditions, typeError)
+		gomega.Expect(cond).NotTo(gomega.BeNil())
+		gomega.Expect(cond.Reason).To(gomega.Equal(reasonEntryNotFound))
+	})
+
+	ginkgo.It("should leave entries in a transaction to it", func() {
+		result, err := entries.Reconcile(ctx, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: "first", Namespace: "default"},
+		})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(result).To(gomega.Equal(reconcile.Result{}))
+
+		requests := entries.entriesForTransaction(ctx, &redisv1alpha1.RedisTransaction{
+			ObjectMeta: metav1.ObjectMeta{Name: "rollout", Namespace: "default"},
+			Spec:       redisv1alpha1.RedisTransactionSpec{Entries: []string{"first"}},
+		})
+		gomega.Expect(requests).To(gomega.ConsistOf(reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: "first", Namespace: "default"},
+		}))
+	})
+})
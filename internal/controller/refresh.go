@@ -0,0 +1,84 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// maxCurrentValueBytes caps the value shown in status.currentValue
+const maxCurrentValueBytes = 1024
+
+// refreshRequested reports whether the entry asks for its current value to
+// be read back from Redis.
+func refreshRequested(redisEntry *redisv1alpha1.RedisEntry) bool {
+	return redisEntry.Annotations[redisv1alpha1.RefreshStatusAnnotation] == "true"
+}
+
+// refreshStatus reads the entry's key from Redis into status.currentValue
+// and removes the refresh annotation. Nothing is written to Redis.
+func (r *RedisEntryReconciler) refreshStatus(ctx context.Context, s *entrySync) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+	defer s.close()
+
+	if res := r.connect(ctx, s); res != nil {
+		return r.report(ctx, s, res)
+	}
+	values, err := s.store.Get(ctx, s.entry.Spec.Key)
+	if err != nil {
+		log.Error(err, "Failed to read current value from Redis")
+		return r.report(ctx, s, r.fail(s, reasonRedisError, err.Error(), redisErrorRetryDelay))
+	}
+	s.entry.Status.CurrentValue = ""
+	if values[0] != nil {
+		s.entry.Status.CurrentValue = statusValue(s.entry, *values[0])
+	}
+	now := metav1.NewTime(r.now())
+	s.entry.Status.LastRefreshed = &now
+	if err := r.updateStatus(ctx, s.entry, s.original); err != nil {
+		log.Error(err, "Failed to update RedisEntry status")
+		return ctrl.Result{}, err
+	}
+	log.V(1).Info("Refreshed current value from Redis", "key", s.entry.Spec.Key)
+
+	delete(s.entry.Annotations, redisv1alpha1.RefreshStatusAnnotation)
+	if err := r.Update(ctx, s.entry); err != nil {
+		log.Error(err, "Failed to remove refresh annotation from RedisEntry")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// statusValue returns a value as shown in the status: hashed when it comes
+// from a Secret, and truncated past maxCurrentValueBytes.
+func statusValue(redisEntry *redisv1alpha1.RedisEntry, value string) string {
+	if _, ok := valueSecret(redisEntry); ok {
+		return ValueLogHashed.redact(value)
+	}
+	if len(value) > maxCurrentValueBytes {
+		// Cutting may split a multi-byte character
+		return fmt.Sprintf("%s... (%d bytes)", strings.ToValidUTF8(value[:maxCurrentValueBytes], ""), len(value))
+	}
+	return value
+}
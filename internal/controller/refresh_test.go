@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"context"
+	"strings"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	redismock "github.com/go-redis/redismock/v9"
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = ginkgo.Describe("Status Refresh", func() {
+	var (
+		ctx  context.Context
+		mock redismock.ClientMock
+		name types.NamespacedName
+	)
+
+	newReconciler := func(spec redisv1alpha1.RedisEntrySpec) *RedisEntryReconciler {
+		s := runtime.NewScheme()
+		gomega.Expect(redisv1alpha1.AddToScheme(s)).To(gomega.Succeed())
+		entry := &redisv1alpha1.RedisEntry{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name.Name,
+				Namespace:   name.Namespace,
+				Annotations: map[string]string{redisv1alpha1.RefreshStatusAnnotation: "true"},
+				Finalizers:  []string{redisv1alpha1.KeyCleanupFinalizer},
+			},
+			Spec: spec,
+		}
+		mockRedis, m := redismock.NewClientMock()
+		mock = m
+		return &RedisEntryReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(s).
+				WithObjects(entry).
+				WithStatusSubresource(&redisv1alpha1.RedisEntry{}).
+				Build(),
+			Scheme:      s,
+			RedisClient: mockRedis,
+		}
+	}
+
+	// refresh reconciles the entry and returns it afterwards
+	refresh := func(r *RedisEntryReconciler) *redisv1alpha1.RedisEntry {
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		entry := &redisv1alpha1.RedisEntry{}
+		gomega.Expect(r.Get(ctx, name, entry)).To(gomega.Succeed())
+		return entry
+	}
+
+	ginkgo.BeforeEach(func() {
+		ctx = context.Background()
+		name = types.NamespacedName{Name: "inspected", Namespace: "default"}
+	})
+
+	ginkgo.AfterEach(func() {
+		gomega.Expect(mock.ExpectationsWereMet()).To(gomega.Succeed())
+	})
+
+	ginkgo.It("should read the key into the status without writing it", func() {
+		r := newReconciler(redisv1alpha1.RedisEntrySpec{Key: "app:key", Value: "declared"})
+		mock.ExpectMGet("app:key").SetVal([]interface{}{"edited"})
+
+		entry := refresh(r)
+		gomega.Expect(entry.Status.CurrentValue).To(gomega.Equal("edited"))
+		gomega.Expect(entry.Status.LastRefreshed).NotTo(gomega.BeNil())
+		gomega.Expect(entry.Annotations).NotTo(gomega.HaveKey(redisv1alpha1.RefreshStatusAnnotation))
+	})
+
+	ginkgo.It("should clear the value of a missing key", func() {
+		r := newReconciler(redisv1alpha1.RedisEntrySpec{Key: "app:key", Value: "declared"})
+		entry := &redisv1alpha1.RedisEntry{}
+		gomega.Expect(r.Get(ctx, name, entry)).To(gomega.Succeed())
+		entry.Status.CurrentValue = "stale"
+		gomega.Expect(r.Status().Update(ctx, entry)).To(gomega.Succeed())
+		mock.ExpectMGet("app:key").SetVal([]interface{}{nil})
+
+		gomega.Expect(refresh(r).Status.CurrentValue).To(gomega.BeEmpty())
+	})
+
+	ginkgo.It("should hash values read from a Secret and truncate long values", func() {
+		r := newReconciler(redisv1alpha1.RedisEntrySpec{Key: "app:key", ValueFrom: &redisv1alpha1.ValueSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "app-secret"},
+				Key:                  "password",
+			},
+		}})
+		mock.ExpectMGet("app:key").SetVal([]interface{}{"hunter2"})
+		gomega.Expect(refresh(r).Status.CurrentValue).To(gomega.MatchRegexp(`^sha256:[0-9a-f]{12}$`))
+
+		long := strings.Repeat("x", 2*maxCurrentValueBytes)
+		gomega.Expect(statusValue(&redisv1alpha1.RedisEntry{}, long)).To(
+			gomega.Equal(long[:maxCurrentValueBytes] + "... (2048 bytes)"))
+	})
+})
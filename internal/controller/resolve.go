@@ -0,0 +1,104 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net"
+	"slices"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// addressWatcher periodically resolves the host of the Redis address and
+// drops the client's connections when the addresses behind it change, as
+// happens during managed Redis maintenance, so the controller doesn't keep
+// talking to a retired endpoint.
+type addressWatcher struct {
+	host     string
+	interval time.Duration
+	conns    *connTracker
+
+	// lookup resolves a host name; net.DefaultResolver.LookupHost by default
+	lookup func(ctx context.Context, host string) ([]string, error)
+
+	// addrs is the last resolved set, sorted; only accessed from the
+	// watcher goroutine
+	addrs []string
+}
+
+// newAddressWatcher returns a watcher for a host:port address, or nil when
+// the address is an IP or a unix socket and there is nothing to resolve.
+func newAddressWatcher(network, addr string, interval time.Duration, conns *connTracker) *addressWatcher {
+	if network == "unix" || interval <= 0 {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil || net.ParseIP(host) != nil {
+		return nil
+	}
+	return &addressWatcher{
+		host:     host,
+		interval: interval,
+		conns:    conns,
+		lookup:   net.DefaultResolver.LookupHost,
+	}
+}
+
+// Start resolves the host every interval until the context is cancelled.
+func (w *addressWatcher) Start(ctx context.Context) error {
+	w.check(ctx)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			w.check(ctx)
+		}
+	}
+}
+
+// NeedLeaderElection is false so that standby replicas also keep their
+// connections current.
+func (w *addressWatcher) NeedLeaderElection() bool {
+	return false
+}
+
+// check resolves the host once and reconnects if its addresses changed.
+// Lookup failures keep the current connections.
+func (w *addressWatcher) check(ctx context.Context) {
+	log := log.FromContext(ctx).WithName("redis-dns")
+
+	addrs, err := w.lookup(ctx, w.host)
+	if err != nil || len(addrs) == 0 {
+		if err != nil {
+			log.V(1).Info("Failed to resolve Redis host", "host", w.host, "error", err.Error())
+		}
+		return
+	}
+	slices.Sort(addrs)
+	if w.addrs != nil && !slices.Equal(addrs, w.addrs) {
+		log.Info("Redis host resolves to new addresses, reconnecting", "host", w.host,
+			"previous", w.addrs, "current", addrs)
+		w.conns.reconnect()
+	}
+	w.addrs = addrs
+}
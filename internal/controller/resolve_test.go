@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+)
+
+var _ = ginkgo.Describe("Redis Address Watcher", func() {
+	ginkgo.It("should only watch host names", func() {
+		gomega.Expect(newAddressWatcher("tcp", "10.0.0.1:6379", time.Second, nil)).To(gomega.BeNil())
+		gomega.Expect(newAddressWatcher("unix", "/run/redis.sock", time.Second, nil)).To(gomega.BeNil())
+		gomega.Expect(newAddressWatcher("tcp", "redis:6379", 0, nil)).To(gomega.BeNil())
+		gomega.Expect(newAddressWatcher("tcp", "redis:6379", time.Second, nil).host).To(gomega.Equal("redis"))
+	})
+
+	ginkgo.It("should reconnect when the addresses change", func() {
+		tracker := &connTracker{conns: map[*trackedConn]struct{}{}}
+		watcher := newAddressWatcher("tcp", "redis:6379", time.Second, tracker)
+
+		var addrs []string
+		var lookupErr error
+		watcher.lookup = func(context.Context, string) ([]string, error) { return addrs, lookupErr }
+
+		ctx := context.Background()
+		addrs = []string{"10.0.0.2", "10.0.0.1"}
+		watcher.check(ctx)
+		gomega.Expect(tracker.lastReconnect.IsZero()).To(gomega.BeTrue())
+
+		// Same set in another order
+		addrs = []string{"10.0.0.1", "10.0.0.2"}
+		watcher.check(ctx)
+		gomega.Expect(tracker.lastReconnect.IsZero()).To(gomega.BeTrue())
+
+		// Failed lookups keep the connections
+		lookupErr = errors.New("no such host")
+		watcher.check(ctx)
+		gomega.Expect(tracker.lastReconnect.IsZero()).To(gomega.BeTrue())
+
+		addrs, lookupErr = []string{"10.0.0.3"}, nil
+		watcher.check(ctx)
+		gomega.Expect(tracker.lastReconnect.IsZero()).To(gomega.BeFalse())
+		gomega.Expect(watcher.addrs).To(gomega.Equal([]string{"10.0.0.3"}))
+	})
+})
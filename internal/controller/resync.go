@@ -0,0 +1,64 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"net/http"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ResyncPath is where ResyncHandler is served on the metrics server.
+const ResyncPath = "/resync"
+
+// ResyncHandler returns an HTTP handler that enqueues every managed RedisEntry
+// for reconciliation, for example after Redis was restored from a backup.
+// Entries whose keys no longer match are rewritten as drift.
+//
+// It accepts POST requests with an optional connection query parameter, which
+// must name the controller's connection. Only the leader resyncs, so requests
+// to other replicas are refused.
+func (r *RedisEntryReconciler) ResyncHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "resync requires POST", http.StatusMethodNotAllowed)
+			return
+		}
+		if connection := req.URL.Query().Get("connection"); connection != "" && connection != connectionName(r.ConnectionName) {
+			http.Error(w, fmt.Sprintf("unknown connection %q", connection), http.StatusNotFound)
+			return
+		}
+		if r.monitor == nil {
+			http.Error(w, "controller is not set up", http.StatusServiceUnavailable)
+			return
+		}
+		select {
+		case <-r.elected:
+		default:
+			http.Error(w, "this replica is not the leader", http.StatusServiceUnavailable)
+			return
+		}
+
+		if r.monitor.requestResync() {
+			log.FromContext(req.Context()).Info("Full resync requested", "connection", connectionName(r.ConnectionName))
+		}
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprintf(w, "resync of connection %s requested\n", connectionName(r.ConnectionName))
+	})
+}
@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+)
+
+var _ = ginkgo.Describe("Resync Endpoint", func() {
+	var (
+		reconciler *RedisEntryReconciler
+		elected    chan struct{}
+	)
+
+	ginkgo.BeforeEach(func() {
+		elected = make(chan struct{})
+		reconciler = &RedisEntryReconciler{
+			monitor: newHealthMonitor(nil, nil, time.Second),
+			elected: elected,
+		}
+	})
+
+	// send sends a request to the resync handler and returns the status code
+	send := func(method, target string) int {
+		recorder := httptest.NewRecorder()
+		reconciler.ResyncHandler().ServeHTTP(recorder, httptest.NewRequest(method, target, nil))
+		return recorder.Code
+	}
+
+	ginkgo.It("should queue a single resync on the leader", func() {
+		close(elected)
+		gomega.Expect(send(http.MethodPost, ResyncPath)).To(gomega.Equal(http.StatusAccepted))
+		gomega.Expect(send(http.MethodPost, ResyncPath+"?connection=default")).To(gomega.Equal(http.StatusAccepted))
+		gomega.Expect(reconciler.monitor.resyncs).To(gomega.HaveLen(1))
+	})
+
+	ginkgo.It("should refuse requests it cannot serve", func() {
+		gomega.Expect(send(http.MethodPost, ResyncPath)).To(gomega.Equal(http.StatusServiceUnavailable))
+
+		close(elected)
+		gomega.Expect(send(http.MethodGet, ResyncPath)).To(gomega.Equal(http.StatusMethodNotAllowed))
+		gomega.Expect(send(http.MethodPost, ResyncPath+"?connection=other")).To(gomega.Equal(http.StatusNotFound))
+		gomega.Expect(reconciler.monitor.resyncs).To(gomega.BeEmpty())
+	})
+})
@@ -0,0 +1,84 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sync"
+	"time"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// Defaults for entries that declare a retry policy
+	defaultMaxRetryDelay   = 5 * time.Minute
+	defaultRetryMultiplier = 2
+)
+
+// retryDelay returns how long to wait before retrying an entry that has failed
+// the given number of consecutive times. Values the CRD rejects fall back to
+// the defaults, so a failed entry is always retried.
+func retryDelay(policy *redisv1alpha1.RetryPolicy, failures int) time.Duration {
+	delay := redisErrorRetryDelay
+	maxDelay := defaultMaxRetryDelay
+	multiplier := float64(defaultRetryMultiplier)
+	if policy.InitialDelay != nil && policy.InitialDelay.Duration > 0 {
+		delay = policy.InitialDelay.Duration
+	}
+	if policy.MaxDelay != nil && policy.MaxDelay.Duration > 0 {
+		maxDelay = policy.MaxDelay.Duration
+	}
+	if policy.Multiplier != nil && policy.Multiplier.AsApproximateFloat64() >= 1 {
+		multiplier = policy.Multiplier.AsApproximateFloat64()
+	}
+
+	for i := 1; i < failures && delay < maxDelay; i++ {
+		// Stop before the multiplication overflows
+		next := float64(delay) * multiplier
+		if next >= float64(maxDelay) {
+			return maxDelay
+		}
+		delay = time.Duration(next)
+	}
+	return min(delay, maxDelay)
+}
+
+// failureTracker counts consecutive failed writes per entry. The zero value is
+// ready to use.
+type failureTracker struct {
+	mu     sync.Mutex
+	counts map[types.NamespacedName]int
+}
+
+// inc records a failure and returns the number of consecutive failures.
+func (t *failureTracker) inc(name types.NamespacedName) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.counts == nil {
+		t.counts = map[types.NamespacedName]int{}
+	}
+	t.counts[name]++
+	return t.counts[name]
+}
+
+// reset forgets the failures of an entry after a successful write.
+func (t *failureTracker) reset(name types.NamespacedName) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.counts, name)
+}
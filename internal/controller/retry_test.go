@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	redismock "github.com/go-redis/redismock/v9"
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	redisv9 "github.com/redis/go-redis/v9"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = ginkgo.Describe("Retry Policy", func() {
+	ginkgo.Context("retryDelay", func() {
+		ginkgo.It("should use the defaults for an empty policy", func() {
+			policy := &redisv1alpha1.RetryPolicy{}
+			gomega.Expect(retryDelay(policy, 1)).To(gomega.Equal(5 * time.Second))
+			gomega.Expect(retryDelay(policy, 2)).To(gomega.Equal(10 * time.Second))
+			gomega.Expect(retryDelay(policy, 100)).To(gomega.Equal(5 * time.Minute))
+		})
+
+		ginkgo.It("should apply the configured delays and multiplier", func() {
+			multiplier := resource.MustParse("3")
+			policy := &redisv1alpha1.RetryPolicy{
+				InitialDelay: &metav1.Duration{Duration: 100 * time.Millisecond},
+				MaxDelay:     &metav1.Duration{Duration: time.Second},
+				Multiplier:   &multiplier,
+			}
+			gomega.Expect(retryDelay(policy, 1)).To(gomega.Equal(100 * time.Millisecond))
+			gomega.Expect(retryDelay(policy, 3)).To(gomega.Equal(900 * time.Millisecond))
+			gomega.Expect(retryDelay(policy, 4)).To(gomega.Equal(time.Second))
+		})
+
+		ginkgo.It("should apply a fractional multiplier", func() {
+			multiplier := resource.MustParse("1.5")
+			policy := &redisv1alpha1.RetryPolicy{
+				InitialDelay: &metav1.Duration{Duration: time.Second},
+				Multiplier:   &multiplier,
+			}
+			gomega.Expect(retryDelay(policy, 2)).To(gomega.Equal(1500 * time.Millisecond))
+			gomega.Expect(retryDelay(policy, 3)).To(gomega.Equal(2250 * time.Millisecond))
+		})
+
+		ginkgo.It("should fall back to the defaults for delays that would never retry", func() {
+			multiplier := resource.MustParse("0")
+			policy := &redisv1alpha1.RetryPolicy{
+				InitialDelay: &metav1.Duration{},
+				MaxDelay:     &metav1.Duration{Duration: -time.Second},
+				Multiplier:   &multiplier,
+			}
+			gomega.Expect(retryDelay(policy, 1)).To(gomega.Equal(5 * time.Second))
+			gomega.Expect(retryDelay(policy, 2)).To(gomega.Equal(10 * time.Second))
+		})
+
+		ginkgo.It("should not overflow with a large multiplier", func() {
+			multiplier := resource.MustParse("1e300")
+			policy := &redisv1alpha1.RetryPolicy{
+				MaxDelay:   &metav1.Duration{Duration: 1000 * time.Hour},
+				Multiplier: &multiplier,
+			}
+			for failures := 2; failures < 10; failures++ {
+				gomega.Expect(retryDelay(policy, failures)).To(gomega.Equal(1000 * time.Hour))
+			}
+		})
+	})
+
+	ginkgo.It("should forget the failures of deleted entries", func() {
+		s := runtime.NewScheme()
+		gomega.Expect(redisv1alpha1.AddToScheme(s)).To(gomega.Succeed())
+		r := &RedisEntryReconciler{Client: fake.NewClientBuilder().WithScheme(s).Build(), Scheme: s}
+		name := types.NamespacedName{Name: "gone", Namespace: "default"}
+		r.failures.inc(name)
+
+		_, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: name})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(r.failures.inc(name)).To(gomega.Equal(1))
+	})
+
+	ginkgo.It("should back off failed entries according to their policy", func() {
+		ctx := context.Background()
+		s := runtime.NewScheme()
+		gomega.Expect(redisv1alpha1.AddToScheme(s)).To(gomega.Succeed())
+
+		var mockRedis *redisv9.Client
+		var mock redismock.ClientMock
+		mockRedis, mock = redismock.NewClientMock()
+		r := &RedisEntryReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(s).
+				WithStatusSubresource(&redisv1alpha1.RedisEntry{}).
+				Build(),
+			Scheme:      s,
+			RedisClient: mockRedis,
+		}
+
+		entry := &redisv1alpha1.RedisEntry{
+			ObjectMeta: metav1.ObjectMeta{Name: "retry-entry", Namespace: "default"},
+			Spec: redisv1alpha1.RedisEntrySpec{
+				Key:   "retry-key",
+				Value: "retry-value",
+				RetryPolicy: &redisv1alpha1.RetryPolicy{
+					InitialDelay: &metav1.Duration{Duration: time.Second},
+				},
+			},
+		}
+		gomega.Expect(r.Create(ctx, entry)).To(gomega.Succeed())
+		req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "retry-entry", Namespace: "default"}}
+
+		mock.ExpectSet("retry-key", "retry-value", 0).SetErr(errors.New("redis error"))
+		result, err := r.Reconcile(ctx, req)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(result.RequeueAfter).To(gomega.Equal(time.Second))
+
+		mock.ExpectSet("retry-key", "retry-value", 0).SetErr(errors.New("redis error"))
+		result, err = r.Reconcile(ctx, req)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(result.RequeueAfter).To(gomega.Equal(2 * time.Second))
+
+		// A successful write resets the backoff
+		mock.ExpectSet("retry-key", "retry-value", 0).SetVal("OK")
+		_, err = r.Reconcile(ctx, req)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		mock.ExpectMGet("retry-key").SetVal([]interface{}{"retry-value"})
+		mock.ExpectSet("retry-key", "retry-value", 0).SetErr(errors.New("redis error"))
+		result, err = r.Reconcile(ctx, req)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(result.RequeueAfter).To(gomega.Equal(time.Second))
+
+		gomega.Expect(mock.ExpectationsWereMet()).To(gomega.Succeed())
+	})
+})
@@ -0,0 +1,221 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	redisv9 "github.com/redis/go-redis/v9"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Capability is an optional server feature the controller may use.
+type Capability string
+
+const (
+	// CapabilityUnlink is non-blocking key deletion (UNLINK)
+	CapabilityUnlink Capability = "UNLINK"
+	// CapabilityACLDryRun is ACL permission probing (ACL DRYRUN)
+	CapabilityACLDryRun Capability = "ACL DRYRUN"
+)
+
+// Engine identifies the server implementation behind the Redis protocol.
+type Engine string
+
+const (
+	// EngineRedis is Redis itself, and the fallback for unrecognized servers
+	EngineRedis Engine = "redis"
+	// EngineValkey is the Valkey fork of Redis
+	EngineValkey Engine = "valkey"
+	// EngineKeyDB is the multithreaded KeyDB fork of Redis
+	EngineKeyDB Engine = "keydb"
+	// EngineDragonfly is Dragonfly, a Redis-compatible reimplementation
+	EngineDragonfly Engine = "dragonfly"
+)
+
+// capabilityRequirement is the minimum version a capability needs. Versions
+// refer to the Redis compatibility level reported as redis_version, which
+// forks keep in line with the Redis features they implement.
+type capabilityRequirement struct {
+	major, minor int
+}
+
+var capabilityRequirements = map[Capability]capabilityRequirement{
+	CapabilityUnlink:    {major: 4, minor: 0},
+	CapabilityACLDryRun: {major: 7, minor: 0},
+}
+
+// ServerInfo describes the Redis server the controller is connected to, as
+// reported by INFO server and MODULE LIST.
+type ServerInfo struct {
+	// Engine is the server implementation
+	Engine Engine
+
+	// EngineVersion is the engine's own version, which differs from Version
+	// for forks and reimplementations
+	EngineVersion string
+
+	// Version is the raw redis_version string
+	Version string
+
+	// Modules holds the lower-cased names of loaded modules
+	Modules []string
+
+	major, minor int
+}
+
+// detectServer queries the server version and loaded modules. Servers that
+// refuse MODULE LIST (older versions, restricted ACLs) are treated as having
+// no modules.
+func detectServer(ctx context.Context, redisClient redisv9.UniversalClient) (*ServerInfo, error) {
+	raw, err := redisClient.Info(ctx, "server").Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read server info: %w", err)
+	}
+	fields := map[string]string{}
+	for _, line := range strings.Split(raw, "\n") {
+		if key, value, ok := strings.Cut(strings.TrimSpace(line), ":"); ok {
+			fields[key] = value
+		}
+	}
+	info := &ServerInfo{Version: fields["redis_version"]}
+	if info.Version == "" {
+		return nil, fmt.Errorf("server info does not report redis_version")
+	}
+	info.major, info.minor = parseVersion(info.Version)
+	info.Engine, info.EngineVersion = detectEngine(fields)
+
+	if modules, err := redisClient.Do(ctx, "MODULE", "LIST").Slice(); err == nil {
+		info.Modules = parseModuleNames(modules)
+	}
+	return info, nil
+}
+
+// detectEngine identifies the server implementation from INFO server fields.
+// Forks report a Redis compatibility version as redis_version and add their
+// own version field.
+func detectEngine(fields map[string]string) (Engine, string) {
+	switch {
+	case fields["dragonfly_version"] != "":
+		return EngineDragonfly, fields["dragonfly_version"]
+	case fields["server_name"] == "valkey" || fields["valkey_version"] != "":
+		return EngineValkey, fields["valkey_version"]
+	case fields["server_name"] == "keydb" || strings.HasSuffix(fields["executable"], "keydb-server"):
+		return EngineKeyDB, fields["redis_version"]
+	default:
+		return EngineRedis, fields["redis_version"]
+	}
+}
+
+// parseVersion extracts the major and minor components of a version string.
+func parseVersion(version string) (int, int) {
+	parts := strings.SplitN(version, ".", 3)
+	major, _ := strconv.Atoi(parts[0])
+	var minor int
+	if len(parts) > 1 {
+		minor, _ = strconv.Atoi(parts[1])
+	}
+	return major, minor
+}
+
+// parseModuleNames reads module names from a MODULE LIST reply, which is a
+// list of either maps (RESP3) or flat name/value arrays (RESP2).
+func parseModuleNames(reply []interface{}) []string {
+	var names []string
+	for _, module := range reply {
+		switch fields := module.(type) {
+		case map[interface{}]interface{}:
+			if name, ok := fields["name"].(string); ok {
+				names = append(names, strings.ToLower(name))
+			}
+		case []interface{}:
+			for i := 0; i+1 < len(fields); i += 2 {
+				if fields[i] == "name" {
+					if name, ok := fields[i+1].(string); ok {
+						names = append(names, strings.ToLower(name))
+					}
+				}
+			}
+		}
+	}
+	return names
+}
+
+// AtLeast reports whether the server version is at least major.minor.
+func (s *ServerInfo) AtLeast(major, minor int) bool {
+	return s.major > major || (s.major == major && s.minor >= minor)
+}
+
+// Supports reports whether the server provides a capability. An unknown
+// server (nil) is assumed to support everything so that detection failures
+// never block writes.
+func (s *ServerInfo) Supports(c Capability) bool {
+	return s.Require(c) == nil
+}
+
+// Require returns a descriptive error, suitable for a status condition, when
+// the server lacks a capability.
+func (s *ServerInfo) Require(c Capability) error {
+	req, known := capabilityRequirements[c]
+	if s == nil || !known {
+		return nil
+	}
+	if !s.AtLeast(req.major, req.minor) {
+		return fmt.Errorf("%s requires Redis %d.%d or newer, but the server runs %s", c, req.major, req.minor, s.Version)
+	}
+	return nil
+}
+
+// serverState holds the ServerInfo of a client that is detected on its first
+// use, and again after invalidate. A failed detection is not retried until
+// then, leaving the server unknown. The zero value detects on first use.
+type serverState struct {
+	mu   sync.Mutex
+	info *ServerInfo
+
+	// detected is kept outside mu, since the dial of a detection may itself
+	// call invalidate
+	detected atomic.Bool
+}
+
+// get returns the ServerInfo of the server behind redisClient, detecting it
+// first if needed. Proxies answer INFO for no particular backend, so nothing
+// is detected through them.
+func (s *serverState) get(ctx context.Context, redisClient redisv9.UniversalClient, proxy bool) *ServerInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.detected.Load() && !proxy {
+		info, err := detectServer(ctx, redisClient)
+		if err != nil {
+			log.FromContext(ctx).Error(err, "Failed to detect Redis server version, assuming all capabilities")
+		}
+		s.info = info
+	}
+	s.detected.Store(true)
+	return s.info
+}
+
+// invalidate detects the server again on the next get, for example because
+// the client reconnected to a server that may have been upgraded or replaced.
+func (s *serverState) invalidate() {
+	s.detected.Store(false)
+}
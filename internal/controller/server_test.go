@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"context"
+	"errors"
+
+	redismock "github.com/go-redis/redismock/v9"
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+)
+
+var _ = ginkgo.Describe("Redis Server Detection", func() {
+	ginkgo.It("should parse the version and modules", func() {
+		mockRedis, mock := redismock.NewClientMock()
+		mock.ExpectInfo("server").SetVal("# Server\r\nredis_version:7.2.4\r\nredis_mode:standalone\r\n")
+		mock.ExpectDo("MODULE", "LIST").SetVal([]interface{}{
+			[]interface{}{"name", "ReJSON", "ver", int64(20609)},
+		})
+
+		info, err := detectServer(context.Background(), mockRedis)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(info.Version).To(gomega.Equal("7.2.4"))
+		gomega.Expect(info.Engine).To(gomega.Equal(EngineRedis))
+		gomega.Expect(info.Modules).To(gomega.ConsistOf("rejson"))
+		gomega.Expect(info.Supports(CapabilityACLDryRun)).To(gomega.BeTrue())
+		gomega.Expect(mock.ExpectationsWereMet()).To(gomega.Succeed())
+	})
+
+	ginkgo.It("should recognize Valkey and its JSON module", func() {
+		mockRedis, mock := redismock.NewClientMock()
+		mock.ExpectInfo("server").SetVal("# Server\r\nredis_version:7.2.4\r\nserver_name:valkey\r\nvalkey_version:8.0.1\r\n")
+		mock.ExpectDo("MODULE", "LIST").SetVal([]interface{}{
+			map[interface{}]interface{}{"name": "json", "ver": int64(10010)},
+		})
+
+		info, err := detectServer(context.Background(), mockRedis)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(info.Engine).To(gomega.Equal(EngineValkey))
+		gomega.Expect(info.EngineVersion).To(gomega.Equal("8.0.1"))
+		gomega.Expect(info.Modules).To(gomega.ConsistOf("json"))
+		gomega.Expect(mock.ExpectationsWereMet()).To(gomega.Succeed())
+	})
+
+	ginkgo.It("should recognize Dragonfly without MODULE LIST", func() {
+		mockRedis, mock := redismock.NewClientMock()
+		mock.ExpectInfo("server").SetVal("# Server\r\nredis_version:7.2.0\r\ndragonfly_version:df-v1.21.2\r\n")
+		mock.ExpectDo("MODULE", "LIST").SetErr(errors.New("ERR unknown command 'MODULE'"))
+
+		info, err := detectServer(context.Background(), mockRedis)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(info.Engine).To(gomega.Equal(EngineDragonfly))
+		gomega.Expect(info.EngineVersion).To(gomega.Equal("df-v1.21.2"))
+		gomega.Expect(info.Modules).To(gomega.BeEmpty())
+		gomega.Expect(mock.ExpectationsWereMet()).To(gomega.Succeed())
+	})
+
+	ginkgo.It("should explain missing capabilities on old servers", func() {
+		info := &ServerInfo{Version: "5.0.7"}
+		info.major, info.minor = parseVersion(info.Version)
+
+		gomega.Expect(info.Supports(CapabilityUnlink)).To(gomega.BeTrue())
+		gomega.Expect(info.Require(CapabilityACLDryRun)).To(gomega.MatchError(
+			"ACL DRYRUN requires Redis 7.0 or newer, but the server runs 5.0.7"))
+	})
+
+	ginkgo.It("should assume everything is supported when the server is unknown", func() {
+		var info *ServerInfo
+		gomega.Expect(info.Supports(CapabilityUnlink)).To(gomega.BeTrue())
+	})
+
+	ginkgo.It("should detect the server of a client once and delete with DEL on old servers", func() {
+		mockRedis, mock := redismock.NewClientMock()
+		mock.ExpectInfo("server").SetVal("# Server\r\nredis_version:3.2.12\r\n")
+		mock.ExpectDo("MODULE", "LIST").SetErr(errors.New("ERR unknown command 'MODULE'"))
+
+		state := &serverState{}
+		info := state.get(context.Background(), mockRedis, false)
+		gomega.Expect(info.Version).To(gomega.Equal("3.2.12"))
+		gomega.Expect(state.get(context.Background(), mockRedis, false)).To(gomega.BeIdenticalTo(info))
+
+		r := &RedisEntryReconciler{}
+		mock.ExpectDel("a", "b").SetVal(2)
+		gomega.Expect(r.store(mockRedis, info).Del(context.Background(), "a", "b")).To(gomega.Succeed())
+		gomega.Expect(mock.ExpectationsWereMet()).To(gomega.Succeed())
+
+		// After a reconnect the server may have been upgraded
+		state.invalidate()
+		mock.ExpectInfo("server").SetVal("# Server\r\nredis_version:7.2.4\r\n")
+		mock.ExpectDo("MODULE", "LIST").SetVal([]interface{}{})
+		gomega.Expect(state.get(context.Background(), mockRedis, false).Supports(CapabilityUnlink)).To(gomega.BeTrue())
+		gomega.Expect(mock.ExpectationsWereMet()).To(gomega.Succeed())
+	})
+})
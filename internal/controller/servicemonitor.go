@@ -0,0 +1,131 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ServiceMonitorName matches the ServiceMonitor in config/prometheus, so a
+// kustomize deployment and the generated object converge on one resource.
+const ServiceMonitorName = "redis-ctrl-controller-manager-metrics-monitor"
+
+var serviceMonitorGVK = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "ServiceMonitor"}
+
+// ServiceMonitorInstaller creates or updates a Prometheus Operator
+// ServiceMonitor for the controller's metrics service once it becomes the
+// leader. Clusters without the ServiceMonitor CRD are skipped.
+type ServiceMonitorInstaller struct {
+	Client    client.Client
+	Namespace string
+
+	// Secure scrapes over HTTPS with the Prometheus service account token,
+	// matching --metrics-secure
+	Secure bool
+}
+
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors,verbs=get;create;update
+
+// Start installs the ServiceMonitor. Failures are logged rather than
+// returned so that missing permissions don't stop the manager.
+func (s *ServiceMonitorInstaller) Start(ctx context.Context) error {
+	log := log.FromContext(ctx).WithName("service-monitor")
+
+	if _, err := s.Client.RESTMapper().RESTMapping(serviceMonitorGVK.GroupKind(), serviceMonitorGVK.Version); err != nil {
+		if meta.IsNoMatchError(err) {
+			log.Info("ServiceMonitor CRD is not installed, skipping")
+		} else {
+			log.Error(err, "Failed to look up the ServiceMonitor CRD")
+		}
+		return nil
+	}
+	if err := s.apply(ctx); err != nil {
+		log.Error(err, "Failed to create ServiceMonitor", "namespace", s.Namespace, "name", ServiceMonitorName)
+		return nil
+	}
+	log.Info("ServiceMonitor is up to date", "namespace", s.Namespace, "name", ServiceMonitorName)
+	return nil
+}
+
+// NeedLeaderElection makes only the leader write the ServiceMonitor.
+func (s *ServiceMonitorInstaller) NeedLeaderElection() bool {
+	return true
+}
+
+// apply creates the ServiceMonitor or resets its spec.
+func (s *ServiceMonitorInstaller) apply(ctx context.Context) error {
+	monitor := &unstructured.Unstructured{}
+	monitor.SetGroupVersionKind(serviceMonitorGVK)
+	monitor.SetNamespace(s.Namespace)
+	monitor.SetName(ServiceMonitorName)
+
+	_, err := controllerutil.CreateOrUpdate(ctx, s.Client, monitor, func() error {
+		labels := monitor.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		for key, value := range metricsServiceLabels() {
+			labels[key] = value
+		}
+		monitor.SetLabels(labels)
+		return unstructured.SetNestedField(monitor.Object, s.spec(), "spec")
+	})
+	if err != nil {
+		return fmt.Errorf("failed to apply ServiceMonitor: %w", err)
+	}
+	return nil
+}
+
+// spec mirrors config/prometheus/monitor.yaml.
+func (s *ServiceMonitorInstaller) spec() map[string]interface{} {
+	// The Service port is named https regardless of --metrics-secure
+	endpoint := map[string]interface{}{
+		"path":   "/metrics",
+		"port":   "https",
+		"scheme": "http",
+	}
+	if s.Secure {
+		endpoint["scheme"] = "https"
+		endpoint["bearerTokenFile"] = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+		endpoint["tlsConfig"] = map[string]interface{}{"insecureSkipVerify": true}
+	}
+
+	matchLabels := map[string]interface{}{}
+	for key, value := range metricsServiceLabels() {
+		matchLabels[key] = value
+	}
+	return map[string]interface{}{
+		"endpoints": []interface{}{endpoint},
+		"selector":  map[string]interface{}{"matchLabels": matchLabels},
+	}
+}
+
+// metricsServiceLabels are the labels of the metrics Service in config/default.
+func metricsServiceLabels() map[string]string {
+	return map[string]string{
+		"control-plane":          "controller-manager",
+		"app.kubernetes.io/name": "redis-ctrl",
+	}
+}
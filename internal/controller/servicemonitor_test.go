@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"context"
+
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = ginkgo.Describe("ServiceMonitor Installer", func() {
+	var ctx context.Context
+
+	ginkgo.BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	newClient := func(withCRD bool) client.Client {
+		mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{serviceMonitorGVK.GroupVersion()})
+		if withCRD {
+			mapper.Add(serviceMonitorGVK, meta.RESTScopeNamespace)
+		}
+		return fake.NewClientBuilder().WithScheme(runtime.NewScheme()).WithRESTMapper(mapper).Build()
+	}
+
+	getMonitor := func(c client.Client) (*unstructured.Unstructured, error) {
+		monitor := &unstructured.Unstructured{}
+		monitor.SetGroupVersionKind(serviceMonitorGVK)
+		err := c.Get(ctx, client.ObjectKey{Namespace: "redis-ctrl-system", Name: ServiceMonitorName}, monitor)
+		return monitor, err
+	}
+
+	ginkgo.It("should create a ServiceMonitor when the CRD is installed", func() {
+		c := newClient(true)
+		installer := &ServiceMonitorInstaller{Client: c, Namespace: "redis-ctrl-system", Secure: true}
+		gomega.Expect(installer.Start(ctx)).To(gomega.Succeed())
+
+		monitor, err := getMonitor(c)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		endpoints, _, _ := unstructured.NestedSlice(monitor.Object, "spec", "endpoints")
+		gomega.Expect(endpoints).To(gomega.HaveLen(1))
+		gomega.Expect(endpoints[0]).To(gomega.HaveKeyWithValue("scheme", "https"))
+		gomega.Expect(monitor.GetLabels()).To(gomega.HaveKeyWithValue("app.kubernetes.io/name", "redis-ctrl"))
+
+		// Running again updates the existing object
+		gomega.Expect(installer.Start(ctx)).To(gomega.Succeed())
+	})
+
+	ginkgo.It("should skip clusters without the CRD", func() {
+		c := newClient(false)
+		installer := &ServiceMonitorInstaller{Client: c, Namespace: "redis-ctrl-system"}
+		gomega.Expect(installer.Start(ctx)).To(gomega.Succeed())
+
+		_, err := getMonitor(c)
+		gomega.Expect(err).To(gomega.HaveOccurred())
+	})
+})
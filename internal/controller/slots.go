@@ -0,0 +1,69 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"strings"
+
+	redisv9 "github.com/redis/go-redis/v9"
+)
+
+// clusterSlots is the number of hash slots in a Redis Cluster.
+const clusterSlots = 16384
+
+// keySlot returns the Redis Cluster hash slot of a key: the CRC16 of the key,
+// or of its hash tag when the key contains a non-empty {...} section.
+func keySlot(key string) int {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+	return int(crc16(key)) % clusterSlots
+}
+
+// crc16 implements CRC-16/XMODEM as used for Redis Cluster key slots.
+func crc16(data string) uint16 {
+	var crc uint16
+	for i := 0; i < len(data); i++ {
+		crc ^= uint16(data[i]) << 8
+		for range 8 {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// sameSlot reports whether all keys hash to the same cluster slot.
+func sameSlot(keys []string) bool {
+	for _, key := range keys[1:] {
+		if keySlot(key) != keySlot(keys[0]) {
+			return false
+		}
+	}
+	return true
+}
+
+// isCluster reports whether the client talks to a Redis Cluster.
+func isCluster(redisClient redisv9.UniversalClient) bool {
+	_, ok := redisClient.(*redisv9.ClusterClient)
+	return ok
+}
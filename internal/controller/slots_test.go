@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"context"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	redismock "github.com/go-redis/redismock/v9"
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"k8s.io/utils/ptr"
+)
+
+var _ = ginkgo.Describe("Cluster Hash Slots", func() {
+	ginkgo.It("should compute slots like CLUSTER KEYSLOT", func() {
+		gomega.Expect(keySlot("foo")).To(gomega.Equal(12182))
+		gomega.Expect(keySlot("somekey")).To(gomega.Equal(11058))
+		gomega.Expect(keySlot("{user1000}.following")).To(gomega.Equal(keySlot("user1000")))
+		// An empty hash tag hashes the whole key
+		gomega.Expect(keySlot("{}foo")).NotTo(gomega.Equal(keySlot("")))
+	})
+
+	ginkgo.It("should only group keys sharing a slot", func() {
+		gomega.Expect(sameSlot([]string{"{app}:a", "{app}:b"})).To(gomega.BeTrue())
+		gomega.Expect(sameSlot([]string{"foo", "somekey"})).To(gomega.BeFalse())
+	})
+
+	ginkgo.It("should fall back to a pipeline for keys in different slots", func() {
+		ctx := context.Background()
+		clusterClient, mock := redismock.NewClusterMock()
+		entry := &redisv1alpha1.RedisEntry{
+			Spec: redisv1alpha1.RedisEntrySpec{
+				Key:     "foo",
+				Value:   "1",
+				Entries: map[string]string{"somekey": "2"},
+			},
+		}
+
+		mock.ExpectSet("foo", "1", 0).SetVal("OK")
+		mock.ExpectSet("somekey", "2", 0).SetVal("OK")
+		r := &RedisEntryReconciler{}
+		gomega.Expect(r.writeEntry(ctx, r.store(clusterClient, nil), entry, "1", 0)).To(gomega.Succeed())
+		gomega.Expect(entry.Status.WriteMode).To(gomega.Equal(redisv1alpha1.WriteModePipeline))
+		gomega.Expect(mock.ExpectationsWereMet()).To(gomega.Succeed())
+	})
+
+	ginkgo.It("should read and delete keys in different slots one at a time", func() {
+		ctx := context.Background()
+		clusterClient, mock := redismock.NewClusterMock()
+		entry := &redisv1alpha1.RedisEntry{
+			Spec: redisv1alpha1.RedisEntrySpec{
+				Key:     "foo",
+				Value:   "1",
+				Entries: map[string]string{"somekey": "2"},
+			},
+		}
+		r := &RedisEntryReconciler{}
+		store := r.store(clusterClient, nil)
+
+		mock.ExpectGet("foo").SetVal("1")
+		mock.ExpectGet("somekey").RedisNil()
+		values, err := store.Get(ctx, "foo", "somekey")
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(values).To(gomega.Equal([]*string{ptr.To("1"), nil}))
+
+		mock.ExpectDel("foo").SetVal(1)
+		mock.ExpectDel("somekey").SetVal(1)
+		gomega.Expect(r.deleteEntry(ctx, store, entry)).To(gomega.Succeed())
+		gomega.Expect(mock.ExpectationsWereMet()).To(gomega.Succeed())
+	})
+})
@@ -0,0 +1,129 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// statusCoalescer tracks per-entry status writes so that updates which only
+// touch sync bookkeeping can be deferred. The zero value is ready to use.
+type statusCoalescer struct {
+	mu      sync.Mutex
+	written map[types.NamespacedName]time.Time
+	skipped map[types.NamespacedName]int64
+}
+
+// deferWrite reports whether a write of the entry's status may be skipped because
+// the last one is more recent than window at now. Skipped writes are counted so
+// the sync attempts they carried are added to the next write.
+func (c *statusCoalescer) deferWrite(name types.NamespacedName, window time.Duration, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	last, ok := c.written[name]
+	if !ok || now.Sub(last) >= window {
+		return false
+	}
+	if c.skipped == nil {
+		c.skipped = map[types.NamespacedName]int64{}
+	}
+	c.skipped[name]++
+	return true
+}
+
+// pending returns the number of sync attempts whose status writes were skipped.
+func (c *statusCoalescer) pending(name types.NamespacedName) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.skipped[name]
+}
+
+// deferred returns the entries with skipped status writes.
+func (c *statusCoalescer) deferred() []types.NamespacedName {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	names := make([]types.NamespacedName, 0, len(c.skipped))
+	for name := range c.skipped {
+		names = append(names, name)
+	}
+	return names
+}
+
+// wrote records a successful status write made at now.
+func (c *statusCoalescer) wrote(name types.NamespacedName, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.written == nil {
+		c.written = map[types.NamespacedName]time.Time{}
+	}
+	c.written[name] = now
+	delete(c.skipped, name)
+}
+
+// forget drops the state of a deleted entry.
+func (c *statusCoalescer) forget(name types.NamespacedName) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.written, name)
+	delete(c.skipped, name)
+}
+
+// onlyBookkeepingChanged reports whether two statuses differ at most in the
+// fields every sync refreshes: the attempt counter, timestamps and duration.
+func onlyBookkeepingChanged(before, after *redisv1alpha1.RedisEntryStatus) bool {
+	a, b := before.DeepCopy(), after.DeepCopy()
+	for _, status := range []*redisv1alpha1.RedisEntryStatus{a, b} {
+		status.SyncAttempts = 0
+		status.LastSyncTime = nil
+		status.LastUpdated = nil
+		status.LastSyncDurationMillis = 0
+	}
+	return equality.Semantic.DeepEqual(a, b)
+}
+
+// updateStatus writes the entry's status unless the change can be coalesced
+// with a later write, and keeps overall status writes within StatusLimiter and
+// the current Backpressure level.
+func (r *RedisEntryReconciler) updateStatus(ctx context.Context, redisEntry *redisv1alpha1.RedisEntry,
+	before *redisv1alpha1.RedisEntryStatus) error {
+	name := types.NamespacedName{Namespace: redisEntry.Namespace, Name: redisEntry.Name}
+	if r.StatusCoalesceWindow > 0 && onlyBookkeepingChanged(before, &redisEntry.Status) &&
+		r.statuses.deferWrite(name, r.StatusCoalesceWindow, r.now()) {
+		return nil
+	}
+
+	redisEntry.Status.SyncAttempts += r.statuses.pending(name)
+	if r.StatusLimiter != nil {
+		if err := r.StatusLimiter.Wait(ctx); err != nil {
+			return err
+		}
+	}
+	if err := r.Backpressure.waitStatus(ctx); err != nil {
+		return err
+	}
+	if err := r.Client.Status().Update(ctx, redisEntry); err != nil {
+		return err
+	}
+	r.statuses.wrote(name, r.now())
+	return nil
+}
@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	redismock "github.com/go-redis/redismock/v9"
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clocktesting "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = ginkgo.Describe("Status Update Coalescing", func() {
+	ginkgo.It("should treat sync counters, timestamps and durations as bookkeeping", func() {
+		now := metav1.Now()
+		before := &redisv1alpha1.RedisEntryStatus{SyncAttempts: 1, LastSyncDurationMillis: 3}
+		after := &redisv1alpha1.RedisEntryStatus{SyncAttempts: 2, LastSyncTime: &now, LastSyncDurationMillis: 250}
+		gomega.Expect(onlyBookkeepingChanged(before, after)).To(gomega.BeTrue())
+
+		after.LastError = "connection refused"
+		gomega.Expect(onlyBookkeepingChanged(before, after)).To(gomega.BeFalse())
+	})
+
+	ginkgo.It("should defer bookkeeping-only status writes and carry their attempts forward", func() {
+		ctx := context.Background()
+		s := runtime.NewScheme()
+		gomega.Expect(redisv1alpha1.AddToScheme(s)).To(gomega.Succeed())
+		mockRedis, mock := redismock.NewClientMock()
+		clock := clocktesting.NewFakePassiveClock(time.Now())
+		r := &RedisEntryReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(s).
+				WithStatusSubresource(&redisv1alpha1.RedisEntry{}).
+				Build(),
+			Scheme:               s,
+			RedisClient:          mockRedis,
+			StatusCoalesceWindow: time.Hour,
+			Clock:                clock,
+		}
+
+		entry := &redisv1alpha1.RedisEntry{
+			ObjectMeta: metav1.ObjectMeta{Name: "coalesced", Namespace: "default"},
+			Spec:       redisv1alpha1.RedisEntrySpec{Key: "coalesced-key", Value: "v"},
+		}
+		gomega.Expect(r.Create(ctx, entry)).To(gomega.Succeed())
+		name := types.NamespacedName{Name: "coalesced", Namespace: "default"}
+
+		syncAttempts := func() int64 {
+			updated := &redisv1alpha1.RedisEntry{}
+			gomega.Expect(r.Get(ctx, name, updated)).To(gomega.Succeed())
+			return updated.Status.SyncAttempts
+		}
+
+		// The first sync changes the conditions and is written right away
+		mock.ExpectSet("coalesced-key", "v", 0).SetVal("OK")
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(syncAttempts()).To(gomega.Equal(int64(1)))
+
+		// Further syncs only refresh counters and timestamps
+		for range 2 {
+			mock.ExpectMGet("coalesced-key").SetVal([]interface{}{"v"})
+			mock.ExpectSet("coalesced-key", "v", 0).SetVal("OK")
+			_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		}
+		gomega.Expect(syncAttempts()).To(gomega.Equal(int64(1)))
+
+		// Once the window has passed, the skipped attempts are included
+		clock.SetTime(clock.Now().Add(time.Hour))
+		mock.ExpectMGet("coalesced-key").SetVal([]interface{}{"v"})
+		mock.ExpectSet("coalesced-key", "v", 0).SetVal("OK")
+		_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(syncAttempts()).To(gomega.Equal(int64(4)))
+		gomega.Expect(mock.ExpectationsWereMet()).To(gomega.Succeed())
+	})
+})
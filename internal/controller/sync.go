@@ -0,0 +1,328 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// maxFailedKeys caps the keys listed in status.failedKeys
+const maxFailedKeys = 20
+
+// entrySync carries one sync of a RedisEntry through the stages of the
+// pipeline. Stages fill in the fields later stages depend on.
+type entrySync struct {
+	name  types.NamespacedName
+	entry *redisv1alpha1.RedisEntry
+
+	// original is the status as fetched, to tell which changes need writing
+	original *redisv1alpha1.RedisEntryStatus
+
+	// client and store are set by connect
+	client *entryClient
+	store  KVStore
+
+	// remaining and hasDeadline are set by expire
+	remaining   time.Duration
+	hasDeadline bool
+
+	// value and ttl are set by resolve
+	value string
+	ttl   time.Duration
+}
+
+// syncResult ends a sync. Unless err is set or skipStatus is true, the
+// entry's status is written before result is returned.
+type syncResult struct {
+	result ctrl.Result
+
+	// err ends the sync without a status write and leaves the retry to
+	// controller-runtime's rate limiter; it is meant for unexpected failures
+	err error
+
+	// skipStatus ends the sync without a status write
+	skipStatus bool
+}
+
+// syncStage is one step of a sync. It returns nil to pass the entry on to the
+// next stage, or a result to end the sync.
+type syncStage func(ctx context.Context, s *entrySync) *syncResult
+
+// close gives back the client picked by connect.
+func (s *entrySync) close() {
+	if s.client != nil {
+		s.client.release()
+	}
+}
+
+// sync runs an entry through every stage and reports the outcome.
+func (r *RedisEntryReconciler) sync(ctx context.Context, s *entrySync) (ctrl.Result, error) {
+	defer s.close()
+	stages := []syncStage{r.connect, r.authorize, r.checkKeys, r.expire, r.resolve, r.throttle, r.verify, r.write}
+	for _, stage := range stages {
+		if res := stage(ctx, s); res != nil {
+			return r.report(ctx, s, res)
+		}
+	}
+	return r.report(ctx, s, &syncResult{})
+}
+
+// report writes the entry's status as left by the stages and returns the
+// result of the sync.
+func (r *RedisEntryReconciler) report(ctx context.Context, s *entrySync, res *syncResult) (ctrl.Result, error) {
+	if res.err != nil || res.skipStatus {
+		return res.result, res.err
+	}
+	if err := r.updateStatus(ctx, s.entry, s.original); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to update RedisEntry status")
+		return ctrl.Result{}, err
+	}
+	return res.result, nil
+}
+
+// fail ends a sync with an Error condition. A zero requeueAfter waits for
+// the entry or its inputs to change.
+func (r *RedisEntryReconciler) fail(s *entrySync, reason, message string, requeueAfter time.Duration) *syncResult {
+	r.setCondition(s.entry, typeError, reason, message)
+	if requeueAfter > 0 {
+		r.Metrics.enqueued(s.name, backlogRetry)
+	}
+	return &syncResult{result: ctrl.Result{RequeueAfter: requeueAfter}}
+}
+
+// connect picks the Redis client for the entry's connection or namespace.
+func (r *RedisEntryReconciler) connect(ctx context.Context, s *entrySync) *syncResult {
+	log := log.FromContext(ctx)
+
+	if r.RedisClient == nil {
+		log.Error(nil, "Redis client not initialized")
+		return r.fail(s, "RedisClientNotInitialized", "Redis client is not initialized", redisErrorRetryDelay)
+	}
+
+	redisClient, err := r.redisClientForEntry(ctx, s.entry)
+	if err != nil && s.entry.Spec.ConnectionRef != nil {
+		log.Error(err, "Failed to get Redis client for RedisConnection")
+		return r.fail(s, reasonConnectionError, err.Error(), redisErrorRetryDelay)
+	}
+	if err != nil {
+		log.Error(err, "Failed to get Redis credentials for namespace")
+		return r.fail(s, reasonCredentialsError, err.Error(), redisErrorRetryDelay)
+	}
+	s.client = redisClient
+	s.store = r.store(r.withCommandTimeout(redisClient.client, s.entry), redisClient.server)
+	return nil
+}
+
+// authorize skips writes the Redis user is known not to be allowed to make.
+func (r *RedisEntryReconciler) authorize(ctx context.Context, s *entrySync) *syncResult {
+	if message := r.insufficientPermissions(ctx, s.client); message != "" {
+		log.FromContext(ctx).Info("Skipping write due to insufficient Redis permissions")
+		return r.fail(s, reasonInsufficientPermissions, message, permissionRecheckInterval)
+	}
+	return nil
+}
+
+// insufficientPermissions describes why writes through a client are known to
+// be refused, or returns "". Only the controller's own user is checked.
+func (r *RedisEntryReconciler) insufficientPermissions(ctx context.Context, redisClient *entryClient) string {
+	if !redisClient.shared {
+		return ""
+	}
+	return r.permissions.insufficient(ctx, r.RedisClient, r.serverInfo(ctx))
+}
+
+// checkKeys refuses keys under a reserved prefix or breaking the key policy.
+// Retrying cannot help until the spec changes.
+func (r *RedisEntryReconciler) checkKeys(ctx context.Context, s *entrySync) *syncResult {
+	log := log.FromContext(ctx)
+
+	if key, reserved := r.reservedKey(s.entry); reserved {
+		log.Info("Refusing to write key with reserved prefix", "key", key)
+		return r.fail(s, reasonReservedKey, fmt.Sprintf("Key %q uses a reserved prefix", key), 0)
+	}
+	if err := r.invalidKey(s.entry); err != nil {
+		log.Info("Refusing to write invalid key", "reason", err.Error())
+		return r.fail(s, reasonInvalidKey, err.Error(), 0)
+	}
+	return nil
+}
+
+// expire deletes the entry's keys once its active deadline has passed.
+func (r *RedisEntryReconciler) expire(ctx context.Context, s *entrySync) *syncResult {
+	log := log.FromContext(ctx)
+
+	s.remaining, s.hasDeadline = untilDeadline(s.entry, r.now())
+	if !s.hasDeadline || s.remaining > 0 {
+		return nil
+	}
+	if err := r.deleteEntry(ctx, s.store, s.entry); err != nil {
+		log.Error(err, "Failed to delete keys after the active deadline")
+		return r.fail(s, reasonRedisError, err.Error(), redisErrorRetryDelay)
+	}
+	log.Info("Active deadline exceeded, deleted keys from Redis", "key", s.entry.Spec.Key)
+	r.complete(s.entry)
+	// Come back to delete the entry after ttlSecondsAfterFinished
+	if remaining, ok := untilCleanup(s.entry, r.now()); ok {
+		return &syncResult{result: ctrl.Result{RequeueAfter: max(remaining, time.Second)}}
+	}
+	return &syncResult{}
+}
+
+// resolve determines the value and TTL to write.
+func (r *RedisEntryReconciler) resolve(ctx context.Context, s *entrySync) *syncResult {
+	if s.entry.Spec.TTL != nil {
+		s.ttl = time.Duration(*s.entry.Spec.TTL) * time.Second
+	}
+
+	value, err := r.resolveValue(ctx, s.entry)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to resolve value")
+		return r.fail(s, reasonValueSourceError, err.Error(), redisErrorRetryDelay)
+	}
+	s.value = value
+	return nil
+}
+
+// throttle keeps writes within the controller-wide write budget.
+func (r *RedisEntryReconciler) throttle(ctx context.Context, s *entrySync) *syncResult {
+	if err := r.waitForWriteBudget(ctx, s.entry); err != nil {
+		log.FromContext(ctx).Error(err, "Failed waiting for the Redis write rate limit")
+		return &syncResult{err: err}
+	}
+	return nil
+}
+
+// verify compares Redis with an already synced entry before it is
+// overwritten, recording drift and the remaining TTL. Read failures don't
+// stop the write.
+func (r *RedisEntryReconciler) verify(ctx context.Context, s *entrySync) *syncResult {
+	log := log.FromContext(ctx)
+
+	drifted, err := r.detectDrift(ctx, s.store, s.entry, s.value)
+	if err != nil {
+		log.Error(err, "Failed to read current value from Redis for drift detection")
+	}
+	if drifted != nil {
+		actual := "<missing>"
+		if drifted.actual != nil {
+			actual = r.valueLogMode(s.entry).redact(*drifted.actual)
+		}
+		log.Info("Detected drift between Redis and the declared value", "key", drifted.key,
+			"expected", r.valueLogMode(s.entry).redact(drifted.expected), "actual", actual)
+		now := metav1.NewTime(r.now())
+		s.entry.Status.LastDriftDetected = &now
+		r.Metrics.recordDrift(s.entry, now.Time)
+	}
+
+	// Sample the remaining TTL before the write refreshes it
+	if r.Metrics.tracksTTL() && isSynced(s.entry) {
+		if ttls, err := s.store.TTL(ctx, s.entry.Spec.Key); err != nil {
+			log.Error(err, "Failed to read remaining TTL")
+		} else {
+			r.Metrics.recordTTL(s.entry, ttls[0])
+		}
+	}
+	return nil
+}
+
+// write stores the entry in Redis and records the outcome in its status.
+func (r *RedisEntryReconciler) write(ctx context.Context, s *entrySync) *syncResult {
+	log := log.FromContext(ctx)
+	redisEntry := s.entry
+
+	start := r.now()
+	syncTime := metav1.NewTime(start)
+	redisEntry.Status.SyncAttempts++
+	redisEntry.Status.LastSyncTime = &syncTime
+
+	err := r.writeEntry(ctx, s.store, redisEntry, s.value, s.ttl)
+	if err != nil && isReadOnlyError(err) && s.client.conns != nil {
+		// The address leads to a replica, typically after a failover. New
+		// connections resolve it again and should reach the new primary.
+		// Only the connections of the entry's own server are dropped.
+		if s.client.conns.reconnect() {
+			log.Info("Redis refused the write as a read-only replica, reconnected")
+		}
+		err = r.writeEntry(ctx, s.store, redisEntry, s.value, s.ttl)
+	}
+	duration := r.now().Sub(start)
+	redisEntry.Status.LastSyncDurationMillis = duration.Milliseconds()
+	if err != nil {
+		r.Metrics.recordSync(redisEntry, resultError, duration)
+		redisEntry.Status.LastError = err.Error()
+		redisEntry.Status.FailedKeys = failedKeys(err)
+		log.Error(err, "Failed to set key-value pair in Redis")
+		if isAuthError(err) {
+			// Retrying cannot help until the credentials change. A change to
+			// the credentials Secret, or the health monitor seeing Redis
+			// accept the controller's credentials again, resyncs the entry.
+			return r.fail(s, reasonAuthFailed, err.Error(), 0)
+		}
+		// Redis errors are expected and retried after a delay. Returning the
+		// error would make controller-runtime ignore the delay in favor of
+		// its own rate limiter.
+		delay := redisErrorRetryDelay
+		failures := r.failures.inc(s.name)
+		if policy := redisEntry.Spec.RetryPolicy; policy != nil {
+			delay = retryDelay(policy, failures)
+		}
+		return r.fail(s, reasonRedisError, err.Error(), delay)
+	}
+	r.failures.reset(s.name)
+	log.V(1).Info("Wrote entry to Redis", "key", redisEntry.Spec.Key, "value", r.valueLogMode(redisEntry).redact(s.value))
+
+	r.Metrics.recordSync(redisEntry, resultSuccess, duration)
+	redisEntry.Status.LastError = ""
+	redisEntry.Status.FailedKeys = nil
+	redisEntry.Status.LastUpdated = &syncTime
+	// A deadline extended after completion makes the entry active again
+	redisEntry.Status.CompletionTime = nil
+	meta.RemoveStatusCondition(&redisEntry.Status.Conditions, typeCompleted)
+	meta.RemoveStatusCondition(&redisEntry.Status.Conditions, typeError)
+	r.setCondition(redisEntry, typeAvailable, reasonSuccess, "Key-value pair successfully set in Redis")
+
+	// Come back when the deadline passes
+	if s.hasDeadline {
+		return &syncResult{result: ctrl.Result{RequeueAfter: s.remaining}}
+	}
+	return &syncResult{}
+}
+
+// failedKeys lists the keys a partially applied write failed on, up to
+// maxFailedKeys.
+func failedKeys(err error) []redisv1alpha1.KeyFailure {
+	var writeErr *WriteError
+	if !errors.As(err, &writeErr) {
+		return nil
+	}
+	failed := writeErr.Failed[:min(len(writeErr.Failed), maxFailedKeys)]
+	failures := make([]redisv1alpha1.KeyFailure, len(failed))
+	for i, failure := range failed {
+		failures[i] = redisv1alpha1.KeyFailure{Key: failure.Key, Error: failure.Err.Error()}
+	}
+	return failures
+}
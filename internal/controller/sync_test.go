@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	redismock "github.com/go-redis/redismock/v9"
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clocktesting "k8s.io/utils/clock/testing"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var _ = ginkgo.Describe("Sync Stages", func() {
+	var (
+		ctx     context.Context
+		created time.Time
+		s       *entrySync
+	)
+
+	ginkgo.BeforeEach(func() {
+		ctx = context.Background()
+		created = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		entry := &redisv1alpha1.RedisEntry{
+			ObjectMeta: metav1.ObjectMeta{Name: "staged", Namespace: "default", CreationTimestamp: metav1.NewTime(created)},
+			Spec:       redisv1alpha1.RedisEntrySpec{Key: "app:staged", Value: "v"},
+		}
+		s = &entrySync{entry: entry, original: entry.Status.DeepCopy()}
+	})
+
+	ginkgo.It("should stop at connect without a Redis client", func() {
+		r := &RedisEntryReconciler{}
+		res := r.connect(ctx, s)
+		gomega.Expect(res).NotTo(gomega.BeNil())
+		gomega.Expect(res.result).To(gomega.Equal(ctrl.Result{RequeueAfter: redisErrorRetryDelay}))
+		gomega.Expect(meta.FindStatusCondition(s.entry.Status.Conditions, typeError).Reason).
+			To(gomega.Equal("RedisClientNotInitialized"))
+	})
+
+	ginkgo.It("should refuse reserved keys without retrying", func() {
+		r := &RedisEntryReconciler{ReservedKeyPrefixes: []string{"redis-ctrl:"}}
+		gomega.Expect(r.checkKeys(ctx, s)).To(gomega.BeNil())
+
+		s.entry.Spec.Key = "redis-ctrl:marker"
+		res := r.checkKeys(ctx, s)
+		gomega.Expect(res).NotTo(gomega.BeNil())
+		gomega.Expect(res.result).To(gomega.Equal(ctrl.Result{}))
+		gomega.Expect(meta.FindStatusCondition(s.entry.Status.Conditions, typeError).Reason).
+			To(gomega.Equal(reasonReservedKey))
+	})
+
+	ginkgo.It("should refuse the marker key", func() {
+		r := &RedisEntryReconciler{MarkerKey: DefaultMarkerKey}
+		s.entry.Spec.Entries = map[string]string{DefaultMarkerKey: "x"}
+		key, reserved := r.reservedKey(s.entry)
+		gomega.Expect(reserved).To(gomega.BeTrue())
+		gomega.Expect(key).To(gomega.Equal(DefaultMarkerKey))
+	})
+
+	ginkgo.It("should pass entries before their deadline on and complete them after", func() {
+		mockRedis, mock := redismock.NewClientMock()
+		clock := clocktesting.NewFakePassiveClock(created.Add(30 * time.Second))
+		r := &RedisEntryReconciler{Clock: clock}
+		s.store = r.store(mockRedis, nil)
+		s.entry.Spec.ActiveDeadlineSeconds = ptr.To[int64](60)
+
+		gomega.Expect(r.expire(ctx, s)).To(gomega.BeNil())
+		gomega.Expect(s.hasDeadline).To(gomega.BeTrue())
+		gomega.Expect(s.remaining).To(gomega.Equal(30 * time.Second))
+
+		clock.SetTime(created.Add(time.Minute))
+		mock.ExpectUnlink("app:staged").SetVal(1)
+		res := r.expire(ctx, s)
+		gomega.Expect(res).To(gomega.Equal(&syncResult{}))
+		gomega.Expect(meta.IsStatusConditionTrue(s.entry.Status.Conditions, typeCompleted)).To(gomega.BeTrue())
+		gomega.Expect(mock.ExpectationsWereMet()).To(gomega.Succeed())
+	})
+
+	ginkgo.It("should retry failed writes after the policy's delay", func() {
+		mockRedis, mock := redismock.NewClientMock()
+		r := &RedisEntryReconciler{}
+		s.store = r.store(mockRedis, nil)
+		s.value = "v"
+		s.entry.Spec.RetryPolicy = &redisv1alpha1.RetryPolicy{InitialDelay: &metav1.Duration{Duration: time.Second}}
+
+		mock.ExpectSet("app:staged", "v", 0).SetErr(errors.New("connection reset"))
+		res := r.write(ctx, s)
+		gomega.Expect(res.err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(res.result).To(gomega.Equal(ctrl.Result{RequeueAfter: time.Second}))
+		gomega.Expect(s.entry.Status.LastError).To(gomega.Equal("connection reset"))
+		gomega.Expect(s.entry.Status.SyncAttempts).To(gomega.Equal(int64(1)))
+		gomega.Expect(mock.ExpectationsWereMet()).To(gomega.Succeed())
+	})
+
+	ginkgo.It("should not write the status of syncs ended by an error", func() {
+		// A status write would fail without a client
+		r := &RedisEntryReconciler{}
+		result, err := r.report(ctx, s, &syncResult{err: context.Canceled})
+		gomega.Expect(err).To(gomega.MatchError(context.Canceled))
+		gomega.Expect(result).To(gomega.Equal(ctrl.Result{}))
+	})
+})
@@ -0,0 +1,79 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sync"
+	"time"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	redisv9 "github.com/redis/go-redis/v9"
+)
+
+// timeoutClients caches clients that share the settings of a base client but
+// use an entry's command timeout for reads and writes. The zero value is
+// ready to use.
+type timeoutClients struct {
+	mu      sync.Mutex
+	clients map[*redisv9.Client]map[time.Duration]*redisv9.Client
+}
+
+// get returns a client like base with the given command timeout.
+func (c *timeoutClients) get(base *redisv9.Client, timeout time.Duration) *redisv9.Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cached, ok := c.clients[base][timeout]; ok {
+		return cached
+	}
+	opts := *base.Options()
+	opts.ReadTimeout, opts.WriteTimeout = timeout, timeout
+	redisClient := redisv9.NewClient(&opts)
+
+	if c.clients == nil {
+		c.clients = map[*redisv9.Client]map[time.Duration]*redisv9.Client{}
+	}
+	if c.clients[base] == nil {
+		c.clients[base] = map[time.Duration]*redisv9.Client{}
+	}
+	c.clients[base][timeout] = redisClient
+	return redisClient
+}
+
+// release closes the clients derived from a base client that is going away.
+func (c *timeoutClients) release(base *redisv9.Client) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, redisClient := range c.clients[base] {
+		_ = redisClient.Close()
+	}
+	delete(c.clients, base)
+}
+
+// withCommandTimeout returns the client to use for an entry that overrides
+// the command timeout. Clients other than a plain *redisv9.Client are
+// returned unchanged.
+func (r *RedisEntryReconciler) withCommandTimeout(redisClient redisv9.UniversalClient,
+	redisEntry *redisv1alpha1.RedisEntry) redisv9.UniversalClient {
+	seconds := redisEntry.Spec.CommandTimeoutSeconds
+	base, ok := redisClient.(*redisv9.Client)
+	if seconds == nil || !ok {
+		return redisClient
+	}
+	return r.timeoutClients.get(base, time.Duration(*seconds)*time.Second)
+}
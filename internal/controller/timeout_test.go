@@ -0,0 +1,49 @@
+package controller
+
+import (
+	"time"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	redisv9 "github.com/redis/go-redis/v9"
+	"k8s.io/utils/ptr"
+)
+
+var _ = ginkgo.Describe("Command Timeouts", func() {
+	var (
+		base *redisv9.Client
+		r    *RedisEntryReconciler
+	)
+
+	ginkgo.BeforeEach(func() {
+		base = redisv9.NewClient(&redisv9.Options{Addr: "redis.example:6379", DB: 2})
+		r = &RedisEntryReconciler{RedisClient: base}
+	})
+
+	ginkgo.AfterEach(func() {
+		r.timeoutClients.release(base)
+		gomega.Expect(base.Close()).To(gomega.Succeed())
+	})
+
+	ginkgo.It("should use the shared client without an override", func() {
+		entry := &redisv1alpha1.RedisEntry{}
+		gomega.Expect(r.withCommandTimeout(base, entry)).To(gomega.BeIdenticalTo(base))
+	})
+
+	ginkgo.It("should derive and reuse a client with the entry's timeout", func() {
+		entry := &redisv1alpha1.RedisEntry{
+			Spec: redisv1alpha1.RedisEntrySpec{CommandTimeoutSeconds: ptr.To[int32](30)},
+		}
+		derived := r.withCommandTimeout(base, entry).(*redisv9.Client)
+		gomega.Expect(derived).NotTo(gomega.BeIdenticalTo(base))
+		gomega.Expect(derived.Options().Addr).To(gomega.Equal("redis.example:6379"))
+		gomega.Expect(derived.Options().DB).To(gomega.Equal(2))
+		gomega.Expect(derived.Options().ReadTimeout).To(gomega.Equal(30 * time.Second))
+		gomega.Expect(derived.Options().WriteTimeout).To(gomega.Equal(30 * time.Second))
+		gomega.Expect(r.withCommandTimeout(base, entry)).To(gomega.BeIdenticalTo(derived))
+
+		r.timeoutClients.release(base)
+		gomega.Expect(r.timeoutClients.clients).To(gomega.BeEmpty())
+	})
+})
@@ -10,7 +10,9 @@ import (
 	ginkgo "github.com/onsi/ginkgo/v2"
 	"github.com/onsi/gomega"
 	redisv9 "github.com/redis/go-redis/v9"
+	"golang.org/x/time/rate"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -95,6 +97,10 @@ var _ = ginkgo.Describe("RedisEntry Controller Unit Tests", func() {
 			gomega.Expect(updatedEntry.Status.Conditions).To(gomega.HaveLen(1))
 			gomega.Expect(updatedEntry.Status.Conditions[0].Type).To(gomega.Equal("Available"))
 			gomega.Expect(updatedEntry.Status.Conditions[0].Status).To(gomega.Equal(metav1.ConditionTrue))
+			gomega.Expect(updatedEntry.Status.SyncAttempts).To(gomega.Equal(int64(1)))
+			gomega.Expect(updatedEntry.Status.LastSyncTime).NotTo(gomega.BeNil())
+			gomega.Expect(updatedEntry.Status.LastUpdated).NotTo(gomega.BeNil())
+			gomega.Expect(updatedEntry.Status.LastError).To(gomega.BeEmpty())
 		})
 
 		ginkgo.It("should handle TTL operations", func() {
@@ -138,6 +144,282 @@ var _ = ginkgo.Describe("RedisEntry Controller Unit Tests", func() {
 			gomega.Expect(updatedEntry.Status.Conditions[0].Status).To(gomega.Equal(metav1.ConditionTrue))
 		})
 
+		ginkgo.It("should write additional entries in one transaction", func() {
+			ttl := int64(60)
+			redisEntry = &redisv1alpha1.RedisEntry{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-entries",
+					Namespace: "default",
+				},
+				Spec: redisv1alpha1.RedisEntrySpec{
+					Key:   "main-key",
+					Value: "main-value",
+					TTL:   &ttl,
+					Entries: map[string]string{
+						"extra-b": "value-b",
+						"extra-a": "value-a",
+					},
+				},
+			}
+
+			// Create the RedisEntry
+			gomega.Expect(controllerReconciler.Client.Create(ctx, redisEntry)).To(gomega.Succeed())
+
+			// Set up Redis mock expectations for the transaction
+			mock.ExpectTxPipeline()
+			mock.ExpectSet("main-key", "main-value", time.Duration(ttl)*time.Second).SetVal("OK")
+			mock.ExpectMSet("extra-a", "value-a", "extra-b", "value-b").SetVal("OK")
+			mock.ExpectExpire("extra-a", time.Duration(ttl)*time.Second).SetVal(true)
+			mock.ExpectExpire("extra-b", time.Duration(ttl)*time.Second).SetVal(true)
+			mock.ExpectTxPipelineExec()
+
+			// Reconcile
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      "test-entries",
+					Namespace: "default",
+				},
+			})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+			// Verify the RedisEntry was updated
+			updatedEntry := &redisv1alpha1.RedisEntry{}
+			err = controllerReconciler.Get(ctx, types.NamespacedName{
+				Name:      "test-entries",
+				Namespace: "default",
+			}, updatedEntry)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(updatedEntry.Status.Conditions).To(gomega.HaveLen(1))
+			gomega.Expect(updatedEntry.Status.Conditions[0].Type).To(gomega.Equal("Available"))
+		})
+
+		ginkgo.It("should pipeline additional entries without a transaction in proxy mode", func() {
+			controllerReconciler.ProxyMode = true
+			redisEntry = &redisv1alpha1.RedisEntry{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-proxy",
+					Namespace: "default",
+				},
+				Spec: redisv1alpha1.RedisEntrySpec{
+					Key:     "main-key",
+					Value:   "main-value",
+					Entries: map[string]string{"extra-a": "value-a"},
+				},
+			}
+			gomega.Expect(controllerReconciler.Client.Create(ctx, redisEntry)).To(gomega.Succeed())
+
+			// No MULTI/EXEC is expected around the commands
+			mock.ExpectSet("main-key", "main-value", 0).SetVal("OK")
+			mock.ExpectMSet("extra-a", "value-a").SetVal("OK")
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      "test-proxy",
+					Namespace: "default",
+				},
+			})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		})
+
+		ginkgo.It("should refuse keys with a reserved prefix", func() {
+			controllerReconciler.ReservedKeyPrefixes = []string{"__keyspace@", "app:internal:"}
+			redisEntry = &redisv1alpha1.RedisEntry{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-reserved",
+					Namespace: "default",
+				},
+				Spec: redisv1alpha1.RedisEntrySpec{
+					Key:   "allowed-key",
+					Value: "value",
+					Entries: map[string]string{
+						"app:internal:lock": "1",
+					},
+				},
+			}
+
+			// Create the RedisEntry
+			gomega.Expect(controllerReconciler.Client.Create(ctx, redisEntry)).To(gomega.Succeed())
+
+			// Reconcile without any Redis expectations
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      "test-reserved",
+					Namespace: "default",
+				},
+			})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(result.Requeue).To(gomega.BeFalse())
+
+			// Verify error status was set
+			updatedEntry := &redisv1alpha1.RedisEntry{}
+			err = controllerReconciler.Get(ctx, types.NamespacedName{
+				Name:      "test-reserved",
+				Namespace: "default",
+			}, updatedEntry)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(updatedEntry.Status.Conditions).To(gomega.HaveLen(1))
+			gomega.Expect(updatedEntry.Status.Conditions[0].Type).To(gomega.Equal("Error"))
+			gomega.Expect(updatedEntry.Status.Conditions[0].Reason).To(gomega.Equal("ReservedKey"))
+		})
+
+		ginkgo.It("should refuse keys with control characters", func() {
+			redisEntry = &redisv1alpha1.RedisEntry{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-invalid-key",
+					Namespace: "default",
+				},
+				Spec: redisv1alpha1.RedisEntrySpec{
+					Key:   "allowed-key",
+					Value: "value",
+					Entries: map[string]string{
+						"injected\r\nFLUSHALL": "1",
+					},
+				},
+			}
+			gomega.Expect(controllerReconciler.Client.Create(ctx, redisEntry)).To(gomega.Succeed())
+
+			// Reconcile without any Redis expectations
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      "test-invalid-key",
+					Namespace: "default",
+				},
+			})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(result.Requeue).To(gomega.BeFalse())
+
+			updatedEntry := &redisv1alpha1.RedisEntry{}
+			err = controllerReconciler.Get(ctx, types.NamespacedName{
+				Name:      "test-invalid-key",
+				Namespace: "default",
+			}, updatedEntry)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(updatedEntry.Status.Conditions).To(gomega.HaveLen(1))
+			gomega.Expect(updatedEntry.Status.Conditions[0].Reason).To(gomega.Equal("InvalidKey"))
+		})
+
+		ginkgo.It("should draw writes from the global write limiter", func() {
+			controllerReconciler.WriteLimiter = rate.NewLimiter(rate.Every(time.Hour), 2)
+			redisEntry = &redisv1alpha1.RedisEntry{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-throttle",
+					Namespace: "default",
+				},
+				Spec: redisv1alpha1.RedisEntrySpec{
+					Key:   "throttle-key",
+					Value: "throttle-value",
+				},
+			}
+
+			// Create the RedisEntry
+			gomega.Expect(controllerReconciler.Client.Create(ctx, redisEntry)).To(gomega.Succeed())
+
+			mock.ExpectSet("throttle-key", "throttle-value", 0).SetVal("OK")
+
+			// Reconcile
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      "test-throttle",
+					Namespace: "default",
+				},
+			})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+			// One of the two tokens was spent on the write
+			gomega.Expect(controllerReconciler.WriteLimiter.Tokens()).To(gomega.BeNumerically("~", 1, 0.01))
+		})
+
+		ginkgo.It("should detect drift on an already synced entry", func() {
+			redisEntry = &redisv1alpha1.RedisEntry{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-drift",
+					Namespace: "default",
+				},
+				Spec: redisv1alpha1.RedisEntrySpec{
+					Key:   "drift-key",
+					Value: "declared",
+				},
+			}
+
+			// Create the RedisEntry and mark it as synced
+			gomega.Expect(controllerReconciler.Client.Create(ctx, redisEntry)).To(gomega.Succeed())
+			redisEntry.Status.Conditions = []metav1.Condition{{
+				Type:               "Available",
+				Status:             metav1.ConditionTrue,
+				Reason:             "Success",
+				LastTransitionTime: metav1.Now(),
+			}}
+			gomega.Expect(controllerReconciler.Client.Status().Update(ctx, redisEntry)).To(gomega.Succeed())
+
+			// Someone changed the key behind the controller's back
+			mock.ExpectMGet("drift-key").SetVal([]interface{}{"changed"})
+			mock.ExpectSet("drift-key", "declared", 0).SetVal("OK")
+
+			// Reconcile
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      "test-drift",
+					Namespace: "default",
+				},
+			})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+			// Verify the drift was recorded
+			updatedEntry := &redisv1alpha1.RedisEntry{}
+			err = controllerReconciler.Get(ctx, types.NamespacedName{
+				Name:      "test-drift",
+				Namespace: "default",
+			}, updatedEntry)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(updatedEntry.Status.LastDriftDetected).NotTo(gomega.BeNil())
+		})
+
+		ginkgo.It("should not report expired keys with a TTL as drift", func() {
+			ttl := int64(30)
+			redisEntry = &redisv1alpha1.RedisEntry{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-expired",
+					Namespace: "default",
+				},
+				Spec: redisv1alpha1.RedisEntrySpec{
+					Key:   "expired-key",
+					Value: "declared",
+					TTL:   &ttl,
+				},
+			}
+
+			// Create the RedisEntry and mark it as synced
+			gomega.Expect(controllerReconciler.Client.Create(ctx, redisEntry)).To(gomega.Succeed())
+			redisEntry.Status.Conditions = []metav1.Condition{{
+				Type:               "Available",
+				Status:             metav1.ConditionTrue,
+				Reason:             "Success",
+				LastTransitionTime: metav1.Now(),
+			}}
+			gomega.Expect(controllerReconciler.Client.Status().Update(ctx, redisEntry)).To(gomega.Succeed())
+
+			// The key expired on its own
+			mock.ExpectMGet("expired-key").SetVal([]interface{}{nil})
+			mock.ExpectSet("expired-key", "declared", 30*time.Second).SetVal("OK")
+
+			// Reconcile
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      "test-expired",
+					Namespace: "default",
+				},
+			})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+			updatedEntry := &redisv1alpha1.RedisEntry{}
+			err = controllerReconciler.Get(ctx, types.NamespacedName{
+				Name:      "test-expired",
+				Namespace: "default",
+			}, updatedEntry)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(updatedEntry.Status.LastDriftDetected).To(gomega.BeNil())
+		})
+
 		ginkgo.It("should handle Redis errors", func() {
 			redisEntry = &redisv1alpha1.RedisEntry{
 				ObjectMeta: metav1.ObjectMeta{
@@ -163,8 +445,7 @@ var _ = ginkgo.Describe("RedisEntry Controller Unit Tests", func() {
 					Namespace: "default",
 				},
 			})
-			gomega.Expect(err).To(gomega.HaveOccurred())
-			gomega.Expect(result.Requeue).To(gomega.BeTrue())
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
 			gomega.Expect(result.RequeueAfter).To(gomega.Equal(5 * time.Second))
 
 			// Verify error status was set
@@ -177,6 +458,43 @@ var _ = ginkgo.Describe("RedisEntry Controller Unit Tests", func() {
 			gomega.Expect(updatedEntry.Status.Conditions).To(gomega.HaveLen(1))
 			gomega.Expect(updatedEntry.Status.Conditions[0].Type).To(gomega.Equal("Error"))
 			gomega.Expect(updatedEntry.Status.Conditions[0].Status).To(gomega.Equal(metav1.ConditionTrue))
+			gomega.Expect(updatedEntry.Status.SyncAttempts).To(gomega.Equal(int64(1)))
+			gomega.Expect(updatedEntry.Status.LastSyncTime).NotTo(gomega.BeNil())
+			gomega.Expect(updatedEntry.Status.LastUpdated).To(gomega.BeNil())
+			gomega.Expect(updatedEntry.Status.LastError).To(gomega.Equal("redis error"))
+
+			// The retry succeeds and clears the error
+			mock.ExpectSet("error-key", "error-value", 0).SetVal("OK")
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      "test-error",
+					Namespace: "default",
+				},
+			})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			err = controllerReconciler.Get(ctx, types.NamespacedName{
+				Name:      "test-error",
+				Namespace: "default",
+			}, updatedEntry)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(updatedEntry.Status.Conditions).To(gomega.HaveLen(1))
+			gomega.Expect(updatedEntry.Status.Conditions[0].Type).To(gomega.Equal("Available"))
+			gomega.Expect(updatedEntry.Status.LastError).To(gomega.BeEmpty())
+		})
+	})
+
+	ginkgo.Context("Entry selection", func() {
+		ginkgo.It("should only manage entries matching the selector", func() {
+			controllerReconciler.EntrySelector = labels.SelectorFromSet(labels.Set{"redis.aaspcodes.github.io/managed": "true"})
+
+			labeled := &redisv1alpha1.RedisEntry{ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{"redis.aaspcodes.github.io/managed": "true"},
+			}}
+			gomega.Expect(controllerReconciler.managesEntry(labeled)).To(gomega.BeTrue())
+			gomega.Expect(controllerReconciler.managesEntry(&redisv1alpha1.RedisEntry{})).To(gomega.BeFalse())
+
+			controllerReconciler.EntrySelector = nil
+			gomega.Expect(controllerReconciler.managesEntry(&redisv1alpha1.RedisEntry{})).To(gomega.BeTrue())
 		})
 	})
 })
@@ -0,0 +1,340 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	// maxHTTPValueBytes bounds the size of content fetched for valueFrom.http
+	maxHTTPValueBytes = 16 << 20
+
+	// httpValueTimeout bounds a single fetch when no HTTPClient is configured
+	httpValueTimeout = 30 * time.Second
+
+	// valueSecretField indexes entries by the Secret holding their value
+	valueSecretField = "spec.valueFrom.secretKeyRef.name"
+
+	// valueConfigMapField indexes entries by the ConfigMap holding their value
+	valueConfigMapField = "spec.valueFrom.configMapKeyRef.name"
+)
+
+// valueCache remembers fetched values per entry. Content is pinned by its
+// checksum, so a cached value stays valid for as long as the pin is unchanged.
+// The zero value is ready to use.
+type valueCache struct {
+	mu     sync.Mutex
+	values map[types.NamespacedName]cachedValue
+}
+
+type cachedValue struct {
+	sha256 string
+	value  string
+}
+
+func (c *valueCache) get(name types.NamespacedName, sum string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, ok := c.values[name]
+	if !ok || cached.sha256 != sum {
+		return "", false
+	}
+	return cached.value, true
+}
+
+func (c *valueCache) put(name types.NamespacedName, sum, value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.values == nil {
+		c.values = map[types.NamespacedName]cachedValue{}
+	}
+	c.values[name] = cachedValue{sha256: sum, value: value}
+}
+
+func (c *valueCache) forget(name types.NamespacedName) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.values, name)
+}
+
+// resolveValue returns the value to write for the entry's main key, fetching
+// it from spec.valueFrom or serializing spec.structuredValue when set.
+func (r *RedisEntryReconciler) resolveValue(ctx context.Context, redisEntry *redisv1alpha1.RedisEntry) (string, error) {
+	if redisEntry.Spec.StructuredValue != nil {
+		return serializeStructuredValue(redisEntry.Spec.StructuredValue)
+	}
+	source := redisEntry.Spec.ValueFrom
+	if source != nil && source.SecretKeyRef != nil {
+		return r.readSecretValue(ctx, redisEntry.Namespace, source.SecretKeyRef)
+	}
+	if source != nil && source.ConfigMapKeyRef != nil {
+		return r.readConfigMapValue(ctx, redisEntry.Namespace, source.ConfigMapKeyRef)
+	}
+	if source == nil || source.HTTP == nil {
+		return redisEntry.Spec.Value, nil
+	}
+
+	name := types.NamespacedName{Namespace: redisEntry.Namespace, Name: redisEntry.Name}
+	if value, ok := r.values.get(name, source.HTTP.SHA256); ok {
+		return value, nil
+	}
+	value, err := r.fetchHTTPValue(ctx, redisEntry.Namespace, source.HTTP)
+	if err != nil {
+		return "", err
+	}
+	r.values.put(name, source.HTTP.SHA256, value)
+	return value, nil
+}
+
+// serializeStructuredValue renders a structured value as compact JSON with
+// object keys sorted, so the same object always yields the same bytes no
+// matter how the API server ordered it.
+func serializeStructuredValue(raw *runtime.RawExtension) (string, error) {
+	// Numbers are kept as written rather than rounded through float64
+	decoder := json.NewDecoder(bytes.NewReader(raw.Raw))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return "", fmt.Errorf("invalid structuredValue: %w", err)
+	}
+	// encoding/json sorts map keys; HTML escaping would only obscure values
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return "", fmt.Errorf("invalid structuredValue: %w", err)
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// fetchHTTPValue downloads the content and verifies it against the pinned
+// checksum.
+func (r *RedisEntryReconciler) fetchHTTPValue(ctx context.Context, namespace string, source *redisv1alpha1.HTTPValueSource) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source.URL, nil)
+	if err != nil {
+		return "", fmt.Errorf("invalid value URL: %w", err)
+	}
+	if ref := source.AuthHeaderSecretRef; ref != nil {
+		header, err := r.readSecretValue(ctx, namespace, ref)
+		if err != nil {
+			return "", fmt.Errorf("failed to read auth header: %w", err)
+		}
+		req.Header.Set("Authorization", header)
+	}
+
+	httpClient := r.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: httpValueTimeout}
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch value: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to fetch value: unexpected status %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPValueBytes+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to read value: %w", err)
+	}
+	if len(body) > maxHTTPValueBytes {
+		return "", fmt.Errorf("value exceeds the %d byte limit", maxHTTPValueBytes)
+	}
+
+	sum := sha256.Sum256(body)
+	if actual := hex.EncodeToString(sum[:]); actual != source.SHA256 {
+		return "", fmt.Errorf("checksum mismatch: content has sha256 %s, expected %s", actual, source.SHA256)
+	}
+	return string(body), nil
+}
+
+// sourceReader returns the reader for Secrets and ConfigMaps values are read
+// from. They are not in the manager's cache: it only holds credentials
+// Secrets (see SecretCacheOptions), and only the metadata of ConfigMaps is
+// watched.
+func (r *RedisEntryReconciler) sourceReader() client.Reader {
+	if r.APIReader != nil {
+		return r.APIReader
+	}
+	return r.Client
+}
+
+// readSecretValue returns the value of a Secret key in a namespace.
+func (r *RedisEntryReconciler) readSecretValue(ctx context.Context, namespace string, ref *corev1.SecretKeySelector) (string, error) {
+	secret := &corev1.Secret{}
+	if err := r.sourceReader().Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.Name}, secret); err != nil {
+		return "", fmt.Errorf("failed to read secret %q: %w", ref.Name, err)
+	}
+	value, ok := secret.Data[ref.Key]
+	if !ok {
+		return "", fmt.Errorf("secret %q has no key %q", ref.Name, ref.Key)
+	}
+	return string(value), nil
+}
+
+// readConfigMapValue returns the value of a ConfigMap key in a namespace.
+func (r *RedisEntryReconciler) readConfigMapValue(ctx context.Context, namespace string, ref *corev1.ConfigMapKeySelector) (string, error) {
+	configMap := &corev1.ConfigMap{}
+	if err := r.sourceReader().Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.Name}, configMap); err != nil {
+		return "", fmt.Errorf("failed to read configmap %q: %w", ref.Name, err)
+	}
+	if value, ok := configMap.Data[ref.Key]; ok {
+		return value, nil
+	}
+	if value, ok := configMap.BinaryData[ref.Key]; ok {
+		return string(value), nil
+	}
+	return "", fmt.Errorf("configmap %q has no key %q", ref.Name, ref.Key)
+}
+
+// valueSecret returns the name of the Secret an entry reads its value from.
+func valueSecret(redisEntry *redisv1alpha1.RedisEntry) (string, bool) {
+	source := redisEntry.Spec.ValueFrom
+	if source == nil || source.SecretKeyRef == nil {
+		return "", false
+	}
+	return source.SecretKeyRef.Name, true
+}
+
+// valueConfigMap returns the name of the ConfigMap an entry reads its value
+// from.
+func valueConfigMap(redisEntry *redisv1alpha1.RedisEntry) (string, bool) {
+	source := redisEntry.Spec.ValueFrom
+	if source == nil || source.ConfigMapKeyRef == nil {
+		return "", false
+	}
+	return source.ConfigMapKeyRef.Name, true
+}
+
+// valueLogMode returns how the entry's values may be shown. Values read from
+// a Secret are never shown verbatim.
+func (r *RedisEntryReconciler) valueLogMode(redisEntry *redisv1alpha1.RedisEntry) ValueLogMode {
+	if _, ok := valueSecret(redisEntry); ok && r.LogValues == ValueLogAlways {
+		return ValueLogHashed
+	}
+	return r.LogValues
+}
+
+// valueSourceIndexes maps the fields entries are indexed by to the
+// functions extracting them.
+var valueSourceIndexes = map[string]func(*redisv1alpha1.RedisEntry) (string, bool){
+	valueSecretField:    valueSecret,
+	valueConfigMapField: valueConfigMap,
+}
+
+// indexValueSources registers the value source indexes with the manager's
+// cache.
+func indexValueSources(ctx context.Context, indexer client.FieldIndexer) error {
+	for field, ref := range valueSourceIndexes {
+		if err := indexer.IndexField(ctx, &redisv1alpha1.RedisEntry{}, field, entryIndexer(ref)); err != nil {
+			return fmt.Errorf("failed to index RedisEntries by %s: %w", field, err)
+		}
+	}
+	return nil
+}
+
+// entryIndexer adapts a function returning an entry's value source to a
+// field index.
+func entryIndexer(ref func(*redisv1alpha1.RedisEntry) (string, bool)) client.IndexerFunc {
+	return func(obj client.Object) []string {
+		if name, ok := ref(obj.(*redisv1alpha1.RedisEntry)); ok {
+			return []string{name}
+		}
+		return nil
+	}
+}
+
+// entriesForValueSecret maps a change to a Secret to the entries reading
+// their value from it.
+func (r *RedisEntryReconciler) entriesForValueSecret(ctx context.Context, secret *metav1.PartialObjectMetadata) []ctrl.Request {
+	return r.entriesIndexed(ctx, secret, valueSecretField)
+}
+
+// entriesForValueConfigMap maps a change to a ConfigMap to the entries
+// reading their value from it.
+func (r *RedisEntryReconciler) entriesForValueConfigMap(ctx context.Context, configMap client.Object) []ctrl.Request {
+	return r.entriesIndexed(ctx, configMap, valueConfigMapField)
+}
+
+// entriesIndexed returns the managed entries in the namespace of obj whose
+// value source, as indexed by field, is obj. Every Secret and ConfigMap in
+// the cluster is watched, so the entries are looked up in the manager's cache
+// rather than listed from the API server.
+func (r *RedisEntryReconciler) entriesIndexed(ctx context.Context, obj client.Object, field string) []ctrl.Request {
+	entries := &redisv1alpha1.RedisEntryList{}
+	if err := r.List(ctx, entries, client.InNamespace(obj.GetNamespace()),
+		client.MatchingFields{field: obj.GetName()}); err != nil {
+		return nil
+	}
+	var requests []ctrl.Request
+	for i := range entries.Items {
+		if entry := &entries.Items[i]; r.managesEntry(entry) {
+			key := client.ObjectKeyFromObject(entry)
+			r.Metrics.enqueued(key, backlogOther)
+			requests = append(requests, ctrl.Request{NamespacedName: key})
+		}
+	}
+	return requests
+}
+
+// valueSecretSource watches the metadata of all Secrets through a cache of
+// its own, since the manager's cache only holds credentials Secrets. Values
+// are still read from the API server when an entry is synced.
+func (r *RedisEntryReconciler) valueSecretSource(mgr ctrl.Manager) (source.Source, error) {
+	secrets, err := cache.New(mgr.GetConfig(), cache.Options{
+		HTTPClient:       mgr.GetHTTPClient(),
+		Scheme:           mgr.GetScheme(),
+		Mapper:           mgr.GetRESTMapper(),
+		DefaultTransform: cache.TransformStripManagedFields(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Secret metadata cache: %w", err)
+	}
+	if err := mgr.Add(secrets); err != nil {
+		return nil, err
+	}
+	r.valueSecrets = secrets
+	secret := &metav1.PartialObjectMetadata{}
+	secret.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Secret"))
+	return source.Kind(secrets, secret, handler.TypedEnqueueRequestsFromMapFunc(r.entriesForValueSecret),
+		predicate.TypedResourceVersionChangedPredicate[*metav1.PartialObjectMetadata]{}), nil
+}
@@ -0,0 +1,314 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	redismock "github.com/go-redis/redismock/v9"
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = ginkgo.Describe("HTTP Value Source", func() {
+	const content = "10.0.0.0/8 internal\n"
+
+	var (
+		ctx      context.Context
+		mock     redismock.ClientMock
+		r        *RedisEntryReconciler
+		server   *httptest.Server
+		requests int
+		name     types.NamespacedName
+	)
+
+	newEntry := func(sum string) *redisv1alpha1.RedisEntry {
+		return &redisv1alpha1.RedisEntry{
+			ObjectMeta: metav1.ObjectMeta{Name: "geoip", Namespace: "default"},
+			Spec: redisv1alpha1.RedisEntrySpec{
+				Key: "geoip:ranges",
+				ValueFrom: &redisv1alpha1.ValueSource{HTTP: &redisv1alpha1.HTTPValueSource{
+					URL:    server.URL + "/ranges.txt",
+					SHA256: sum,
+					AuthHeaderSecretRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "artifact-token"},
+						Key:                  "header",
+					},
+				}},
+			},
+		}
+	}
+
+	ginkgo.BeforeEach(func() {
+		ctx = context.Background()
+		requests = 0
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			requests++
+			if req.Header.Get("Authorization") != "Bearer t0ken" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(content))
+		}))
+
+		s := runtime.NewScheme()
+		gomega.Expect(redisv1alpha1.AddToScheme(s)).To(gomega.Succeed())
+		gomega.Expect(corev1.AddToScheme(s)).To(gomega.Succeed())
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "artifact-token", Namespace: "default"},
+			Data:       map[string][]byte{"header": []byte("Bearer t0ken")},
+		}
+
+		mockRedis, m := redismock.NewClientMock()
+		mock = m
+		r = &RedisEntryReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(s).
+				WithObjects(secret).
+				WithStatusSubresource(&redisv1alpha1.RedisEntry{}).
+				Build(),
+			Scheme:      s,
+			RedisClient: mockRedis,
+			HTTPClient:  server.Client(),
+		}
+		name = types.NamespacedName{Name: "geoip", Namespace: "default"}
+	})
+
+	ginkgo.AfterEach(func() {
+		server.Close()
+		gomega.Expect(mock.ExpectationsWereMet()).To(gomega.Succeed())
+	})
+
+	ginkgo.It("should write fetched content that matches the pin", func() {
+		sum := sha256.Sum256([]byte(content))
+		gomega.Expect(r.Create(ctx, newEntry(hex.EncodeToString(sum[:])))).To(gomega.Succeed())
+
+		mock.ExpectSet("geoip:ranges", content, 0).SetVal("OK")
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		// The pinned content is not downloaded again
+		mock.ExpectMGet("geoip:ranges").SetVal([]interface{}{content})
+		mock.ExpectSet("geoip:ranges", content, 0).SetVal("OK")
+		_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(requests).To(gomega.Equal(1))
+	})
+
+	ginkgo.It("should refuse content that does not match the pin", func() {
+		sum := sha256.Sum256([]byte("something else"))
+		gomega.Expect(r.Create(ctx, newEntry(hex.EncodeToString(sum[:])))).To(gomega.Succeed())
+
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		updated := &redisv1alpha1.RedisEntry{}
+		gomega.Expect(r.Get(ctx, name, updated)).To(gomega.Succeed())
+		gomega.Expect(updated.Status.Conditions).To(gomega.HaveLen(1))
+		gomega.Expect(updated.Status.Conditions[0].Reason).To(gomega.Equal("ValueSourceError"))
+		gomega.Expect(updated.Status.Conditions[0].Message).To(gomega.ContainSubstring("checksum mismatch"))
+	})
+})
+
+var _ = ginkgo.Describe("Secret Value Source", func() {
+	var (
+		ctx  context.Context
+		mock redismock.ClientMock
+		r    *RedisEntryReconciler
+		name types.NamespacedName
+	)
+
+	ginkgo.BeforeEach(func() {
+		ctx = context.Background()
+		s := runtime.NewScheme()
+		gomega.Expect(redisv1alpha1.AddToScheme(s)).To(gomega.Succeed())
+		gomega.Expect(corev1.AddToScheme(s)).To(gomega.Succeed())
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "api-keys", Namespace: "default"},
+			Data:       map[string][]byte{"stripe": []byte("sk_live_123")},
+		}
+		entry := &redisv1alpha1.RedisEntry{
+			ObjectMeta: metav1.ObjectMeta{Name: "stripe-key", Namespace: "default"},
+			Spec: redisv1alpha1.RedisEntrySpec{
+				Key: "secrets:stripe",
+				ValueFrom: &redisv1alpha1.ValueSource{SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "api-keys"},
+					Key:                  "stripe",
+				}},
+			},
+		}
+		plain := &redisv1alpha1.RedisEntry{
+			ObjectMeta: metav1.ObjectMeta{Name: "plain", Namespace: "default"},
+			Spec:       redisv1alpha1.RedisEntrySpec{Key: "plain", Value: "v"},
+		}
+
+		mockRedis, m := redismock.NewClientMock()
+		mock = m
+		r = &RedisEntryReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(s).
+				WithObjects(secret, entry, plain).
+				WithIndex(&redisv1alpha1.RedisEntry{}, valueSecretField, entryIndexer(valueSecret)).
+				WithStatusSubresource(&redisv1alpha1.RedisEntry{}).
+				Build(),
+			Scheme:      s,
+			RedisClient: mockRedis,
+			LogValues:   ValueLogAlways,
+		}
+		name = types.NamespacedName{Name: "stripe-key", Namespace: "default"}
+	})
+
+	ginkgo.AfterEach(func() {
+		gomega.Expect(mock.ExpectationsWereMet()).To(gomega.Succeed())
+	})
+
+	ginkgo.It("should write the value of the Secret key", func() {
+		mock.ExpectSet("secrets:stripe", "sk_live_123", 0).SetVal("OK")
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		entry := &redisv1alpha1.RedisEntry{}
+		gomega.Expect(r.Get(ctx, name, entry)).To(gomega.Succeed())
+		gomega.Expect(r.valueLogMode(entry)).To(gomega.Equal(ValueLogHashed))
+	})
+
+	ginkgo.It("should fail when the Secret lacks the key", func() {
+		entry := &redisv1alpha1.RedisEntry{}
+		gomega.Expect(r.Get(ctx, name, entry)).To(gomega.Succeed())
+		entry.Spec.ValueFrom.SecretKeyRef.Key = "paypal"
+		gomega.Expect(r.Update(ctx, entry)).To(gomega.Succeed())
+
+		result, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(result.RequeueAfter).To(gomega.Equal(redisErrorRetryDelay))
+		gomega.Expect(r.Get(ctx, name, entry)).To(gomega.Succeed())
+		gomega.Expect(entry.Status.Conditions[0].Reason).To(gomega.Equal(reasonValueSourceError))
+		gomega.Expect(entry.Status.Conditions[0].Message).To(gomega.ContainSubstring(`has no key "paypal"`))
+	})
+
+	ginkgo.It("should map Secret changes to the entries reading it", func() {
+		secret := &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Name: "api-keys", Namespace: "default"}}
+		gomega.Expect(r.entriesForValueSecret(ctx, secret)).To(gomega.ConsistOf(reconcile.Request{NamespacedName: name}))
+
+		secret.Name = "unrelated"
+		gomega.Expect(r.entriesForValueSecret(ctx, secret)).To(gomega.BeEmpty())
+	})
+})
+
+var _ = ginkgo.Describe("ConfigMap Value Source", func() {
+	var (
+		ctx  context.Context
+		mock redismock.ClientMock
+		r    *RedisEntryReconciler
+		name types.NamespacedName
+	)
+
+	ginkgo.BeforeEach(func() {
+		ctx = context.Background()
+		s := runtime.NewScheme()
+		gomega.Expect(redisv1alpha1.AddToScheme(s)).To(gomega.Succeed())
+		gomega.Expect(corev1.AddToScheme(s)).To(gomega.Succeed())
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "routing", Namespace: "default"},
+			Data:       map[string]string{"rules.json": `{"eu":"fra"}`},
+		}
+		entry := &redisv1alpha1.RedisEntry{
+			ObjectMeta: metav1.ObjectMeta{Name: "routing-rules", Namespace: "default"},
+			Spec: redisv1alpha1.RedisEntrySpec{
+				Key: "routing:rules",
+				ValueFrom: &redisv1alpha1.ValueSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "routing"},
+					Key:                  "rules.json",
+				}},
+			},
+		}
+
+		mockRedis, m := redismock.NewClientMock()
+		mock = m
+		r = &RedisEntryReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(s).
+				WithObjects(configMap, entry).
+				WithIndex(&redisv1alpha1.RedisEntry{}, valueConfigMapField, entryIndexer(valueConfigMap)).
+				WithStatusSubresource(&redisv1alpha1.RedisEntry{}).
+				Build(),
+			Scheme:      s,
+			RedisClient: mockRedis,
+		}
+		name = types.NamespacedName{Name: "routing-rules", Namespace: "default"}
+	})
+
+	ginkgo.AfterEach(func() {
+		gomega.Expect(mock.ExpectationsWereMet()).To(gomega.Succeed())
+	})
+
+	ginkgo.It("should write the value of the ConfigMap key", func() {
+		mock.ExpectSet("routing:rules", `{"eu":"fra"}`, 0).SetVal("OK")
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+	})
+
+	ginkgo.It("should map ConfigMap changes to the entries reading it", func() {
+		configMap := &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Name: "routing", Namespace: "default"}}
+		gomega.Expect(r.entriesForValueConfigMap(ctx, configMap)).To(gomega.ConsistOf(reconcile.Request{NamespacedName: name}))
+
+		configMap.Namespace = "other"
+		gomega.Expect(r.entriesForValueConfigMap(ctx, configMap)).To(gomega.BeEmpty())
+	})
+})
+
+var _ = ginkgo.Describe("Structured Values", func() {
+	ginkgo.It("should serialize objects with sorted keys", func() {
+		a, err := serializeStructuredValue(&runtime.RawExtension{Raw: []byte(`{"b": {"y": 1, "x": [2, 1]}, "a": "<eu>"}`)})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(a).To(gomega.Equal(`{"a":"<eu>","b":{"x":[2,1],"y":1}}`))
+
+		b, err := serializeStructuredValue(&runtime.RawExtension{Raw: []byte(`{"a":"<eu>","b":{"y":1,"x":[2,1]}}`)})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(b).To(gomega.Equal(a))
+	})
+
+	ginkgo.It("should keep numbers as written", func() {
+		value, err := serializeStructuredValue(&runtime.RawExtension{Raw: []byte(`{"id": 9007199254740993, "ratio": 0.10}`)})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(value).To(gomega.Equal(`{"id":9007199254740993,"ratio":0.10}`))
+	})
+
+	ginkgo.It("should write the serialized object", func() {
+		s := runtime.NewScheme()
+		gomega.Expect(redisv1alpha1.AddToScheme(s)).To(gomega.Succeed())
+		entry := &redisv1alpha1.RedisEntry{
+			ObjectMeta: metav1.ObjectMeta{Name: "feature-flags", Namespace: "default"},
+			Spec: redisv1alpha1.RedisEntrySpec{
+				Key:             "flags",
+				StructuredValue: &runtime.RawExtension{Raw: []byte(`{"search":true,"checkout":false}`)},
+			},
+		}
+		mockRedis, mock := redismock.NewClientMock()
+		r := &RedisEntryReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(s).
+				WithObjects(entry).
+				WithStatusSubresource(&redisv1alpha1.RedisEntry{}).
+				Build(),
+			Scheme:      s,
+			RedisClient: mockRedis,
+		}
+
+		mock.ExpectSet("flags", `{"checkout":false,"search":true}`, 0).SetVal("OK")
+		_, err := r.Reconcile(context.Background(), reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: "feature-flags", Namespace: "default"},
+		})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(mock.ExpectationsWereMet()).To(gomega.Succeed())
+	})
+})
@@ -0,0 +1,161 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// WorkloadKinds are the workload kinds whose annotation declares RedisEntries.
+var WorkloadKinds = []schema.GroupVersionKind{
+	appsv1.SchemeGroupVersion.WithKind("Deployment"),
+	appsv1.SchemeGroupVersion.WithKind("StatefulSet"),
+}
+
+// WorkloadEntryReconciler creates a RedisEntry for every key declared in the
+// entries annotation of a workload. The entries are owned by the workload,
+// so they are garbage collected with it. Only the workload's metadata is
+// watched and cached.
+type WorkloadEntryReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// Kind is the workload kind reconciled, one of WorkloadKinds
+	Kind schema.GroupVersionKind
+}
+
+// +kubebuilder:rbac:groups=apps,resources=deployments;statefulsets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps,resources=deployments/finalizers;statefulsets/finalizers,verbs=update
+
+// Reconcile creates, updates and deletes the workload's entries to match its
+// annotation.
+func (r *WorkloadEntryReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	workload := &metav1.PartialObjectMetadata{}
+	workload.SetGroupVersionKind(r.Kind)
+	if err := r.Get(ctx, req.NamespacedName, workload); err != nil {
+		if apierrors.IsNotFound(err) {
+			// The entries are garbage collected with the workload
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Failed to get workload")
+		return ctrl.Result{}, err
+	}
+	if !workload.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	specs, err := workloadEntrySpecs(workload.Annotations)
+	if err != nil {
+		// Existing entries are kept until the annotation is fixed, which
+		// triggers another reconcile
+		log.Error(err, "Invalid entries annotation on workload", "annotation", redisv1alpha1.WorkloadEntriesAnnotation)
+		return ctrl.Result{}, nil
+	}
+
+	owned, err := listOwnedEntries(ctx, r.Client, r.Scheme, workload, redisv1alpha1.WorkloadEntryLabel)
+	if err != nil {
+		log.Error(err, "Failed to list RedisEntries of workload")
+		return ctrl.Result{}, err
+	}
+	// Deployments and StatefulSets may share a name
+	prefix := workload.Name + "-" + strings.ToLower(r.Kind.Kind)
+	for _, spec := range specs {
+		_, err := owned.apply(ctx, owned.entry(prefix, spec))
+		if errors.Is(err, errNameTaken) || apierrors.IsInvalid(err) {
+			log.Error(err, "Skipping key declared by workload", "key", spec.Key)
+			continue
+		}
+		if err != nil {
+			log.Error(err, "Failed to apply RedisEntry of workload", "key", spec.Key)
+			return ctrl.Result{}, err
+		}
+	}
+
+	deleted, err := owned.prune(ctx)
+	for _, entry := range deleted {
+		log.Info("Deleted RedisEntry of key removed from workload", "name", entry.Name, "key", entry.Spec.Key)
+	}
+	if err != nil {
+		log.Error(err, "Failed to delete RedisEntry of key removed from workload")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// workloadEntrySpecs parses the entries and TTL annotations into entry
+// specs, sorted by key. A workload without the entries annotation declares
+// no entries.
+func workloadEntrySpecs(annotations map[string]string) ([]redisv1alpha1.RedisEntrySpec, error) {
+	raw, ok := annotations[redisv1alpha1.WorkloadEntriesAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	var values map[string]string
+	if err := json.Unmarshal([]byte(raw), &values); err != nil {
+		return nil, fmt.Errorf("entries must be a JSON object of string keys and values: %w", err)
+	}
+
+	var ttl *int64
+	if raw, ok := annotations[redisv1alpha1.WorkloadTTLAnnotation]; ok {
+		seconds, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || seconds < 0 {
+			return nil, fmt.Errorf("ttl must be a non-negative number of seconds, got %q", raw)
+		}
+		ttl = &seconds
+	}
+
+	specs := make([]redisv1alpha1.RedisEntrySpec, 0, len(values))
+	for key, value := range values {
+		specs = append(specs, redisv1alpha1.RedisEntrySpec{Key: key, Value: value, TTL: ttl})
+	}
+	sort.Slice(specs, func(i, j int) bool { return specs[i].Key < specs[j].Key })
+	return specs, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *WorkloadEntryReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	workload, err := r.Scheme.New(r.Kind)
+	if err != nil {
+		return err
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(workload.(client.Object), builder.OnlyMetadata, builder.WithPredicates(
+			predicate.Or(predicate.AnnotationChangedPredicate{}, predicate.LabelChangedPredicate{}))).
+		Owns(&redisv1alpha1.RedisEntry{}).
+		Named(strings.ToLower(r.Kind.Kind) + "-entries").
+		Complete(r)
+}
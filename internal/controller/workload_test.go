@@ -0,0 +1,134 @@
+package controller
+
+import (
+	"context"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = ginkgo.Describe("Workload Entries", func() {
+	var (
+		ctx        context.Context
+		reconciler *WorkloadEntryReconciler
+		deployment *appsv1.Deployment
+		name       types.NamespacedName
+	)
+
+	ginkgo.BeforeEach(func() {
+		ctx = context.Background()
+		s := runtime.NewScheme()
+		gomega.Expect(clientgoscheme.AddToScheme(s)).To(gomega.Succeed())
+		gomega.Expect(redisv1alpha1.AddToScheme(s)).To(gomega.Succeed())
+
+		deployment = &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "api",
+				Namespace: "default",
+				UID:       "api-uid",
+				Labels:    map[string]string{"app": "api"},
+				Annotations: map[string]string{
+					redisv1alpha1.WorkloadEntriesAnnotation: `{"api:config": "v1", "api:flags": "on"}`,
+					redisv1alpha1.WorkloadTTLAnnotation:     "300",
+				},
+			},
+		}
+		name = types.NamespacedName{Name: "api", Namespace: "default"}
+		reconciler = &WorkloadEntryReconciler{
+			Client: fake.NewClientBuilder().WithScheme(s).WithObjects(deployment).Build(),
+			Scheme: s,
+			Kind:   appsv1.SchemeGroupVersion.WithKind("Deployment"),
+		}
+	})
+
+	reconcileWorkload := func() {
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+	}
+
+	listEntries := func() []redisv1alpha1.RedisEntry {
+		entries := &redisv1alpha1.RedisEntryList{}
+		gomega.Expect(reconciler.List(ctx, entries, client.InNamespace("default"))).To(gomega.Succeed())
+		return entries.Items
+	}
+
+	ginkgo.It("should create entries owned by the workload", func() {
+		reconcileWorkload()
+
+		entry := &redisv1alpha1.RedisEntry{}
+		gomega.Expect(reconciler.Get(ctx, types.NamespacedName{
+			Name: ownedEntryName("api-deployment", "api:config"), Namespace: "default",
+		}, entry)).To(gomega.Succeed())
+		gomega.Expect(entry.Spec).To(gomega.Equal(redisv1alpha1.RedisEntrySpec{
+			Key: "api:config", Value: "v1", TTL: ptr.To(int64(300)),
+		}))
+		gomega.Expect(entry.Labels).To(gomega.HaveKeyWithValue("app", "api"))
+		gomega.Expect(entry.Labels).To(gomega.HaveKeyWithValue(redisv1alpha1.WorkloadEntryLabel, "api-uid"))
+
+		owner := metav1.GetControllerOf(entry)
+		gomega.Expect(owner).NotTo(gomega.BeNil())
+		gomega.Expect(owner.Kind).To(gomega.Equal("Deployment"))
+		gomega.Expect(owner.Name).To(gomega.Equal("api"))
+		gomega.Expect(listEntries()).To(gomega.HaveLen(2))
+	})
+
+	ginkgo.It("should follow changes to the annotation", func() {
+		reconcileWorkload()
+
+		gomega.Expect(reconciler.Get(ctx, name, deployment)).To(gomega.Succeed())
+		deployment.Annotations[redisv1alpha1.WorkloadEntriesAnnotation] = `{"api:config": "v2"}`
+		gomega.Expect(reconciler.Update(ctx, deployment)).To(gomega.Succeed())
+		reconcileWorkload()
+
+		entries := listEntries()
+		gomega.Expect(entries).To(gomega.HaveLen(1))
+		gomega.Expect(entries[0].Spec.Value).To(gomega.Equal("v2"))
+
+		// Removing the annotation removes the entries
+		gomega.Expect(reconciler.Get(ctx, name, deployment)).To(gomega.Succeed())
+		delete(deployment.Annotations, redisv1alpha1.WorkloadEntriesAnnotation)
+		gomega.Expect(reconciler.Update(ctx, deployment)).To(gomega.Succeed())
+		reconcileWorkload()
+		gomega.Expect(listEntries()).To(gomega.BeEmpty())
+	})
+
+	ginkgo.It("should keep entries while the annotation is invalid", func() {
+		reconcileWorkload()
+
+		gomega.Expect(reconciler.Get(ctx, name, deployment)).To(gomega.Succeed())
+		deployment.Annotations[redisv1alpha1.WorkloadEntriesAnnotation] = `["api:config"]`
+		gomega.Expect(reconciler.Update(ctx, deployment)).To(gomega.Succeed())
+		reconcileWorkload()
+		gomega.Expect(listEntries()).To(gomega.HaveLen(2))
+	})
+
+	ginkgo.It("should parse the annotations", func() {
+		specs, err := workloadEntrySpecs(map[string]string{
+			redisv1alpha1.WorkloadEntriesAnnotation: `{"b": "2", "a": "1"}`,
+		})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(specs).To(gomega.Equal([]redisv1alpha1.RedisEntrySpec{
+			{Key: "a", Value: "1"}, {Key: "b", Value: "2"},
+		}))
+
+		_, err = workloadEntrySpecs(map[string]string{
+			redisv1alpha1.WorkloadEntriesAnnotation: `{"a": "1"}`,
+			redisv1alpha1.WorkloadTTLAnnotation:     "-5",
+		})
+		gomega.Expect(err).To(gomega.MatchError(gomega.ContainSubstring("ttl must be")))
+
+		specs, err = workloadEntrySpecs(nil)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(specs).To(gomega.BeEmpty())
+	})
+})
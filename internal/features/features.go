@@ -0,0 +1,79 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package features defines the feature gates of the Redis controller.
+// Experimental subsystems are registered here so they can ship disabled by
+// default and be enabled per cluster with --feature-gates.
+package features
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/component-base/featuregate"
+)
+
+const (
+	// DriftDetection reads back already synced keys on every resync and
+	// reports values changed outside the controller.
+	DriftDetection featuregate.Feature = "DriftDetection"
+
+	// WorkloadEntries creates RedisEntries for the keys declared in an
+	// annotation on Deployments and StatefulSets.
+	WorkloadEntries featuregate.Feature = "WorkloadEntries"
+)
+
+// defaultFeatureGates lists every known feature with its default and maturity.
+var defaultFeatureGates = map[featuregate.Feature]featuregate.FeatureSpec{
+	DriftDetection:  {Default: true, PreRelease: featuregate.Beta},
+	WorkloadEntries: {Default: false, PreRelease: featuregate.Alpha},
+}
+
+// restartRequired lists the features only read on startup, so changing them
+// at runtime would have no effect.
+var restartRequired = []featuregate.Feature{WorkloadEntries}
+
+// Gate is the controller-wide feature gate, configured from --feature-gates.
+var Gate featuregate.MutableFeatureGate = featuregate.NewFeatureGate()
+
+func init() {
+	runtime.Must(Gate.Add(defaultFeatureGates))
+}
+
+// Enabled reports whether a feature is enabled.
+func Enabled(f featuregate.Feature) bool {
+	return Gate.Enabled(f)
+}
+
+// Snapshot returns whether each known feature is enabled.
+func Snapshot() map[string]bool {
+	gates := make(map[string]bool, len(defaultFeatureGates))
+	for f := range defaultFeatureGates {
+		gates[string(f)] = Gate.Enabled(f)
+	}
+	return gates
+}
+
+// CheckReload returns an error when gates would change a feature that is
+// only read on startup.
+func CheckReload(gates map[string]bool) error {
+	for _, f := range restartRequired {
+		if enabled, ok := gates[string(f)]; ok && enabled != Gate.Enabled(f) {
+			return fmt.Errorf("feature gate %s cannot change without a restart", f)
+		}
+	}
+	return nil
+}
@@ -20,6 +20,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"os/exec"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/AAspCodes/redis-ctrl/test/utils"
@@ -244,8 +246,193 @@ var _ = ginkgo.Describe("Manager", ginkgo.Ordered, func() {
 			))
 		})
 	})
+
+	ginkgo.Context("RedisEntry", func() {
+		const (
+			entryName = "e2e-lifecycle"
+			entryKey  = "e2e:lifecycle"
+		)
+
+		ginkgo.It("should write, update and release a key", func() {
+			ginkgo.DeferCleanup(func() {
+				cmd := exec.Command("kubectl", "delete", "redisentry", entryName, "-n", namespace, "--ignore-not-found=true")
+				_, _ = utils.Run(cmd)
+			})
+
+			ginkgo.By("creating a RedisEntry")
+			gomega.Expect(applyEntry(entryName, entryKey, "v1")).To(gomega.Succeed())
+
+			ginkgo.By("verifying that the key was written to Redis")
+			gomega.Eventually(verifyRedisValue(entryKey, "v1"), "60s", "2s").Should(gomega.Succeed())
+
+			ginkgo.By("validating that the entry reports Available")
+			verifyAvailable := func(g gomega.Gomega) {
+				cmd := exec.Command("kubectl", "get", "redisentry", entryName, "-n", namespace,
+					"-o", `jsonpath={.status.conditions[?(@.type=="Available")].status}`)
+				output, err := utils.Run(cmd)
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+				g.Expect(output).To(gomega.Equal("True"))
+			}
+			gomega.Eventually(verifyAvailable).Should(gomega.Succeed())
+
+			ginkgo.By("updating the value")
+			gomega.Expect(applyEntry(entryName, entryKey, "v2")).To(gomega.Succeed())
+			gomega.Eventually(verifyRedisValue(entryKey, "v2"), "60s", "2s").Should(gomega.Succeed())
+
+			ginkgo.By("deleting the RedisEntry")
+			cmd := exec.Command("kubectl", "delete", "redisentry", entryName, "-n", namespace, "--wait=true")
+			_, err := utils.Run(cmd)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred(), "Failed to delete RedisEntry")
+
+			ginkgo.By("verifying that the key was deleted from Redis")
+			gomega.Eventually(verifyRedisValue(entryKey, ""), "60s", "2s").Should(gomega.Succeed())
+
+			ginkgo.By("verifying that the controller no longer manages the key")
+			gomega.Expect(redisSet(entryKey, "external")).To(gomega.Succeed())
+			gomega.Consistently(verifyRedisValue(entryKey, "external"), "10s", "2s").Should(gomega.Succeed())
+		})
+	})
+
+	ginkgo.Context("Redis outages", func() {
+		const (
+			entryName = "e2e-chaos"
+			entryKey  = "e2e:chaos"
+		)
+
+		ginkgo.BeforeEach(func() {
+			gomega.Expect(applyEntry(entryName, entryKey, "v1")).To(gomega.Succeed())
+			gomega.Eventually(verifyRedisValue(entryKey, "v1"), "60s", "2s").Should(gomega.Succeed())
+			ginkgo.DeferCleanup(func() {
+				cmd := exec.Command("kubectl", "delete", "redisentry", entryName, "-n", namespace, "--ignore-not-found=true")
+				_, _ = utils.Run(cmd)
+			})
+		})
+
+		ginkgo.It("should resync entries after Redis restarts empty", func() {
+			ginkgo.By("replacing the Redis pod")
+			cmd := exec.Command("kubectl", "delete", "pod", "redis", "-n", namespace, "--wait=true")
+			_, err := utils.Run(cmd)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred(), "Failed to delete Redis pod")
+			cmd = exec.Command("kubectl", "run", "redis", "-n", namespace, "--image=redis:7")
+			_, err = utils.Run(cmd)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred(), "Failed to recreate Redis pod")
+			cmd = exec.Command("kubectl", "wait", "pod/redis", "-n", namespace, "--for=condition=Ready", "--timeout=2m")
+			_, err = utils.Run(cmd)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred(), "Redis failed to become ready")
+
+			// The dataset marker is gone along with the data, which triggers
+			// a resync within a health check interval
+			ginkgo.By("verifying that the key is written again")
+			gomega.Eventually(verifyRedisValue(entryKey, "v1"), "60s", "2s").Should(gomega.Succeed())
+		})
+
+		ginkgo.It("should back off while partitioned from Redis and catch up afterwards", func() {
+			ginkgo.By("denying all traffic to Redis")
+			cmd := exec.Command("kubectl", "apply", "-n", namespace, "-f", "-")
+			cmd.Stdin = strings.NewReader(`apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: e2e-redis-partition
+spec:
+  podSelector:
+    matchLabels:
+      run: redis
+  policyTypes:
+  - Ingress
+`)
+			_, err := utils.Run(cmd)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred(), "Failed to create NetworkPolicy")
+			ginkgo.DeferCleanup(func() {
+				cmd := exec.Command("kubectl", "delete", "networkpolicy", "e2e-redis-partition", "-n", namespace,
+					"--ignore-not-found=true")
+				_, _ = utils.Run(cmd)
+			})
+
+			// Network policies only apply to new connections, so drop the
+			// controller's pooled ones
+			cmd = exec.Command("kubectl", "exec", "redis", "-n", namespace, "--",
+				"redis-cli", "CLIENT", "KILL", "TYPE", "normal", "SKIPME", "yes")
+			_, err = utils.Run(cmd)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred(), "Failed to drop Redis client connections")
+
+			ginkgo.By("updating the entry while Redis is unreachable")
+			gomega.Expect(applyEntry(entryName, entryKey, "v2")).To(gomega.Succeed())
+			verifyError := func(g gomega.Gomega) {
+				cmd := exec.Command("kubectl", "get", "redisentry", entryName, "-n", namespace,
+					"-o", "jsonpath={.status.lastError}")
+				output, err := utils.Run(cmd)
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+				g.Expect(output).NotTo(gomega.BeEmpty())
+			}
+			gomega.Eventually(verifyError, "60s", "2s").Should(gomega.Succeed())
+
+			ginkgo.By("validating that failed writes are retried with a delay")
+			before := entrySyncAttempts(entryName)
+			gomega.Consistently(verifyRedisValue(entryKey, "v1"), "30s", "5s").Should(gomega.Succeed())
+			// Writes are retried at most every five seconds
+			gomega.Expect(entrySyncAttempts(entryName) - before).To(gomega.BeNumerically("<=", 8))
+
+			ginkgo.By("lifting the partition")
+			cmd = exec.Command("kubectl", "delete", "networkpolicy", "e2e-redis-partition", "-n", namespace)
+			_, err = utils.Run(cmd)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred(), "Failed to delete NetworkPolicy")
+			gomega.Eventually(verifyRedisValue(entryKey, "v2"), "60s", "2s").Should(gomega.Succeed())
+		})
+	})
 })
 
+// applyEntry creates or updates a RedisEntry in the manager namespace.
+func applyEntry(name, key, value string) error {
+	cmd := exec.Command("kubectl", "apply", "-n", namespace, "-f", "-")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf(`apiVersion: redis.aaspcodes.github.io/v1alpha1
+kind: RedisEntry
+metadata:
+  name: %s
+spec:
+  key: %q
+  value: %q
+`, name, key, value))
+	_, err := utils.Run(cmd)
+	return err
+}
+
+// entrySyncAttempts returns the number of writes a RedisEntry has attempted.
+func entrySyncAttempts(name string) int64 {
+	cmd := exec.Command("kubectl", "get", "redisentry", name, "-n", namespace,
+		"-o", "jsonpath={.status.syncAttempts}")
+	output, err := utils.Run(cmd)
+	gomega.Expect(err).NotTo(gomega.HaveOccurred())
+	if output == "" {
+		return 0
+	}
+	attempts, err := strconv.ParseInt(output, 10, 64)
+	gomega.Expect(err).NotTo(gomega.HaveOccurred())
+	return attempts
+}
+
+// redisGet reads a key through redis-cli in the Redis pod.
+func redisGet(key string) (string, error) {
+	cmd := exec.Command("kubectl", "exec", "redis", "-n", namespace, "--", "redis-cli", "GET", key)
+	output, err := utils.Run(cmd)
+	return strings.TrimSpace(output), err
+}
+
+// verifyRedisValue returns an assertion that a key holds the expected value.
+func verifyRedisValue(key, expected string) func(g gomega.Gomega) {
+	return func(g gomega.Gomega) {
+		value, err := redisGet(key)
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		g.Expect(value).To(gomega.Equal(expected))
+	}
+}
+
+// redisSet writes a key through redis-cli in the Redis pod.
+func redisSet(key, value string) error {
+	cmd := exec.Command("kubectl", "exec", "redis", "-n", namespace, "--", "redis-cli", "SET", key, value)
+	_, err := utils.Run(cmd)
+	return err
+}
+
 // serviceAccountToken returns the token for the service account.
 func serviceAccountToken() (string, error) {
 	var err error
@@ -0,0 +1,197 @@
+//go:build load
+
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package load
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	redisv1alpha1 "github.com/AAspCodes/redis-ctrl/api/v1alpha1"
+	"github.com/AAspCodes/redis-ctrl/internal/controller"
+	"github.com/alicebob/miniredis/v2"
+	redisv9 "github.com/redis/go-redis/v9"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var (
+	entries      = flag.Int("load.entries", 5000, "Number of RedisEntries to create.")
+	workers      = flag.Int("load.workers", 4, "Number of concurrent reconciles.")
+	redisAddress = flag.String("load.redis-address", "",
+		"Redis to write to as host:port. Empty starts an in-process miniredis.")
+)
+
+// apiCalls counts requests to the fake API server by verb.
+type apiCalls struct {
+	get, list, update, statusUpdate atomic.Int64
+}
+
+func (c *apiCalls) total() int64 {
+	return c.get.Load() + c.list.Load() + c.update.Load() + c.statusUpdate.Load()
+}
+
+// interceptors count the API calls made through the client.
+func (c *apiCalls) interceptors() interceptor.Funcs {
+	return interceptor.Funcs{
+		Get: func(ctx context.Context, cl client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+			c.get.Add(1)
+			return cl.Get(ctx, key, obj, opts...)
+		},
+		List: func(ctx context.Context, cl client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+			c.list.Add(1)
+			return cl.List(ctx, list, opts...)
+		},
+		Update: func(ctx context.Context, cl client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+			c.update.Add(1)
+			return cl.Update(ctx, obj, opts...)
+		},
+		SubResourceUpdate: func(ctx context.Context, cl client.Client, subResource string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+			c.statusUpdate.Add(1)
+			return cl.SubResource(subResource).Update(ctx, obj, opts...)
+		},
+	}
+}
+
+// redisTarget returns a client for the configured Redis, or for a fresh
+// miniredis, and a function returning the number of commands it served.
+func redisTarget(t testing.TB) (*redisv9.Client, func() int) {
+	if *redisAddress != "" {
+		redisClient := redisv9.NewClient(&redisv9.Options{Addr: *redisAddress})
+		t.Cleanup(func() { _ = redisClient.Close() })
+		before := commandsProcessed(t, redisClient)
+		return redisClient, func() int { return commandsProcessed(t, redisClient) - before }
+	}
+	server := miniredis.RunT(t)
+	redisClient := redisv9.NewClient(&redisv9.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = redisClient.Close() })
+	return redisClient, server.CommandCount
+}
+
+// commandsProcessed reads total_commands_processed from INFO stats.
+func commandsProcessed(t testing.TB, redisClient *redisv9.Client) int {
+	info, err := redisClient.InfoMap(context.Background(), "stats").Result()
+	if err != nil {
+		t.Fatalf("failed to read Redis stats: %v", err)
+	}
+	var n int
+	_, _ = fmt.Sscan(info["Stats"]["total_commands_processed"], &n)
+	return n
+}
+
+// newReconciler creates n entries in a fake API server and a reconciler
+// writing them to redisClient.
+func newReconciler(t testing.TB, n int, redisClient *redisv9.Client, calls *apiCalls) (*controller.RedisEntryReconciler, []types.NamespacedName) {
+	s := runtime.NewScheme()
+	if err := redisv1alpha1.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	objs := make([]client.Object, 0, n)
+	names := make([]types.NamespacedName, 0, n)
+	for i := range n {
+		name := fmt.Sprintf("load-%d", i)
+		objs = append(objs, &redisv1alpha1.RedisEntry{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Generation: 1},
+			Spec: redisv1alpha1.RedisEntrySpec{
+				Key:   "load:" + name,
+				Value: fmt.Sprintf("value-%d", i),
+			},
+		})
+		names = append(names, types.NamespacedName{Namespace: "default", Name: name})
+	}
+	c := fake.NewClientBuilder().
+		WithScheme(s).
+		WithObjects(objs...).
+		WithStatusSubresource(&redisv1alpha1.RedisEntry{}).
+		WithInterceptorFuncs(calls.interceptors()).
+		Build()
+	return &controller.RedisEntryReconciler{Client: c, Scheme: s, RedisClient: redisClient}, names
+}
+
+// TestLoad reconciles -load.entries entries with -load.workers concurrent
+// workers and reports throughput, API calls and Redis commands.
+func TestLoad(t *testing.T) {
+	ctx := context.Background()
+	redisClient, redisCommands := redisTarget(t)
+	calls := &apiCalls{}
+	r, names := newReconciler(t, *entries, redisClient, calls)
+
+	queue := make(chan types.NamespacedName)
+	var failed atomic.Int64
+	var wg sync.WaitGroup
+	start := time.Now()
+	commandsBefore := redisCommands()
+	for range *workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range queue {
+				if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: name}); err != nil {
+					failed.Add(1)
+				}
+			}
+		}()
+	}
+	for _, name := range names {
+		queue <- name
+	}
+	close(queue)
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	if n := failed.Load(); n > 0 {
+		t.Errorf("%d of %d reconciles failed", n, len(names))
+	}
+	keys, err := redisClient.Exists(ctx, "load:load-0", fmt.Sprintf("load:load-%d", len(names)-1)).Result()
+	if err != nil || keys != 2 {
+		t.Errorf("expected the first and last keys in Redis, found %d (%v)", keys, err)
+	}
+
+	seconds := elapsed.Seconds()
+	t.Logf("reconciled %d entries with %d workers in %s", len(names), *workers, elapsed.Round(time.Millisecond))
+	t.Logf("throughput: %.0f entries/s", float64(len(names))/seconds)
+	t.Logf("API calls: %d (%.0f/s): get=%d list=%d update=%d status=%d", calls.total(), float64(calls.total())/seconds,
+		calls.get.Load(), calls.list.Load(), calls.update.Load(), calls.statusUpdate.Load())
+	commands := redisCommands() - commandsBefore
+	t.Logf("Redis commands: %d (%.0f/s, %.1f per entry)", commands, float64(commands)/seconds,
+		float64(commands)/float64(len(names)))
+}
+
+// BenchmarkReconcile measures a single first-time reconcile of an entry.
+func BenchmarkReconcile(b *testing.B) {
+	ctx := context.Background()
+	redisClient, _ := redisTarget(b)
+	r, names := newReconciler(b, b.N, redisClient, &apiCalls{})
+
+	b.ResetTimer()
+	for i := range b.N {
+		if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: names[i]}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}